@@ -5,35 +5,72 @@ import (
 	"clapper/config"
 	"clapper/database"
 	"clapper/tmdb"
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 type Bot struct {
-	session *discordgo.Session
-	db      *database.Database
-	tmdb    *tmdb.Client
-	config  *config.Config
+	session  *discordgo.Session
+	db       *database.Database
+	tmdb     *tmdb.Client
+	config   *config.Config
+	handlers *commands.Handlers
+	// stop encerra a atualização periódica dos gêneros
+	stop chan struct{}
 }
 
+// genreRefreshInterval é o intervalo entre as atualizações das listas de gêneros do TMDB
+const genreRefreshInterval = 24 * time.Hour
+
 func New(cfg *config.Config) (*Bot, error) {
+	if cfg.TMDBAPIKey == "" && cfg.TMDBAccessToken == "" {
+		return nil, fmt.Errorf("TMDB_API_KEY or TMDB_ACCESS_TOKEN must be set")
+	}
+
 	session, err := discordgo.New("Bot " + cfg.DiscordToken)
 	if err != nil {
 		return nil, fmt.Errorf("error creating Discord session: %w", err)
 	}
 
-	db, err := database.New("clapper.db")
+	db, err := database.New("clapper.db", cfg.LegacyGuildID)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing database: %w", err)
 	}
 
+	tmdbClient := tmdb.NewClient(cfg.TMDBAPIKey)
+	if cfg.TMDBAccessToken != "" {
+		tmdbClient.SetAccessToken(cfg.TMDBAccessToken)
+	}
+	if cfg.TMDBBaseURL != "" {
+		tmdbClient.SetBaseURL(cfg.TMDBBaseURL)
+	}
+	if cfg.TMDBImageBaseURL != "" {
+		tmdbClient.SetImageBaseURL(cfg.TMDBImageBaseURL)
+	}
+	if cfg.TMDBLanguage != "" {
+		tmdbClient.SetLanguage(cfg.TMDBLanguage)
+	}
+	tmdbClient.EnableCache(db, cfg.TMDBCacheTTL)
+	if cfg.TMDBTimeout > 0 {
+		tmdbClient.SetTimeout(cfg.TMDBTimeout)
+	}
+	if cfg.TMDBMaxRetries >= 0 {
+		tmdbClient.SetRetries(cfg.TMDBMaxRetries, tmdb.DefaultRetryBaseDelay)
+	}
+	if cfg.TMDBRateLimit > 0 {
+		tmdbClient.SetRateLimit(cfg.TMDBRateLimit, tmdb.DefaultRateBurst)
+	}
+
 	return &Bot{
 		session: session,
 		db:      db,
-		tmdb:    tmdb.NewClient(cfg.TMDBAPIKey),
+		tmdb:    tmdbClient,
 		config:  cfg,
+		stop:    make(chan struct{}),
 	}, nil
 }
 
@@ -48,8 +85,11 @@ func (b *Bot) Start() error {
 	}
 
 	commandHandlers := commands.NewHandlers(b.db, b.tmdb)
+	b.handlers = commandHandlers
 
-	b.session.AddHandler(commandHandlers.HandleInteraction)
+	b.session.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		commandHandlers.HandleInteraction(s, i)
+	})
 
 	for _, cmd := range commands.Commands {
 		_, err := b.session.ApplicationCommandCreate(b.session.State.User.ID, "", cmd)
@@ -59,10 +99,61 @@ func (b *Bot) Start() error {
 		log.Printf("Registered command: %s", cmd.Name)
 	}
 
+	// Votações abertas sobrevivem a reinícios: os prazos são reagendados a partir do banco
+	if err := commandHandlers.ResumePolls(b.session); err != nil {
+		log.Printf("Erro ao retomar votações abertas: %v", err)
+	}
+
+	// O mesmo vale para as revelações agendadas das avaliações às cegas
+	if err := commandHandlers.ResumeReveals(b.session); err != nil {
+		log.Printf("Erro ao retomar revelações de avaliações às cegas: %v", err)
+	}
+
+	// Mensagens do canal de sugestões que mudaram de estado com o bot fora do ar são corrigidas aos poucos
+	commandHandlers.ReconcileSuggestionPosts(b.session)
+
+	// Os nomes dos gêneros vêm do TMDB em cada idioma usado pelos servidores e são atualizados uma vez por dia
+	b.loadGenres()
+	go b.refreshGenres()
+
 	return nil
 }
 
+// loadGenres carrega a lista de gêneros do idioma padrão e de cada idioma configurado com /setup language
+func (b *Bot) loadGenres() {
+	languages, err := b.db.GetGuildLanguages()
+	if err != nil {
+		log.Printf("Erro ao listar os idiomas dos servidores: %v", err)
+	}
+
+	for _, language := range append([]string{""}, languages...) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := b.tmdb.LoadGenres(ctx, language); err != nil {
+			log.Printf("Erro ao carregar os gêneros do TMDB (idioma %q): %v", language, err)
+		}
+		cancel()
+	}
+}
+
+func (b *Bot) refreshGenres() {
+	ticker := time.NewTicker(genreRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.loadGenres()
+		}
+	}
+}
+
 func (b *Bot) Stop() {
+	close(b.stop)
+	if b.handlers != nil {
+		b.handlers.Shutdown()
+	}
 	b.session.Close()
 	b.db.Close()
 }
\ No newline at end of file
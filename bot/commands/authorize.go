@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+type permission int
+
+const (
+	// permissionConfigure permite alterar a configuração do bot; somente administradores
+	permissionConfigure permission = iota
+	// permissionHost permite sortear, rerrolar, confirmar e moderar sugestões
+	permissionHost
+	// permissionSuggest permite sugerir filmes
+	permissionSuggest
+)
+
+func isAdministrator(s Responder, i *discordgo.InteractionCreate) bool {
+	if i.Member == nil || i.Member.User == nil {
+		return false
+	}
+	perms, err := s.UserChannelPermissions(i.Member.User.ID, i.ChannelID)
+	return err == nil && perms&discordgo.PermissionAdministrator != 0
+}
+
+func memberHasRole(member *discordgo.Member, roleID string) bool {
+	if roleID == "" {
+		return false
+	}
+	for _, id := range member.Roles {
+		if id == roleID {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize é o único ponto de verificação de permissões dos comandos.
+// Administradores sempre passam; os cargos configurados em /setup roles liberam o resto.
+func (h *Handlers) authorize(s Responder, i *discordgo.InteractionCreate, perm permission) bool {
+	if i.GuildID == "" || i.Member == nil {
+		return false
+	}
+
+	if isAdministrator(s, i) {
+		return true
+	}
+
+	if perm == permissionConfigure {
+		return false
+	}
+
+	config, err := h.db.GetGuildConfig(i.GuildID)
+	if err != nil {
+		return false
+	}
+
+	switch perm {
+	case permissionHost:
+		return config != nil && memberHasRole(i.Member, config.HostRoleID)
+	case permissionSuggest:
+		if config == nil || config.SuggesterRoleID == "" {
+			return true
+		}
+		return memberHasRole(i.Member, config.SuggesterRoleID) || memberHasRole(i.Member, config.HostRoleID)
+	}
+
+	return false
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord aceita no máximo 25 opções por resposta de autocomplete
+const maxAutocompleteChoices = 25
+
+func (h *Handlers) HandleAutocomplete(s Responder, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		return
+	}
+
+	data := i.ApplicationCommandData()
+
+	var prefix string
+	for _, opt := range data.Options {
+		if opt.Focused {
+			prefix = opt.StringValue()
+			break
+		}
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+
+	switch data.Name {
+	case "removesuggestion":
+		choices = h.removableSuggestionChoices(s, i, prefix)
+	case "ratemovie", "moviereviews", "schedule", "unschedule", "unselect", "blindratings", "revealratings":
+		choices = h.selectedMovieChoices(i.GuildID, prefix)
+	case "pickmovie":
+		choices = h.genreChoices(i.GuildID, prefix)
+	}
+
+	if choices == nil {
+		choices = []*discordgo.ApplicationCommandOptionChoice{}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
+
+func (h *Handlers) removableSuggestionChoices(s Responder, i *discordgo.InteractionCreate, prefix string) []*discordgo.ApplicationCommandOptionChoice {
+	if i.Member == nil {
+		return nil
+	}
+
+	if h.authorize(s, i, permissionHost) {
+		suggestions, err := h.db.SearchSuggestionsByPrefix(i.GuildID, prefix, maxAutocompleteChoices)
+		if err != nil {
+			return nil
+		}
+
+		var choices []*discordgo.ApplicationCommandOptionChoice
+		for _, suggestion := range suggestions {
+			name := fmt.Sprintf("%s (%s) — %s", suggestion.MovieName, suggestion.ReleaseYear, suggestion.Username)
+			choices = append(choices, autocompleteChoice(name, suggestion.ID))
+		}
+		return choices
+	}
+
+	suggestions, err := h.db.SearchUserSuggestionsByPrefix(i.GuildID, i.Member.User.ID, prefix, maxAutocompleteChoices)
+	if err != nil {
+		return nil
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, suggestion := range suggestions {
+		name := fmt.Sprintf("%s (%s)", suggestion.MovieName, suggestion.ReleaseYear)
+		choices = append(choices, autocompleteChoice(name, suggestion.ID))
+	}
+	return choices
+}
+
+func (h *Handlers) selectedMovieChoices(guildID, prefix string) []*discordgo.ApplicationCommandOptionChoice {
+	movies, err := h.db.SearchSelectedMoviesByPrefix(guildID, prefix, maxAutocompleteChoices)
+	if err != nil {
+		return nil
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, movie := range movies {
+		name := fmt.Sprintf("%s (%s)", movie.MovieName, movie.ReleaseYear)
+		choices = append(choices, autocompleteChoice(name, movie.ID))
+	}
+	return choices
+}
+
+func autocompleteChoice(name string, suggestionID int) *discordgo.ApplicationCommandOptionChoice {
+	runes := []rune(name)
+	if len(runes) > 100 {
+		name = string(runes[:97]) + "..."
+	}
+
+	return &discordgo.ApplicationCommandOptionChoice{
+		Name:  name,
+		Value: strconv.Itoa(suggestionID),
+	}
+}
+
+// parseSuggestionID lê o ID enviado por uma opção com autocomplete; texto livre não é aceito
+func parseSuggestionID(value string) (int, bool) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
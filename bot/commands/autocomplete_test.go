@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"clapper/tmdb"
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestAutocomplete(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "autocomplete for removesuggestion lists only the member's suggestions",
+			setup: func(env *testEnv) {
+				env.suggest(movieAlien, testMember)
+				env.suggest(movieAliens, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return autocompleteRequest(testMember, "removesuggestion", focusedOption("movie_name", "ali"))
+			},
+			check: func(t *testing.T, env *testEnv) {
+				choices := env.responder.responses[0].Data.Choices
+				if len(choices) != 1 || choices[0].Name != "Alien (1979)" || choices[0].Value != env.ref(movieAlien) {
+					t.Errorf("unexpected choices: %+v", choices)
+				}
+			},
+		},
+		{
+			name: "autocomplete for removesuggestion lists only approved suggestions to hosts",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieAlien, testMember)
+				env.pending(movieAliens, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return autocompleteRequest(testHost, "removesuggestion", focusedOption("movie_name", "ali"))
+			},
+			check: func(t *testing.T, env *testEnv) {
+				choices := env.responder.responses[0].Data.Choices
+				if len(choices) != 1 || choices[0].Value != env.ref(movieAlien) {
+					t.Errorf("unexpected choices: %+v", choices)
+				}
+			},
+		},
+		{
+			name: "autocomplete for ratemovie lists selected movies",
+			setup: func(env *testEnv) {
+				env.selectMovie(movieHeat, testOther)
+				env.suggest(movieAlien, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return autocompleteRequest(testMember, "ratemovie", focusedOption("movie_name", ""))
+			},
+			check: func(t *testing.T, env *testEnv) {
+				choices := env.responder.responses[0].Data.Choices
+				if len(choices) != 1 || choices[0].Value != env.ref(movieHeat) {
+					t.Errorf("unexpected choices: %+v", choices)
+				}
+			},
+		},
+		{
+			name: "autocomplete for pickmovie lists genres in the server language",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveLanguage(testGuildID, "pt-BR")
+				env.handlers.tmdb.LoadGenres(context.Background(), "pt-BR")
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return autocompleteRequest(testHost, "pickmovie", focusedOption("genre", "aç"))
+			},
+			check: func(t *testing.T, env *testEnv) {
+				choices := env.responder.responses[0].Data.Choices
+				if len(choices) != 1 || choices[0].Name != "Ação" || choices[0].Value != "28" {
+					t.Errorf("unexpected choices: %+v", choices)
+				}
+			},
+		},
+		{
+			name: "autocomplete for pickmovie lists every genre without a prefix",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return autocompleteRequest(testHost, "pickmovie", focusedOption("genre", ""))
+			},
+			check: func(t *testing.T, env *testEnv) {
+				choices := env.responder.responses[0].Data.Choices
+				if len(choices) != len(tmdb.MovieGenres()) || choices[0].Name != "Action" {
+					t.Errorf("unexpected choices: %+v", choices)
+				}
+			},
+		},
+	})
+}
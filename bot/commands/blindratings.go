@@ -0,0 +1,423 @@
+package commands
+
+import (
+	"clapper/database"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Limite do prazo de revelação automática das avaliações às cegas
+const maxRevealDelay = 30 * 24 * time.Hour
+
+func parseRevealDelay(input string) (time.Duration, error) {
+	delay, err := parseDuration(input)
+	if err != nil {
+		return 0, err
+	}
+
+	if delay < time.Minute || delay > maxRevealDelay {
+		return 0, fmt.Errorf("the reveal must happen between 1 minute and 30 days from now")
+	}
+	return delay, nil
+}
+
+// revealConditions descreve quando as notas escondidas serão reveladas
+func revealConditions(blind *database.BlindRating) string {
+	var conditions []string
+	if blind.RevealAt != nil {
+		conditions = append(conditions, fmt.Sprintf("<t:%d:R>", blind.RevealAt.Unix()))
+	}
+	if blind.RevealAfter > 0 {
+		verb := "have"
+		if blind.RevealAfter == 1 {
+			verb = "has"
+		}
+		conditions = append(conditions, fmt.Sprintf("once %d member%s %s rated", blind.RevealAfter, pluralize(blind.RevealAfter), verb))
+	}
+
+	if len(conditions) == 0 {
+		return "Scores will be revealed when a host runs `/revealratings`."
+	}
+	return fmt.Sprintf("Scores will be revealed automatically %s, or earlier with `/revealratings`.", strings.Join(conditions, " or "))
+}
+
+// blindRatingField substitui a nota da comunidade enquanto as avaliações estão escondidas: mostra só quem já avaliou
+func blindRatingField(blind *database.BlindRating, reviews []database.MovieReview) *discordgo.MessageEmbedField {
+	value := revealConditions(blind)
+	if len(reviews) == 0 {
+		value += "\nNobody has rated yet."
+	} else {
+		raters := make([]string, len(reviews))
+		for idx, review := range reviews {
+			raters[idx] = fmt.Sprintf("<@%s>", review.UserID)
+		}
+		value += fmt.Sprintf("\n**Rated so far (%d):** %s", len(reviews), strings.Join(raters, ", "))
+	}
+
+	return &discordgo.MessageEmbedField{
+		Name:   "🙈 Blind Rating",
+		Value:  truncate(value, 1024),
+		Inline: false,
+	}
+}
+
+// hiddenRatings devolve o estado das avaliações às cegas do filme, ou nil quando as notas estão visíveis
+func (h *Handlers) hiddenRatings(guildID string, suggestionID int) *database.BlindRating {
+	blind, err := h.db.GetBlindRating(guildID, suggestionID)
+	if err != nil || blind == nil || !blind.IsHidden() {
+		return nil
+	}
+	return blind
+}
+
+// selectedMovieOption valida a opção "movie" dos comandos de avaliação às cegas, que já responderam de forma adiada
+func (h *Handlers) selectedMovieOption(s Responder, i *discordgo.InteractionCreate, movieValue string) *database.MovieResult {
+	suggestionID, ok := parseSuggestionID(movieValue)
+	if !ok {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Please pick a movie from the list of selected movies shown while typing."),
+		})
+		return nil
+	}
+
+	movie, err := h.db.GetSelectedMovie(i.GuildID, suggestionID)
+	if err != nil || movie == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Could not find that selected movie."),
+		})
+		return nil
+	}
+	return movie
+}
+
+func (h *Handlers) HandleBlindRatings(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This command can only be used in a server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can hide ratings!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	var movieValue, revealIn string
+	var revealAfter int
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "movie":
+			movieValue = opt.StringValue()
+		case "reveal_in":
+			revealIn = opt.StringValue()
+		case "reveal_after":
+			revealAfter = int(opt.IntValue())
+		}
+	}
+
+	var revealAt *time.Time
+	if revealIn != "" {
+		delay, err := parseRevealDelay(revealIn)
+		if err != nil {
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: ptrString(fmt.Sprintf("❌ Invalid reveal time: %s.", err)),
+			})
+			return
+		}
+		at := time.Now().Add(delay)
+		revealAt = &at
+	}
+
+	movie := h.selectedMovieOption(s, i, movieValue)
+	if movie == nil {
+		return
+	}
+
+	existing, _ := h.db.GetBlindRating(guildID, movie.ID)
+	if existing != nil && !existing.IsHidden() {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString(fmt.Sprintf("❌ The ratings for **%s** have already been revealed.", movie.MovieName)),
+		})
+		return
+	}
+
+	// Quem já viu notas publicadas não avalia mais às cegas; só um filme já escondido pode mudar de prazo
+	_, reviewCount, _ := h.db.GetAverageMovieRating(guildID, movie.ID)
+	if existing == nil && reviewCount > 0 {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString(fmt.Sprintf("❌ **%s** already has %d visible review%s, so its ratings can no longer be hidden.",
+				movie.MovieName, reviewCount, pluralize(reviewCount))),
+		})
+		return
+	}
+
+	if err := h.db.SetBlindRatings(guildID, movie.ID, revealAt, revealAfter); err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while hiding the ratings. Please try again."),
+		})
+		return
+	}
+	h.syncSuggestionPosts(s, guildID, movie.ID)
+
+	if revealAt != nil {
+		h.scheduleReveal(s, guildID, movie.ID, *revealAt)
+	} else {
+		h.cancelReveal(movie.ID)
+	}
+
+	blind := &database.BlindRating{GuildID: guildID, SuggestionID: movie.ID, RevealAt: revealAt, RevealAfter: revealAfter}
+	content := fmt.Sprintf("🙈 Ratings for **%s** are now hidden. Members will only see who has rated until the reveal.\n%s",
+		movie.MovieName, revealConditions(blind))
+
+	// Um novo limite pode já ter sido alcançado pelas avaliações escondidas
+	if revealAfter > 0 && reviewCount >= revealAfter && h.revealRatings(s, guildID, movie.ID) {
+		content = fmt.Sprintf("🎭 **%s** already has %d rating%s, so the full breakdown was revealed right away.",
+			movie.MovieName, reviewCount, pluralize(reviewCount))
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: ptrString(content),
+	})
+}
+
+func (h *Handlers) HandleRevealRatings(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This command can only be used in a server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can reveal ratings!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	var movieValue string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "movie" {
+			movieValue = opt.StringValue()
+		}
+	}
+
+	movie := h.selectedMovieOption(s, i, movieValue)
+	if movie == nil {
+		return
+	}
+
+	blind, _ := h.db.GetBlindRating(guildID, movie.ID)
+	if blind == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString(fmt.Sprintf("❌ **%s** is not using blind ratings.", movie.MovieName)),
+		})
+		return
+	}
+
+	msg := fmt.Sprintf("🎭 The ratings for **%s** were revealed and the full breakdown was posted.", movie.MovieName)
+	if !h.revealRatings(s, guildID, movie.ID) {
+		msg = fmt.Sprintf("❌ The ratings for **%s** have already been revealed.", movie.MovieName)
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: ptrString(msg),
+	})
+}
+
+// scheduleReveal agenda a revelação automática das notas para o prazo escolhido
+func (h *Handlers) scheduleReveal(s Responder, guildID string, suggestionID int, revealAt time.Time) {
+	h.revealMu.Lock()
+	defer h.revealMu.Unlock()
+
+	if timer, ok := h.revealTimers[suggestionID]; ok {
+		timer.Stop()
+	}
+	h.revealTimers[suggestionID] = time.AfterFunc(time.Until(revealAt), func() {
+		// Como na apuração das votações, a revelação entra em background para o Shutdown esperá-la. O Shutdown fecha
+		// stopping antes de pegar o revealMu, então quem passou da checagem já está no background quando ele espera.
+		h.revealMu.Lock()
+		select {
+		case <-h.stopping:
+			h.revealMu.Unlock()
+			return
+		default:
+		}
+		h.background.Add(1)
+		h.revealMu.Unlock()
+		defer h.background.Done()
+		h.revealRatings(s, guildID, suggestionID)
+	})
+}
+
+func (h *Handlers) cancelReveal(suggestionID int) {
+	h.revealMu.Lock()
+	defer h.revealMu.Unlock()
+
+	if timer, ok := h.revealTimers[suggestionID]; ok {
+		timer.Stop()
+		delete(h.revealTimers, suggestionID)
+	}
+}
+
+// ResumeReveals reagenda as revelações pendentes quando o bot liga; as que venceram com ele desligado
+// são publicadas na hora
+func (h *Handlers) ResumeReveals(s Responder) error {
+	reveals, err := h.db.GetPendingReveals()
+	if err != nil {
+		return err
+	}
+
+	for _, blind := range reveals {
+		if blind.RevealAt.After(time.Now()) {
+			h.scheduleReveal(s, blind.GuildID, blind.SuggestionID, *blind.RevealAt)
+		} else {
+			h.revealRatings(s, blind.GuildID, blind.SuggestionID)
+		}
+	}
+	if len(reveals) > 0 {
+		log.Printf("%d revelação(ões) de avaliações às cegas retomada(s)", len(reveals))
+	}
+	return nil
+}
+
+// revealEnoughRatings revela as notas quando o número de avaliações atinge o limite configurado
+func (h *Handlers) revealEnoughRatings(s Responder, blind *database.BlindRating, reviewCount int) bool {
+	if blind.RevealAfter == 0 || reviewCount < blind.RevealAfter {
+		return false
+	}
+	return h.revealRatings(s, blind.GuildID, blind.SuggestionID)
+}
+
+// revealRatings publica as notas escondidas no tópico de discussão do filme ou, sem tópico, no canal de
+// sugestões. Devolve false se elas já tinham sido reveladas por outro caminho.
+func (h *Handlers) revealRatings(s Responder, guildID string, suggestionID int) bool {
+	h.cancelReveal(suggestionID)
+
+	revealed, err := h.db.RevealRatings(guildID, suggestionID)
+	if err != nil || !revealed {
+		if err != nil {
+			log.Printf("Erro ao revelar as avaliações da sugestão %d: %v", suggestionID, err)
+		}
+		return false
+	}
+	h.syncSuggestionPosts(s, guildID, suggestionID)
+
+	movie, err := h.db.GetSelectedMovie(guildID, suggestionID)
+	if err != nil || movie == nil {
+		return true
+	}
+	reviews, err := h.db.GetMovieReviews(guildID, movie.ID)
+	if err != nil {
+		log.Printf("Erro ao carregar as avaliações reveladas de %s: %v", movie.MovieName, err)
+		return true
+	}
+
+	channelID := ""
+	if screening, err := h.db.GetScreening(guildID, movie.ID); err == nil && screening != nil && screening.ThreadID != "" {
+		channelID = screening.ThreadID
+	} else if config, err := h.db.GetGuildConfig(guildID); err == nil && config != nil {
+		channelID = config.SuggestionChannelID
+	}
+	if channelID == "" {
+		return true
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(channelID, h.revealEmbed(movie, reviews)); err != nil {
+		log.Printf("Erro ao publicar as avaliações reveladas de %s: %v", movie.MovieName, err)
+	}
+	return true
+}
+
+// revealEmbed é o resultado completo das avaliações às cegas, da maior para a menor nota
+func (h *Handlers) revealEmbed(movie *database.MovieResult, reviews []database.MovieReview) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:  fmt.Sprintf("🎭 Ratings Revealed: %s (%s)", movie.MovieName, movie.ReleaseYear),
+		Color:  0xFFD700,
+		Fields: []*discordgo.MessageEmbedField{},
+	}
+
+	if len(reviews) == 0 {
+		embed.Description = "Nobody rated this movie before the reveal."
+	} else {
+		var total float64
+		for _, review := range reviews {
+			total += review.Rating
+		}
+		embed.Description = fmt.Sprintf("📊 Community Rating: ⭐ **%.1f/10** based on %d review%s",
+			total/float64(len(reviews)), len(reviews), pluralize(len(reviews)))
+	}
+
+	sorted := append([]database.MovieReview(nil), reviews...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Rating > sorted[b].Rating })
+
+	maxReviews := 20
+	if len(sorted) < maxReviews {
+		maxReviews = len(sorted)
+	}
+	for _, review := range sorted[:maxReviews] {
+		value := "*No written review*"
+		if review.ReviewText != "" {
+			value = truncate(review.ReviewText, 200)
+			if review.Spoiler {
+				value = fmt.Sprintf("||%s||", value)
+			}
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("⭐ %.1f/10 — %s", review.Rating, review.Username),
+			Value:  value,
+			Inline: false,
+		})
+	}
+	if len(sorted) > maxReviews {
+		embed.Footer = &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Showing %d of %d reviews", maxReviews, len(sorted)),
+		}
+	}
+
+	if posterURL := h.tmdb.GetPosterURL(movie.PosterPath); posterURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: posterURL}
+	}
+
+	return embed
+}
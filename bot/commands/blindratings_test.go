@@ -0,0 +1,287 @@
+package commands
+
+import (
+	"clapper/database"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestResumeReveals(t *testing.T) {
+	env := newTestEnv(t)
+	env.configure()
+
+	overdue := env.selectMovie(movieHeat, testMember)
+	revealedAt := time.Now().Add(-time.Minute)
+	env.store.SetBlindRatings(testGuildID, overdue, &revealedAt, 0)
+
+	pending := env.selectMovie(movieAlien, testMember)
+	revealAt := time.Now().Add(time.Hour)
+	env.store.SetBlindRatings(testGuildID, pending, &revealAt, 0)
+
+	// Sem prazo, a revelação depende do /revealratings e não é agendada
+	manual := env.selectMovie(movieParasite, testMember)
+	env.store.SetBlindRatings(testGuildID, manual, nil, 5)
+
+	if err := env.handlers.ResumeReveals(env.responder); err != nil {
+		t.Fatal(err)
+	}
+
+	if blind, _ := env.store.GetBlindRating(testGuildID, overdue); blind == nil || blind.IsHidden() {
+		t.Errorf("expected the overdue reveal to happen right away, got %+v", blind)
+	}
+	if len(env.responder.sent) != 1 || env.responder.sent[0].channelID != testChannelID {
+		t.Errorf("expected one breakdown in the suggestion channel, got %+v", env.responder.sent)
+	}
+
+	var scheduled []int
+	for suggestionID := range env.handlers.revealTimers {
+		scheduled = append(scheduled, suggestionID)
+	}
+	if !reflect.DeepEqual(scheduled, []int{pending}) {
+		t.Errorf("expected only suggestion %d to be scheduled, got %v", pending, scheduled)
+	}
+
+	for _, id := range []int{pending, manual} {
+		if blind, _ := env.store.GetBlindRating(testGuildID, id); blind == nil || !blind.IsHidden() {
+			t.Errorf("expected suggestion %d to stay hidden, got %+v", id, blind)
+		}
+	}
+}
+
+func TestRevealTimersAfterShutdown(t *testing.T) {
+	env := newTestEnv(t)
+	env.configure()
+	id := env.selectMovie(movieHeat, testMember)
+	revealAt := time.Now()
+	env.store.SetBlindRatings(testGuildID, id, &revealAt, 0)
+
+	// Um timer que dispara depois do Shutdown não revela nada; a revelação é retomada na próxima inicialização
+	env.handlers.Shutdown()
+	env.handlers.scheduleReveal(env.responder, testGuildID, id, revealAt)
+	time.Sleep(20 * time.Millisecond)
+	env.handlers.background.Wait()
+
+	if blind, _ := env.store.GetBlindRating(testGuildID, id); blind == nil || !blind.IsHidden() {
+		t.Errorf("expected the ratings to stay hidden after shutdown, got %+v", blind)
+	}
+	if len(env.responder.sent) != 0 {
+		t.Errorf("nothing should be posted after shutdown, got %+v", env.responder.sent)
+	}
+}
+
+func TestBlindRatings(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "blindratings hides the ratings with automatic reveals",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.selectMovie(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "blindratings", stringOption("movie", env.ref(movieHeat)),
+					stringOption("reveal_in", "2d"), intOption("reveal_after", 3))
+			},
+			want: []string{"Ratings for **Heat** are now hidden", "revealed automatically <t:", "or once 3 members have rated"},
+			check: func(t *testing.T, env *testEnv) {
+				blind, _ := env.store.GetBlindRating(testGuildID, env.ids["Heat"])
+				if blind == nil || !blind.IsHidden() || blind.RevealAfter != 3 || blind.RevealAt == nil {
+					t.Fatalf("expected hidden ratings with both reveal conditions, got %+v", blind)
+				}
+				if len(env.handlers.revealTimers) != 1 {
+					t.Errorf("expected the reveal to be scheduled, got %d timers", len(env.handlers.revealTimers))
+				}
+			},
+		},
+		{
+			name:  "blindratings requires a host",
+			setup: func(env *testEnv) { env.selectMovie(movieHeat, testOther) },
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "blindratings", stringOption("movie", env.ref(movieHeat)))
+			},
+			want: []string{"Only administrators and movie night hosts can hide ratings"},
+		},
+		{
+			name: "blindratings rejects invalid reveal times",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.selectMovie(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "blindratings", stringOption("movie", env.ref(movieHeat)), stringOption("reveal_in", "60d"))
+			},
+			want: []string{"Invalid reveal time", "between 1 minute and 30 days"},
+		},
+		{
+			name: "blindratings refuses movies with visible reviews",
+			setup: func(env *testEnv) {
+				env.configure()
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SaveMovieReview(&database.MovieReview{SuggestionID: id, GuildID: testGuildID, UserID: "3", Username: "member", Rating: 9})
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "blindratings", stringOption("movie", env.ref(movieHeat)))
+			},
+			want: []string{"already has 1 visible review", "can no longer be hidden"},
+			check: func(t *testing.T, env *testEnv) {
+				if blind, _ := env.store.GetBlindRating(testGuildID, env.ids["Heat"]); blind != nil {
+					t.Errorf("ratings should stay visible, got %+v", blind)
+				}
+			},
+		},
+		{
+			name: "ratemovie hides the other scores while blind",
+			setup: func(env *testEnv) {
+				id := env.selectMovie(movieHeat, testOther)
+				env.post(movieHeat)
+				env.store.SetBlindRatings(testGuildID, id, nil, 0)
+				env.store.SaveMovieReview(&database.MovieReview{SuggestionID: id, GuildID: testGuildID, UserID: testOther.User.ID, Username: "other", Rating: 3})
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "ratemovie", stringOption("movie_name", env.ref(movieHeat)), stringOption("rating", "8"))
+			},
+			want: []string{"Your rating: **8.0/10**", "Blind Rating", "when a host runs `/revealratings`", "Rated so far (2):** <@4>, <@3>"},
+			check: func(t *testing.T, env *testEnv) {
+				if got := env.responder.lastMessage(); strings.Contains(got, "Community Rating") || strings.Contains(got, "3.0") {
+					t.Errorf("the community rating should be hidden:\n%s", got)
+				}
+				if text, _ := env.postEdit(t, "post-"+env.ref(movieHeat)); strings.Contains(text, "Community Rating") {
+					t.Errorf("the suggestion post should hide the community rating, got %q", text)
+				}
+			},
+		},
+		{
+			name: "ratemovie only announces blind ratings in the discussion thread",
+			setup: func(env *testEnv) {
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SetDiscussionThread(testGuildID, id, "thread-9")
+				env.store.SetBlindRatings(testGuildID, id, nil, 0)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "ratemovie", stringOption("movie_name", env.ref(movieHeat)),
+					stringOption("rating", "9"), stringOption("review", "The ending!"))
+			},
+			want: []string{"<#thread-9> was told you rated, without your score"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.sent) != 1 {
+					t.Fatalf("expected one thread message, got %+v", env.responder.sent)
+				}
+				if content := env.responder.sent[0].data.Content; !strings.Contains(content, "<@3> submitted a blind rating") || strings.Contains(content, "9.0") || strings.Contains(content, "The ending!") {
+					t.Errorf("unexpected thread message %q", content)
+				}
+			},
+		},
+		{
+			name: "moviereviews lists only who rated while blind",
+			setup: func(env *testEnv) {
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SetBlindRatings(testGuildID, id, nil, 0)
+				env.store.SaveMovieReview(&database.MovieReview{SuggestionID: id, GuildID: testGuildID, UserID: "3", Username: "member", Rating: 9, ReviewText: "Classic"})
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "moviereviews", stringOption("movie_name", env.ref(movieHeat)))
+			},
+			want: []string{"Blind Rating", "Rated so far (1):** <@3>"},
+			check: func(t *testing.T, env *testEnv) {
+				if got := env.responder.lastMessage(); strings.Contains(got, "Classic") || strings.Contains(got, "9.0") {
+					t.Errorf("reviews should be hidden:\n%s", got)
+				}
+			},
+		},
+		{
+			name: "selectedmovies hides the community rating while blind",
+			setup: func(env *testEnv) {
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SetBlindRatings(testGuildID, id, nil, 0)
+				env.store.SaveMovieReview(&database.MovieReview{SuggestionID: id, GuildID: testGuildID, UserID: "3", Username: "member", Rating: 9})
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "selectedmovies")
+			},
+			want: []string{"Community: 🙈 Hidden until the reveal (1 review)"},
+		},
+		{
+			name: "ratemovie reveals once enough members rated",
+			setup: func(env *testEnv) {
+				env.configure()
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SetBlindRatings(testGuildID, id, nil, 2)
+				env.store.SaveMovieReview(&database.MovieReview{SuggestionID: id, GuildID: testGuildID, UserID: testOther.User.ID, Username: "other", Rating: 6, ReviewText: "Too long", Spoiler: true})
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "ratemovie", stringOption("movie_name", env.ref(movieHeat)), stringOption("rating", "9"), stringOption("review", "Great heist"))
+			},
+			want: []string{"Ratings Revealed", "Yours was rating #2", "7.5/10 (2 reviews)"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.sent) != 1 || env.responder.sent[0].channelID != testChannelID {
+					t.Fatalf("expected the breakdown in the suggestion channel, got %+v", env.responder.sent)
+				}
+				got := strings.Join(embedText(env.responder.sent[0].data.Embeds), "\n")
+				for _, want := range []string{"Ratings Revealed: Heat (1995)", "7.5/10** based on 2 reviews", "9.0/10 — member\nGreat heist\n", "6.0/10 — other\n||Too long||"} {
+					if !strings.Contains(got, want) {
+						t.Errorf("breakdown does not contain %q:\n%s", want, got)
+					}
+				}
+			},
+		},
+		{
+			name: "revealratings posts the breakdown in the discussion thread",
+			setup: func(env *testEnv) {
+				env.configure()
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SetDiscussionThread(testGuildID, id, "thread-9")
+				revealAt := time.Now().Add(time.Hour)
+				env.store.SetBlindRatings(testGuildID, id, &revealAt, 0)
+				env.handlers.scheduleReveal(env.responder, testGuildID, id, revealAt)
+				env.store.SaveMovieReview(&database.MovieReview{SuggestionID: id, GuildID: testGuildID, UserID: "3", Username: "member", Rating: 9})
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "revealratings", stringOption("movie", env.ref(movieHeat)))
+			},
+			want: []string{"ratings for **Heat** were revealed"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.sent) != 1 || env.responder.sent[0].channelID != "thread-9" {
+					t.Fatalf("expected the breakdown in the thread, got %+v", env.responder.sent)
+				}
+				if len(env.handlers.revealTimers) != 0 {
+					t.Errorf("the reveal timer should be cancelled, got %d timers", len(env.handlers.revealTimers))
+				}
+				if blind, _ := env.store.GetBlindRating(testGuildID, env.ids["Heat"]); blind == nil || blind.IsHidden() {
+					t.Errorf("expected the ratings to be revealed, got %+v", blind)
+				}
+			},
+		},
+		{
+			name: "revealratings only reveals once",
+			setup: func(env *testEnv) {
+				env.configure()
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SetBlindRatings(testGuildID, id, nil, 0)
+				env.store.RevealRatings(testGuildID, id)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "revealratings", stringOption("movie", env.ref(movieHeat)))
+			},
+			want: []string{"ratings for **Heat** have already been revealed"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.sent) != 0 {
+					t.Errorf("nothing should be posted, got %+v", env.responder.sent)
+				}
+			},
+		},
+		{
+			name: "revealratings needs blind ratings",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.selectMovie(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "revealratings", stringOption("movie", env.ref(movieHeat)))
+			},
+			want: []string{"**Heat** is not using blind ratings"},
+		},
+	})
+}
@@ -1,6 +1,10 @@
 package commands
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"clapper/database"
+
+	"github.com/bwmarrin/discordgo"
+)
 
 var Commands = []*discordgo.ApplicationCommand{
 	{
@@ -8,12 +12,178 @@ var Commands = []*discordgo.ApplicationCommand{
 		Description: "Configure the bot for this server (Admin only)",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Type:        discordgo.ApplicationCommandOptionChannel,
-				Name:        "suggestion_channel",
-				Description: "The channel where movie suggestions will be posted",
-				Required:    true,
-				ChannelTypes: []discordgo.ChannelType{
-					discordgo.ChannelTypeGuildText,
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "channels",
+				Description: "Configure the suggestion channel, event channel and timezone",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionChannel,
+						Name:        "suggestion_channel",
+						Description: "The channel where movie suggestions will be posted",
+						Required:    true,
+						ChannelTypes: []discordgo.ChannelType{
+							discordgo.ChannelTypeGuildText,
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionChannel,
+						Name:        "event_channel",
+						Description: "Voice or stage channel used for movie night events",
+						Required:    false,
+						ChannelTypes: []discordgo.ChannelType{
+							discordgo.ChannelTypeGuildVoice,
+							discordgo.ChannelTypeGuildStageVoice,
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "timezone",
+						Description: "Timezone for movie night dates, e.g. America/Sao_Paulo (default: UTC)",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "roles",
+				Description: "Choose which roles can host movie nights and suggest movies",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionRole,
+						Name:        "host_role",
+						Description: "Role allowed to pick, reroll, confirm and moderate suggestions",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionRole,
+						Name:        "suggester_role",
+						Description: "Only members with this role (or hosts) may suggest movies",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "clear",
+						Description: "Remove both role settings (applied before the roles above)",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "picking",
+				Description: "Choose how /pickmovie draws movies in random mode",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "strategy",
+						Description: "How random picks are drawn",
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: pickStrategyNames[database.PickStrategyUniform], Value: database.PickStrategyUniform},
+							{Name: pickStrategyNames[database.PickStrategyRoundRobin], Value: database.PickStrategyRoundRobin},
+							{Name: pickStrategyNames[database.PickStrategyWeightedByWait], Value: database.PickStrategyWeightedByWait},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "vetoes",
+				Description: "Give members veto tokens to force a reroll of /pickmovie results",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "per_season",
+						Description: "Vetoes each member can spend per season (0 disables vetoes)",
+						Required:    false,
+						MinValue:    ptrFloat(0),
+						MaxValue:    20,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "ban_vetoed",
+						Description: "Keep vetoed movies out of the draw until the season ends",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "limits",
+				Description: "Limit how many movies each member can suggest and how often",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "max_open",
+						Description: "Suggestions each member can have waiting to be picked (0 removes the limit)",
+						Required:    false,
+						MinValue:    ptrFloat(0),
+						MaxValue:    100,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "cooldown",
+						Description: "Minimum time between suggestions, e.g. 12h or 2d (0 removes the cooldown)",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "moderation",
+				Description: "Require hosts to approve suggestions before they are posted and can be picked",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "require_approval",
+						Description: "Hold new suggestions in the moderation channel until a host approves them",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionChannel,
+						Name:        "mod_channel",
+						Description: "Channel where hosts approve or reject suggestions",
+						Required:    false,
+						ChannelTypes: []discordgo.ChannelType{
+							discordgo.ChannelTypeGuildText,
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "discussions",
+				Description: "Choose where the discussion thread of each confirmed movie is opened",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionChannel,
+						Name:        "forum",
+						Description: "Forum channel for the discussion posts (default: a thread on the confirmation message)",
+						Required:    false,
+						ChannelTypes: []discordgo.ChannelType{
+							discordgo.ChannelTypeGuildForum,
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "clear",
+						Description: "Stop using the forum and open threads on the confirmation message again",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "language",
+				Description: "Choose the language of movie titles, overviews and genres from TMDB",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "language",
+						Description: "Language used for this server",
+						Required:    true,
+						Choices:     tmdbLanguages,
+					},
 				},
 			},
 		},
@@ -24,14 +194,24 @@ var Commands = []*discordgo.ApplicationCommand{
 	},
 	{
 		Name:        "suggestion",
-		Description: "Suggest a movie for your server using movie name or TMDB link",
+		Description: "Suggest a movie or TV series for your server using its name or a TMDB, IMDb or Letterboxd link",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "movie_name",
-				Description: "Movie name or TMDB link (e.g., https://www.themoviedb.org/movie/74)",
+				Description: "Title or a TMDB, IMDb or Letterboxd link (e.g., https://letterboxd.com/film/heat/)",
 				Required:    true,
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "type",
+				Description: "Whether to search movies or TV series (default: movie; links decide on their own)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Movie", Value: database.MediaMovie},
+					{Name: "TV series", Value: database.MediaTV},
+				},
+			},
 		},
 	},
 	{
@@ -45,10 +225,81 @@ var Commands = []*discordgo.ApplicationCommand{
 	{
 		Name:        "suggestions",
 		Description: "View all movie suggestions in this server",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "status",
+				Description: "Which suggestions to list (default: approved)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Approved", Value: database.SuggestionApproved},
+					{Name: "Awaiting approval", Value: database.SuggestionPending},
+					{Name: "Rejected", Value: database.SuggestionRejected},
+					{Name: "All", Value: suggestionStatusAll},
+				},
+			},
+		},
 	},
 	{
 		Name:        "pickmovie",
 		Description: "Pick a random movie from the suggestions",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "mode",
+				Description: "How the movie is drawn (default: random)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Random (follows the server's picking strategy)", Value: pickModeRandom},
+					{Name: "Weighted by community votes", Value: pickModeVotes},
+				},
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "genre",
+				Description:  "Only pick movies of this genre",
+				Required:     false,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "min_year",
+				Description: "Only pick movies released in or after this year",
+				Required:    false,
+				MinValue:    ptrFloat(1870),
+				MaxValue:    3000,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "max_year",
+				Description: "Only pick movies released in or before this year",
+				Required:    false,
+				MinValue:    ptrFloat(1870),
+				MaxValue:    3000,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "max_runtime",
+				Description: "Maximum runtime in minutes",
+				Required:    false,
+				MinValue:    ptrFloat(1),
+				MaxValue:    1000,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionNumber,
+				Name:        "min_tmdb_rating",
+				Description: "Minimum TMDB rating (0-10)",
+				Required:    false,
+				MinValue:    ptrFloat(0),
+				MaxValue:    10,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "exclude_suggester",
+				Description: "Skip movies suggested by this member",
+				Required:    false,
+			},
+		},
 	},
 	{
 		Name:        "moviestats",
@@ -60,9 +311,10 @@ var Commands = []*discordgo.ApplicationCommand{
 		Options: []*discordgo.ApplicationCommandOption{
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
-				Name:        "movie_name",
-				Description: "The name of the movie to remove",
-				Required:    true,
+				Name:         "movie_name",
+				Description:  "The name of the movie to remove",
+				Required:     true,
+				Autocomplete: true,
 			},
 		},
 	},
@@ -72,9 +324,10 @@ var Commands = []*discordgo.ApplicationCommand{
 		Options: []*discordgo.ApplicationCommandOption{
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
-				Name:        "movie_name",
-				Description: "The name of the movie to rate",
-				Required:    true,
+				Name:         "movie_name",
+				Description:  "The name of the movie to rate",
+				Required:     true,
+				Autocomplete: true,
 			},
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
@@ -88,6 +341,12 @@ var Commands = []*discordgo.ApplicationCommand{
 				Description: "Your review of the movie (optional)",
 				Required:    false,
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "spoiler",
+				Description: "Hide your review behind spoiler tags in the movie's discussion thread",
+				Required:    false,
+			},
 		},
 	},
 	{
@@ -96,9 +355,10 @@ var Commands = []*discordgo.ApplicationCommand{
 		Options: []*discordgo.ApplicationCommandOption{
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
-				Name:        "movie_name",
-				Description: "The name of the movie",
-				Required:    true,
+				Name:         "movie_name",
+				Description:  "The name of the movie",
+				Required:     true,
+				Autocomplete: true,
 			},
 		},
 	},
@@ -106,4 +366,135 @@ var Commands = []*discordgo.ApplicationCommand{
 		Name:        "selectedmovies",
 		Description: "View all movies that have been selected",
 	},
+	{
+		Name:        "schedule",
+		Description: "Schedule or reschedule the movie night for a selected movie (Admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "movie",
+				Description:  "The selected movie",
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "when",
+				Description: "Date and time in the server timezone (YYYY-MM-DD HH:MM)",
+				Required:    true,
+			},
+		},
+	},
+	{
+		Name:        "unschedule",
+		Description: "Cancel the scheduled movie night for a selected movie (Admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "movie",
+				Description:  "The selected movie",
+				Required:     true,
+				Autocomplete: true,
+			},
+		},
+	},
+	{
+		Name:        "unselect",
+		Description: "Move a selected movie back into the pickable pool (Admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "movie",
+				Description:  "The selected movie",
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "reviews",
+				Description: "What to do with the movie's reviews",
+				Required:    true,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Keep them with the movie", Value: "keep"},
+					{Name: "Move them to the archive", Value: "archive"},
+				},
+			},
+		},
+	},
+	{
+		Name:        "blindratings",
+		Description: "Hide a selected movie's ratings until they are revealed (Admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "movie",
+				Description:  "The selected movie",
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "reveal_in",
+				Description: "Reveal automatically after this long (e.g. 12h, 2d)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "reveal_after",
+				Description: "Reveal automatically once this many members have rated",
+				Required:    false,
+				MinValue:    ptrFloat(1),
+				MaxValue:    100,
+			},
+		},
+	},
+	{
+		Name:        "revealratings",
+		Description: "Reveal a selected movie's hidden ratings and post the full breakdown (Admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "movie",
+				Description:  "The selected movie",
+				Required:     true,
+				Autocomplete: true,
+			},
+		},
+	},
+	{
+		Name:        "resetselections",
+		Description: "Archive all selections and start a new season (Admin only)",
+	},
+	{
+		Name:        "refreshmetadata",
+		Description: "Fill in missing TMDB details (posters, overviews, runtimes) for suggestions (Admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "all",
+				Description: "Refresh every suggestion, not only the ones missing details",
+				Required:    false,
+			},
+		},
+	},
+	{
+		Name:        "moviepoll",
+		Description: "Start a ranked-choice poll between random suggestions",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "candidates",
+				Description: "How many movies to draw for the poll",
+				Required:    true,
+				MinValue:    ptrFloat(minPollCandidates),
+				MaxValue:    maxPollCandidates,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "duration",
+				Description: "How long the poll stays open (e.g. 30m, 2h, 1d)",
+				Required:    true,
+			},
+		},
+	},
 }
\ No newline at end of file
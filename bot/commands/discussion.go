@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"clapper/database"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Tópicos de discussão ficam abertos por uma semana sem mensagens antes de serem arquivados
+const discussionArchiveMinutes = 10080
+
+// discussionSeed é a primeira mensagem do tópico, com a sinopse, a duração (ou as temporadas, nas séries)
+// e o aviso de spoilers
+func (h *Handlers) discussionSeed(movie *database.MovieResult) *discordgo.MessageSend {
+	spoilers := "This thread discusses the whole movie. Don't read on until you've watched it, and wrap big reveals in ||spoiler tags||."
+	if movie.IsTV() {
+		spoilers = "This thread discusses the whole series. Say which episode you're on, and wrap anything past it in ||spoiler tags||."
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("🍿 %s (%s)", movie.MovieName, movie.ReleaseYear),
+		Description: truncate(movie.Overview, 1000),
+		Color:       0x9B59B6,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "⚠️ Spoiler Warning",
+				Value:  spoilers,
+				Inline: false,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Reviews sent with /ratemovie are posted here",
+		},
+	}
+
+	if episodes := formatEpisodes(movie.Seasons, movie.Episodes); movie.IsTV() && episodes != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "📺 Episodes", Value: episodes, Inline: true})
+	} else if movie.Runtime > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "⏱️ Runtime",
+			Value:  formatDuration(time.Duration(movie.Runtime) * time.Minute),
+			Inline: true,
+		})
+	}
+	if movie.Genres != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "🎭 Genres", Value: movie.Genres, Inline: true})
+	}
+
+	if posterURL := h.tmdb.GetPosterURL(movie.PosterPath); posterURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: posterURL}
+	}
+
+	return &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+}
+
+// openDiscussion abre o tópico do filme confirmado no fórum configurado ou, sem fórum, na própria
+// mensagem de confirmação, e grava o tópico na linha de selected_movies
+func (h *Handlers) openDiscussion(s Responder, guildConfig *database.GuildConfig, movie *database.MovieResult, confirmation *discordgo.Message) error {
+	threadStart := &discordgo.ThreadStart{
+		Name:                truncate(fmt.Sprintf("🎬 %s (%s)", movie.MovieName, movie.ReleaseYear), 100),
+		AutoArchiveDuration: discussionArchiveMinutes,
+	}
+
+	var thread *discordgo.Channel
+	var err error
+	if guildConfig.DiscussionForumID != "" {
+		thread, err = s.ForumThreadStartComplex(guildConfig.DiscussionForumID, threadStart, h.discussionSeed(movie))
+		if err != nil {
+			return fmt.Errorf("could not create the forum post: %w", err)
+		}
+	} else {
+		thread, err = s.MessageThreadStartComplex(confirmation.ChannelID, confirmation.ID, threadStart)
+		if err != nil {
+			return fmt.Errorf("could not start the thread: %w", err)
+		}
+		if _, err := s.ChannelMessageSendComplex(thread.ID, h.discussionSeed(movie)); err != nil {
+			log.Printf("Erro ao enviar a mensagem inicial do tópico de %s: %v", movie.MovieName, err)
+		}
+	}
+
+	return h.db.SetDiscussionThread(guildConfig.GuildID, movie.ID, thread.ID)
+}
+
+// postReviewToDiscussion publica a avaliação no tópico do filme, escondendo o texto em spoiler quando pedido
+func postReviewToDiscussion(s Responder, threadID string, review *database.MovieReview, updated bool) error {
+	action := "rated"
+	if updated {
+		action = "updated their rating:"
+	}
+
+	content := fmt.Sprintf("⭐ <@%s> %s **%.1f/10**", review.UserID, action, review.Rating)
+	if review.ReviewText != "" {
+		text := review.ReviewText
+		if review.Spoiler {
+			text = fmt.Sprintf("||%s||", text)
+		}
+		content += "\n> " + strings.ReplaceAll(text, "\n", "\n> ")
+	}
+
+	_, err := s.ChannelMessageSendComplex(threadID, &discordgo.MessageSend{
+		Content: content,
+		// A menção identifica o autor sem notificá-lo a cada avaliação
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	return err
+}
+
+// postBlindRatingToDiscussion avisa no tópico que o membro avaliou, sem a nota nem o texto, até a revelação
+func postBlindRatingToDiscussion(s Responder, threadID, userID string, updated bool) error {
+	action := "submitted a blind rating"
+	if updated {
+		action = "updated their blind rating"
+	}
+
+	_, err := s.ChannelMessageSendComplex(threadID, &discordgo.MessageSend{
+		Content:         fmt.Sprintf("🙈 <@%s> %s. Scores stay hidden until the reveal.", userID, action),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	return err
+}
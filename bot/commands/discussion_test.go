@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestDiscussions(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "confirm opens a discussion thread on the confirmation message",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testHost, fmt.Sprintf("confirm_modal_%s_%s", testGuildID, env.ref(movieHeat)), map[string]string{"when": ""})
+			},
+			want: []string{"Selected Movie: Heat (1995)"},
+			check: func(t *testing.T, env *testEnv) {
+				threads := env.responder.threads
+				if len(threads) != 1 || threads[0].parentID != testChannelID || threads[0].messageID != "response-1" || threads[0].name != "🎬 Heat (1995)" {
+					t.Fatalf("expected a thread on the confirmation message, got %+v", threads)
+				}
+				if len(env.responder.sent) != 1 || env.responder.sent[0].channelID != threads[0].id {
+					t.Fatalf("expected the thread to be seeded, got %+v", env.responder.sent)
+				}
+				seed := strings.Join(embedText(env.responder.sent[0].data.Embeds), "\n")
+				for _, want := range []string{"Spoiler Warning", "Runtime\n2h 50m"} {
+					if !strings.Contains(seed, want) {
+						t.Errorf("seed message missing %q:\n%s", want, seed)
+					}
+				}
+				if screening, _ := env.store.GetScreening(testGuildID, env.ids["Heat"]); screening.ThreadID != threads[0].id {
+					t.Errorf("thread = %q, want %q", screening.ThreadID, threads[0].id)
+				}
+			},
+		},
+		{
+			name: "confirm opens a forum post when a forum is configured",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveDiscussionSettings(testGuildID, testForumID)
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testHost, fmt.Sprintf("confirm_modal_%s_%s", testGuildID, env.ref(movieHeat)), map[string]string{"when": ""})
+			},
+			want: []string{"Selected Movie: Heat (1995)"},
+			check: func(t *testing.T, env *testEnv) {
+				threads := env.responder.threads
+				if len(threads) != 1 || threads[0].parentID != testForumID || threads[0].starter == nil {
+					t.Fatalf("expected a forum post with the seed message, got %+v", threads)
+				}
+				if len(env.responder.sent) != 0 {
+					t.Errorf("forum posts are seeded by their starter message, got %+v", env.responder.sent)
+				}
+			},
+		},
+		{
+			name: "confirm still selects the movie when the thread cannot be opened",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.responder.threadErr = fmt.Errorf("HTTP 403 Forbidden")
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testHost, fmt.Sprintf("confirm_modal_%s_%s", testGuildID, env.ref(movieHeat)), map[string]string{"when": ""})
+			},
+			want: []string{"Selected Movie: Heat (1995)"},
+			check: func(t *testing.T, env *testEnv) {
+				if movie, _ := env.store.GetSelectedMovie(testGuildID, env.ids["Heat"]); movie == nil {
+					t.Error("movie should be selected")
+				}
+			},
+		},
+		{
+			name: "ratemovie posts the review in the discussion thread behind spoiler tags",
+			setup: func(env *testEnv) {
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SetDiscussionThread(testGuildID, id, "thread-9")
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "ratemovie", stringOption("movie_name", env.ref(movieHeat)),
+					boolOption("spoiler", true), stringOption("rating", "9"), stringOption("review", "The ending!"))
+			},
+			want: []string{"Review added for Heat", "also posted in <#thread-9>"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.sent) != 1 || env.responder.sent[0].channelID != "thread-9" {
+					t.Fatalf("expected the review in the thread, got %+v", env.responder.sent)
+				}
+				if content := env.responder.sent[0].data.Content; !strings.Contains(content, "**9.0/10**") || !strings.Contains(content, "||The ending!||") {
+					t.Errorf("unexpected thread message %q", content)
+				}
+				if review, _ := env.store.GetUserReview(testGuildID, env.ids["Heat"], testMember.User.ID); review == nil || !review.Spoiler {
+					t.Errorf("expected the spoiler answer to be saved, got %+v", review)
+				}
+			},
+		},
+		{
+			name: "moviereviews links to the discussion thread",
+			setup: func(env *testEnv) {
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SetDiscussionThread(testGuildID, id, "thread-9")
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "moviereviews", stringOption("movie_name", env.ref(movieHeat)))
+			},
+			want: []string{"Discussion\nJoin the conversation in <#thread-9>"},
+		},
+	})
+}
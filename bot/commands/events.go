@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"clapper/database"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const screeningTimeLayout = "2006-01-02 15:04"
+
+func guildLocation(guildConfig *database.GuildConfig) *time.Location {
+	if guildConfig == nil || guildConfig.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(guildConfig.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// parseScreeningTime interpreta "YYYY-MM-DD HH:MM" no fuso horário configurado para o servidor
+func parseScreeningTime(input string, loc *time.Location) (time.Time, error) {
+	when, err := time.ParseInLocation(screeningTimeLayout, strings.TrimSpace(input), loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date format, use YYYY-MM-DD HH:MM")
+	}
+	if !when.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("the date must be in the future")
+	}
+	return when, nil
+}
+
+func screeningModal(customID string, loc *time.Location, value string) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: customID,
+			Title:    "Schedule Movie Night",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "when",
+							Label:       "When? (YYYY-MM-DD HH:MM)",
+							Style:       discordgo.TextInputShort,
+							Placeholder: fmt.Sprintf("e.g. %s (%s) — leave empty to skip", time.Now().In(loc).AddDate(0, 0, 7).Format(screeningTimeLayout), loc.String()),
+							Value:       value,
+							Required:    false,
+							MaxLength:   16,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func modalValue(data discordgo.ModalSubmitInteractionData, customID string) string {
+	for _, component := range data.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, inner := range row.Components {
+			if input, ok := inner.(*discordgo.TextInput); ok && input.CustomID == customID {
+				return input.Value
+			}
+		}
+	}
+	return ""
+}
+
+// posterClient baixa as capas dos eventos; sem timeout um servidor de imagens travado seguraria o /schedule
+var posterClient = &http.Client{Timeout: 10 * time.Second}
+
+// maxPosterSize é o maior pôster aceito como capa; um arquivo cortado chegaria corrompido ao Discord
+const maxPosterSize = 8 << 20
+
+// posterDataURI baixa o pôster e o converte para o formato aceito como capa de evento pelo Discord
+func posterDataURI(ctx context.Context, posterURL string) string {
+	if posterURL == "" {
+		return ""
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, posterURL, nil)
+	if err != nil {
+		return ""
+	}
+	resp, err := posterClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPosterSize+1))
+	if err != nil || len(data) > maxPosterSize {
+		return ""
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	return fmt.Sprintf("data:%s;base64,%s", contentType, base64.StdEncoding.EncodeToString(data))
+}
+
+// scheduleScreening cria ou atualiza o evento agendado do filme e grava a data na linha de selected_movies.
+// Sem canal de eventos configurado, apenas a data é registrada.
+func (h *Handlers) scheduleScreening(s Responder, guildConfig *database.GuildConfig, movie *database.MovieResult, when time.Time) (string, error) {
+	guildID := guildConfig.GuildID
+
+	screening, err := h.db.GetScreening(guildID, movie.ID)
+	if err != nil {
+		return "", err
+	}
+
+	eventID := ""
+	if screening != nil {
+		eventID = screening.EventID
+	}
+
+	if guildConfig.EventChannelID == "" {
+		return "", h.db.SetScreening(guildID, movie.ID, eventID, when)
+	}
+
+	if eventID != "" {
+		_, err := s.GuildScheduledEventEdit(guildID, eventID, &discordgo.GuildScheduledEventParams{
+			ScheduledStartTime: &when,
+		})
+		if err == nil {
+			return eventID, h.db.SetScreening(guildID, movie.ID, eventID, when)
+		}
+		// O evento pode ter sido apagado manualmente; nesse caso criamos outro
+	}
+
+	entityType := discordgo.GuildScheduledEventEntityTypeVoice
+	channel, err := s.Channel(guildConfig.EventChannelID)
+	if err != nil {
+		return "", fmt.Errorf("could not access the event channel: %w", err)
+	}
+	if channel.Type == discordgo.ChannelTypeGuildStageVoice {
+		entityType = discordgo.GuildScheduledEventEntityTypeStageInstance
+	}
+
+	// O evento é criado mesmo sem capa quando o pôster não pode ser baixado a tempo
+	ctx, cancel := tmdbContext()
+	defer cancel()
+
+	params := &discordgo.GuildScheduledEventParams{
+		ChannelID:          guildConfig.EventChannelID,
+		Name:               truncate(fmt.Sprintf("🎬 %s (%s)", movie.MovieName, movie.ReleaseYear), 100),
+		ScheduledStartTime: &when,
+		PrivacyLevel:       discordgo.GuildScheduledEventPrivacyLevelGuildOnly,
+		EntityType:         entityType,
+		Description:        truncate(movie.Overview, 1000),
+		Image:              posterDataURI(ctx, h.tmdb.GetPosterURL(movie.PosterPath)),
+	}
+
+	event, err := s.GuildScheduledEventCreate(guildID, params)
+	if err != nil {
+		return "", fmt.Errorf("could not create the scheduled event: %w", err)
+	}
+
+	return event.ID, h.db.SetScreening(guildID, movie.ID, event.ID, when)
+}
+
+func (h *Handlers) cancelScreening(s Responder, guildID string, suggestionID int) error {
+	screening, err := h.db.GetScreening(guildID, suggestionID)
+	if err != nil {
+		return err
+	}
+
+	if screening != nil && screening.EventID != "" {
+		s.GuildScheduledEventEdit(guildID, screening.EventID, &discordgo.GuildScheduledEventParams{
+			Status: discordgo.GuildScheduledEventStatusCanceled,
+		})
+	}
+
+	return h.db.ClearScreening(guildID, suggestionID)
+}
+
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,1555 @@
+package commands
+
+import (
+	"clapper/database"
+	"clapper/tmdb"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeSelection espelha uma linha de selected_movies
+type fakeSelection struct {
+	selectedAt   time.Time
+	eventID      string
+	scheduledFor *time.Time
+	threadID     string
+	blind        *database.BlindRating
+}
+
+// fakeStore é uma implementação de Store em memória com a mesma semântica do SQLite
+type fakeStore struct {
+	mu sync.Mutex
+
+	nextID      int
+	configs     map[string]*database.GuildConfig
+	suggestions []*database.Suggestion
+	selected    map[int]*fakeSelection
+	votes       map[int]map[string]int
+	reviews     []*database.MovieReview
+	archived    map[string][]int
+	seasons     map[string]int
+	// refreshed guarda quando os metadados de cada sugestão foram gravados, como metadata_updated_at
+	refreshed map[int]time.Time
+	polls     map[int]*fakePoll
+	vetoes    []fakeVeto
+	// cooldowns espelha suggestion_cooldowns, por guild e usuário
+	cooldowns map[[2]string]time.Time
+}
+
+// fakeVeto espelha uma linha de vetoes
+type fakeVeto struct {
+	guildID      string
+	suggestionID int
+	userID       string
+	season       int
+	banned       bool
+}
+
+// fakePoll espelha uma votação com seus candidatos e as cédulas (usuário -> posição -> sugestão)
+type fakePoll struct {
+	poll       database.MoviePoll
+	candidates []int
+	ballots    map[string]map[int]int
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		configs:   map[string]*database.GuildConfig{},
+		selected:  map[int]*fakeSelection{},
+		votes:     map[int]map[string]int{},
+		archived:  map[string][]int{},
+		seasons:   map[string]int{},
+		refreshed: map[int]time.Time{},
+		polls:     map[int]*fakePoll{},
+		cooldowns: map[[2]string]time.Time{},
+	}
+}
+
+var _ Store = (*fakeStore)(nil)
+
+func (f *fakeStore) GetGuildConfig(guildID string) (*database.GuildConfig, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	config, ok := f.configs[guildID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *config
+	return &copied, nil
+}
+
+func (f *fakeStore) SaveGuildConfig(guildID, channelID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	config, ok := f.configs[guildID]
+	if !ok {
+		f.nextID++
+		config = &database.GuildConfig{ID: f.nextID, GuildID: guildID}
+		f.configs[guildID] = config
+	}
+	config.SuggestionChannelID = channelID
+	config.ConfiguredAt = time.Now()
+	return nil
+}
+
+func (f *fakeStore) SaveEventSettings(guildID, eventChannelID, timezone string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if config, ok := f.configs[guildID]; ok {
+		config.EventChannelID = eventChannelID
+		config.Timezone = timezone
+	}
+	return nil
+}
+
+func (f *fakeStore) SaveRoleSettings(guildID, hostRoleID, suggesterRoleID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if config, ok := f.configs[guildID]; ok {
+		config.HostRoleID = hostRoleID
+		config.SuggesterRoleID = suggesterRoleID
+	}
+	return nil
+}
+
+func (f *fakeStore) SavePickStrategy(guildID, strategy string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if config, ok := f.configs[guildID]; ok {
+		config.PickStrategy = strategy
+	}
+	return nil
+}
+
+func (f *fakeStore) SaveVetoSettings(guildID string, vetoesPerSeason int, banVetoed bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if config, ok := f.configs[guildID]; ok {
+		config.VetoesPerSeason = vetoesPerSeason
+		config.BanVetoed = banVetoed
+	}
+	return nil
+}
+
+func (f *fakeStore) SaveSuggestionLimits(guildID string, maxOpen int, cooldown time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if config, ok := f.configs[guildID]; ok {
+		config.MaxOpenSuggestions = maxOpen
+		config.SuggestionCooldown = cooldown.Round(time.Minute)
+	}
+	return nil
+}
+
+func (f *fakeStore) SaveModerationSettings(guildID string, requireApproval bool, modChannelID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if config, ok := f.configs[guildID]; ok {
+		config.RequireApproval = requireApproval
+		config.ModChannelID = modChannelID
+	}
+	return nil
+}
+
+func (f *fakeStore) SaveDiscussionSettings(guildID, forumID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if config, ok := f.configs[guildID]; ok {
+		config.DiscussionForumID = forumID
+	}
+	return nil
+}
+
+func (f *fakeStore) SaveLanguage(guildID, language string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if config, ok := f.configs[guildID]; ok {
+		config.Language = language
+	}
+	return nil
+}
+
+func (f *fakeStore) SaveSuggestion(s *database.Suggestion) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.saveSuggestion(s)
+}
+
+func (f *fakeStore) SaveSuggestionWithinLimits(s *database.Suggestion, maxOpen int, cooldown time.Duration) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	open, last := f.activity(s.GuildID, s.UserID)
+	if (maxOpen > 0 && open >= maxOpen) || (cooldown > 0 && !last.IsZero() && time.Since(last) < cooldown) {
+		return 0, &database.SuggestionLimitError{Open: open, LastSuggestedAt: last}
+	}
+	return f.saveSuggestion(s)
+}
+
+func (f *fakeStore) saveSuggestion(s *database.Suggestion) (int64, error) {
+	mediaType := s.MediaType
+	if mediaType == "" {
+		mediaType = database.MediaMovie
+	}
+	for _, existing := range f.suggestions {
+		if existing.GuildID == s.GuildID && existing.MediaType == mediaType && existing.TMDBID == s.TMDBID && existing.Status != database.SuggestionRejected {
+			return 0, errors.New("UNIQUE constraint failed: suggestions.guild_id, suggestions.media_type, suggestions.tmdb_id")
+		}
+	}
+
+	f.nextID++
+	saved := *s
+	saved.ID = f.nextID
+	saved.MediaType = mediaType
+	saved.SuggestedAt = time.Now()
+	if saved.Status == "" {
+		saved.Status = database.SuggestionApproved
+	}
+	saved.GenreIDs = append([]int(nil), s.GenreIDs...)
+	f.suggestions = append(f.suggestions, &saved)
+	f.refreshed[saved.ID] = time.Now()
+	return int64(saved.ID), nil
+}
+
+func (f *fakeStore) RemoveSuggestion(suggestionID int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if s := f.findSuggestion("", suggestionID); s != nil {
+		key := [2]string{s.GuildID, s.UserID}
+		if s.SuggestedAt.After(f.cooldowns[key]) {
+			f.cooldowns[key] = s.SuggestedAt
+		}
+	}
+	f.discardSuggestion(suggestionID)
+	return nil
+}
+
+func (f *fakeStore) DiscardSuggestion(suggestionID int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.discardSuggestion(suggestionID)
+	return nil
+}
+
+func (f *fakeStore) discardSuggestion(suggestionID int) {
+	for idx, s := range f.suggestions {
+		if s.ID == suggestionID {
+			f.suggestions = append(f.suggestions[:idx], f.suggestions[idx+1:]...)
+			break
+		}
+	}
+	delete(f.selected, suggestionID)
+	delete(f.votes, suggestionID)
+	var vetoes []fakeVeto
+	for _, v := range f.vetoes {
+		if v.suggestionID != suggestionID {
+			vetoes = append(vetoes, v)
+		}
+	}
+	f.vetoes = vetoes
+	f.reviews = filterReviews(f.reviews, func(r *database.MovieReview) bool { return r.SuggestionID != suggestionID })
+}
+
+func (f *fakeStore) findSuggestion(guildID string, suggestionID int) *database.Suggestion {
+	for _, s := range f.suggestions {
+		if s.ID == suggestionID && (guildID == "" || s.GuildID == guildID) {
+			return s
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) MovieAlreadySuggested(guildID, mediaType string, tmdbID int) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, s := range f.suggestions {
+		if s.GuildID == guildID && s.MediaType == mediaType && s.TMDBID == tmdbID && s.Status != database.SuggestionRejected {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeStore) GetMovieSuggester(guildID, mediaType string, tmdbID int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, s := range f.suggestions {
+		if s.GuildID == guildID && s.MediaType == mediaType && s.TMDBID == tmdbID && s.Status != database.SuggestionRejected {
+			return s.Username, nil
+		}
+	}
+	return "", nil
+}
+
+func (f *fakeStore) GetSuggestion(guildID string, suggestionID int) (*database.Suggestion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s := f.findSuggestion(guildID, suggestionID)
+	if s == nil {
+		return nil, nil
+	}
+	copied := *s
+	if selection, ok := f.selected[s.ID]; ok {
+		selectedAt := selection.selectedAt
+		copied.SelectedAt = &selectedAt
+	}
+	return &copied, nil
+}
+
+func (f *fakeStore) SetSuggestionMessage(suggestionID int, channelID, messageID, postHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if s := f.findSuggestion("", suggestionID); s != nil {
+		s.ChannelID, s.MessageID, s.PostHash = channelID, messageID, postHash
+	}
+	return nil
+}
+
+func (f *fakeStore) SetSuggestionPostHash(suggestionID int, postHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if s := f.findSuggestion("", suggestionID); s != nil {
+		s.PostHash = postHash
+	}
+	return nil
+}
+
+func (f *fakeStore) GetPostedSuggestions() ([]database.Suggestion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []database.Suggestion
+	for _, s := range f.suggestions {
+		if s.MessageID != "" {
+			result = append(result, *s)
+		}
+	}
+	return result, nil
+}
+
+// listSuggestions devolve cópias preenchidas com seleção e votos, das mais recentes para as mais antigas
+func (f *fakeStore) listSuggestions(keep func(s *database.Suggestion) bool) []database.Suggestion {
+	var result []database.Suggestion
+	for idx := len(f.suggestions) - 1; idx >= 0; idx-- {
+		s := f.suggestions[idx]
+		if !keep(s) {
+			continue
+		}
+		copied := *s
+		_, copied.IsSelected = f.selected[s.ID]
+		copied.Upvotes, copied.Downvotes = f.voteTotals(s.ID)
+		result = append(result, copied)
+	}
+	return result
+}
+
+func (f *fakeStore) GetUserSuggestions(guildID, userID string) ([]database.Suggestion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.listSuggestions(func(s *database.Suggestion) bool {
+		return s.GuildID == guildID && s.UserID == userID
+	}), nil
+}
+
+func (f *fakeStore) GetAllSuggestions(guildID, status string) ([]database.Suggestion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.listSuggestions(func(s *database.Suggestion) bool {
+		return s.GuildID == guildID && (status == "" || s.Status == status)
+	}), nil
+}
+
+func (f *fakeStore) GetAllSuggestionsCount(guildID string) (int, error) {
+	suggestions, _ := f.GetAllSuggestions(guildID, database.SuggestionApproved)
+	return len(suggestions), nil
+}
+
+func (f *fakeStore) GetPendingSuggestionsCount(guildID string) (int, error) {
+	suggestions, _ := f.GetAllSuggestions(guildID, database.SuggestionPending)
+	return len(suggestions), nil
+}
+
+func (f *fakeStore) SetSuggestionStatus(guildID string, suggestionID int, from, to, moderatorID, reason string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s := f.findSuggestion(guildID, suggestionID)
+	if s == nil || s.Status != from {
+		return false, nil
+	}
+	s.Status = to
+	s.RejectionReason = reason
+	return true, nil
+}
+
+func (f *fakeStore) GetUserStats(guildID, userID string) (int, float64, error) {
+	suggestions, _ := f.GetUserSuggestions(guildID, userID)
+	if len(suggestions) == 0 {
+		return 0, 0, nil
+	}
+
+	var total float64
+	for _, s := range suggestions {
+		total += s.Rating
+	}
+	return len(suggestions), total / float64(len(suggestions)), nil
+}
+
+func (f *fakeStore) GetUserSuggestionActivity(guildID, userID string) (int, time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	open, last := f.activity(guildID, userID)
+	return open, last, nil
+}
+
+func (f *fakeStore) activity(guildID, userID string) (int, time.Time) {
+	var open int
+	last := f.cooldowns[[2]string{guildID, userID}]
+	for _, s := range f.suggestions {
+		if s.GuildID != guildID || s.UserID != userID {
+			continue
+		}
+		if _, selected := f.selected[s.ID]; !selected && s.Status != database.SuggestionRejected {
+			open++
+		}
+		if s.SuggestedAt.After(last) {
+			last = s.SuggestedAt
+		}
+	}
+	return open, last
+}
+
+func (f *fakeStore) searchByPrefix(keep func(s *database.Suggestion) bool, prefix string, limit int) []database.Suggestion {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	prefix = strings.ToLower(prefix)
+	suggestions := f.listSuggestions(func(s *database.Suggestion) bool {
+		return keep(s) && strings.HasPrefix(strings.ToLower(s.MovieName), prefix)
+	})
+	sort.Slice(suggestions, func(a, b int) bool {
+		return strings.ToLower(suggestions[a].MovieName) < strings.ToLower(suggestions[b].MovieName)
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+func (f *fakeStore) SearchSuggestionsByPrefix(guildID, prefix string, limit int) ([]database.Suggestion, error) {
+	return f.searchByPrefix(func(s *database.Suggestion) bool {
+		return s.GuildID == guildID && s.Status == database.SuggestionApproved
+	}, prefix, limit), nil
+}
+
+func (f *fakeStore) SearchUserSuggestionsByPrefix(guildID, userID, prefix string, limit int) ([]database.Suggestion, error) {
+	return f.searchByPrefix(func(s *database.Suggestion) bool {
+		return s.GuildID == guildID && s.UserID == userID
+	}, prefix, limit), nil
+}
+
+func (f *fakeStore) GetSuggestionsForMetadataRefresh(guildID string, all bool) ([]database.Suggestion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []database.Suggestion
+	for _, s := range f.suggestions {
+		if _, done := f.refreshed[s.ID]; s.GuildID == guildID && (all || !done) {
+			result = append(result, *s)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeStore) UpdateSuggestionMetadata(s *database.Suggestion) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	existing := f.findSuggestion("", s.ID)
+	if existing == nil {
+		return nil
+	}
+	existing.MovieName = s.MovieName
+	existing.Rating = s.Rating
+	existing.Genres = s.Genres
+	existing.ReleaseYear = s.ReleaseYear
+	existing.GenreIDs = append([]int(nil), s.GenreIDs...)
+	existing.MovieMetadata = s.MovieMetadata
+	f.refreshed[s.ID] = time.Now()
+	return nil
+}
+
+func (f *fakeStore) SaveVote(guildID string, suggestionID int, userID string, vote int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.votes[suggestionID] == nil {
+		f.votes[suggestionID] = map[string]int{}
+	}
+	if f.votes[suggestionID][userID] == vote {
+		delete(f.votes[suggestionID], userID)
+		return nil
+	}
+	f.votes[suggestionID][userID] = vote
+	return nil
+}
+
+func (f *fakeStore) voteTotals(suggestionID int) (upvotes, downvotes int) {
+	for _, vote := range f.votes[suggestionID] {
+		if vote > 0 {
+			upvotes++
+		} else {
+			downvotes++
+		}
+	}
+	return upvotes, downvotes
+}
+
+func (f *fakeStore) GetVoteTotals(suggestionID int) (int, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	upvotes, downvotes := f.voteTotals(suggestionID)
+	return upvotes, downvotes, nil
+}
+
+func matchesFilter(s *database.Suggestion, filter database.MovieFilter) bool {
+	if filter.GenreID > 0 {
+		found := false
+		for _, id := range s.GenreIDs {
+			if id == filter.GenreID {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	year, err := strconv.Atoi(s.ReleaseYear)
+	if (filter.MinYear > 0 || filter.MaxYear > 0) && err != nil {
+		return false
+	}
+	if filter.MinYear > 0 && year < filter.MinYear {
+		return false
+	}
+	if filter.MaxYear > 0 && year > filter.MaxYear {
+		return false
+	}
+	if filter.MaxRuntime > 0 && (s.Runtime <= 0 || s.Runtime > filter.MaxRuntime) {
+		return false
+	}
+	if filter.MinTMDBRating > 0 && s.Rating < filter.MinTMDBRating {
+		return false
+	}
+	if filter.ExcludeUserID != "" && s.UserID == filter.ExcludeUserID {
+		return false
+	}
+	if filter.ExcludeSuggestionID > 0 && s.ID == filter.ExcludeSuggestionID {
+		return false
+	}
+	return true
+}
+
+// banned indica se a sugestão foi vetada com banimento na temporada atual
+func (f *fakeStore) banned(s *database.Suggestion) bool {
+	for _, v := range f.vetoes {
+		if v.suggestionID == s.ID && v.banned && v.season == f.seasons[s.GuildID]+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeStore) pickable(guildID string, filter database.MovieFilter) []*database.Suggestion {
+	var result []*database.Suggestion
+	for _, s := range f.suggestions {
+		if _, selected := f.selected[s.ID]; s.GuildID == guildID && s.Status == database.SuggestionApproved && !selected && !f.banned(s) && matchesFilter(s, filter) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func movieResult(s *database.Suggestion) *database.MovieResult {
+	return &database.MovieResult{
+		ID:            s.ID,
+		GuildID:       s.GuildID,
+		MovieName:     s.MovieName,
+		Username:      s.Username,
+		Rating:        s.Rating,
+		Genres:        s.Genres,
+		ReleaseYear:   s.ReleaseYear,
+		TMDBID:        s.TMDBID,
+		MovieMetadata: s.MovieMetadata,
+	}
+}
+
+func (f *fakeStore) GetRandomMovie(guildID string, filter database.MovieFilter) (*database.MovieResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	candidates := f.pickable(guildID, filter)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	return movieResult(candidates[rand.Intn(len(candidates))]), nil
+}
+
+func (f *fakeStore) GetVoteWeightedRandomMovie(guildID string, filter database.MovieFilter) (*database.MovieResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	candidates := f.pickable(guildID, filter)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	// Nos testes o mais votado sempre vence, para que o resultado seja determinístico
+	best := candidates[0]
+	bestNet := -1 << 31
+	for _, s := range candidates {
+		upvotes, downvotes := f.voteTotals(s.ID)
+		if net := upvotes - downvotes; net > bestNet {
+			best, bestNet = s, net
+		}
+	}
+	return movieResult(best), nil
+}
+
+func (f *fakeStore) GetRoundRobinRandomMovie(guildID string, filter database.MovieFilter) (*database.MovieResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	candidates := f.pickable(guildID, filter)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	// Último filme escolhido de cada pessoa; quem nunca foi escolhido fica com o tempo zero e vem primeiro
+	lastPicked := map[string]time.Time{}
+	for _, s := range f.suggestions {
+		if sel, ok := f.selected[s.ID]; ok && s.GuildID == guildID && sel.selectedAt.After(lastPicked[s.UserID]) {
+			lastPicked[s.UserID] = sel.selectedAt
+		}
+	}
+
+	// Nos testes o desempate é pelo ID de quem sugeriu, para que o resultado seja determinístico
+	userID := ""
+	for _, s := range candidates {
+		if userID == "" || lastPicked[s.UserID].Before(lastPicked[userID]) ||
+			(lastPicked[s.UserID].Equal(lastPicked[userID]) && s.UserID < userID) {
+			userID = s.UserID
+		}
+	}
+
+	var films []*database.Suggestion
+	for _, s := range candidates {
+		if s.UserID == userID {
+			films = append(films, s)
+		}
+	}
+	return movieResult(films[rand.Intn(len(films))]), nil
+}
+
+func (f *fakeStore) GetWaitWeightedRandomMovie(guildID string, filter database.MovieFilter) (*database.MovieResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	candidates := f.pickable(guildID, filter)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	// Nos testes a sugestão mais antiga sempre vence
+	oldest := candidates[0]
+	for _, s := range candidates {
+		if s.SuggestedAt.Before(oldest.SuggestedAt) {
+			oldest = s
+		}
+	}
+	return movieResult(oldest), nil
+}
+
+func (f *fakeStore) GetMovieByID(suggestionID int) (*database.MovieResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s := f.findSuggestion("", suggestionID)
+	if s == nil {
+		return nil, nil
+	}
+	return movieResult(s), nil
+}
+
+func (f *fakeStore) MarkMovieSelected(guildID string, suggestionID int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.selected[suggestionID]; ok {
+		return errors.New("UNIQUE constraint failed: selected_movies.guild_id, selected_movies.suggestion_id")
+	}
+	f.selected[suggestionID] = &fakeSelection{selectedAt: time.Now()}
+	return nil
+}
+
+func (f *fakeStore) GetSelectedMovie(guildID string, suggestionID int) (*database.MovieResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s := f.findSuggestion(guildID, suggestionID)
+	if _, selected := f.selected[suggestionID]; s == nil || !selected {
+		return nil, nil
+	}
+	return movieResult(s), nil
+}
+
+func (f *fakeStore) GetSelectedMoviesCount(guildID string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	count := 0
+	for id := range f.selected {
+		if f.findSuggestion(guildID, id) != nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeStore) GetAllSelectedMovies(guildID string) ([]database.SelectedMovieWithReviews, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var movies []database.SelectedMovieWithReviews
+	for id, selection := range f.selected {
+		s := f.findSuggestion(guildID, id)
+		if s == nil {
+			continue
+		}
+
+		m := database.SelectedMovieWithReviews{
+			MovieResult:  *movieResult(s),
+			SelectedAt:   selection.selectedAt,
+			EventID:      selection.eventID,
+			ScheduledFor: selection.scheduledFor,
+		}
+		for _, r := range f.reviews {
+			if r.GuildID == guildID && r.SuggestionID == id {
+				m.Reviews = append(m.Reviews, *r)
+				m.AverageScore += r.Rating
+			}
+		}
+		m.ReviewCount = len(m.Reviews)
+		if m.ReviewCount > 0 {
+			m.AverageScore /= float64(m.ReviewCount)
+		}
+		movies = append(movies, m)
+	}
+
+	sort.Slice(movies, func(a, b int) bool {
+		return movies[a].SelectedAt.After(movies[b].SelectedAt)
+	})
+	return movies, nil
+}
+
+func (f *fakeStore) SearchSelectedMoviesByPrefix(guildID, prefix string, limit int) ([]database.MovieResult, error) {
+	selected, _ := f.GetAllSelectedMovies(guildID)
+
+	var movies []database.MovieResult
+	for _, m := range selected {
+		if strings.HasPrefix(strings.ToLower(m.MovieName), strings.ToLower(prefix)) && len(movies) < limit {
+			movies = append(movies, m.MovieResult)
+		}
+	}
+	return movies, nil
+}
+
+func (f *fakeStore) UnselectMovie(guildID string, suggestionID int, archiveReviews bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if archiveReviews {
+		f.reviews = filterReviews(f.reviews, func(r *database.MovieReview) bool {
+			return r.GuildID != guildID || r.SuggestionID != suggestionID
+		})
+	}
+	delete(f.selected, suggestionID)
+	return nil
+}
+
+func (f *fakeStore) ResetSelections(guildID string) (int, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	archived := 0
+	for id := range f.selected {
+		if f.findSuggestion(guildID, id) != nil {
+			f.archived[guildID] = append(f.archived[guildID], id)
+			delete(f.selected, id)
+			archived++
+		}
+	}
+	if archived == 0 {
+		return 0, 0, nil
+	}
+
+	f.seasons[guildID]++
+	f.reviews = filterReviews(f.reviews, func(r *database.MovieReview) bool { return r.GuildID != guildID })
+	return f.seasons[guildID], archived, nil
+}
+
+func (f *fakeStore) GetCurrentSeason(guildID string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.seasons[guildID] + 1, nil
+}
+
+func (f *fakeStore) SetScreening(guildID string, suggestionID int, eventID string, scheduledFor time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if selection, ok := f.selected[suggestionID]; ok {
+		selection.eventID = eventID
+		selection.scheduledFor = &scheduledFor
+	}
+	return nil
+}
+
+func (f *fakeStore) ClearScreening(guildID string, suggestionID int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if selection, ok := f.selected[suggestionID]; ok {
+		selection.eventID = ""
+		selection.scheduledFor = nil
+	}
+	return nil
+}
+
+func (f *fakeStore) GetScreening(guildID string, suggestionID int) (*database.Screening, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	selection, ok := f.selected[suggestionID]
+	if !ok {
+		return nil, nil
+	}
+	return &database.Screening{EventID: selection.eventID, ScheduledFor: selection.scheduledFor, ThreadID: selection.threadID}, nil
+}
+
+func (f *fakeStore) SetDiscussionThread(guildID string, suggestionID int, threadID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if selection, ok := f.selected[suggestionID]; ok {
+		selection.threadID = threadID
+	}
+	return nil
+}
+
+func (f *fakeStore) SetBlindRatings(guildID string, suggestionID int, revealAt *time.Time, revealAfter int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if selection, ok := f.selected[suggestionID]; ok {
+		selection.blind = &database.BlindRating{GuildID: guildID, SuggestionID: suggestionID, RevealAt: revealAt, RevealAfter: revealAfter}
+	}
+	return nil
+}
+
+func (f *fakeStore) GetBlindRating(guildID string, suggestionID int) (*database.BlindRating, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	selection, ok := f.selected[suggestionID]
+	if !ok || selection.blind == nil {
+		return nil, nil
+	}
+	copied := *selection.blind
+	return &copied, nil
+}
+
+func (f *fakeStore) RevealRatings(guildID string, suggestionID int) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	selection, ok := f.selected[suggestionID]
+	if !ok || selection.blind == nil || selection.blind.RevealedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	selection.blind.RevealedAt = &now
+	return true, nil
+}
+
+func (f *fakeStore) GetPendingReveals() ([]database.BlindRating, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var reveals []database.BlindRating
+	for _, selection := range f.selected {
+		if blind := selection.blind; blind != nil && blind.RevealedAt == nil && blind.RevealAt != nil {
+			reveals = append(reveals, *blind)
+		}
+	}
+	return reveals, nil
+}
+
+func (f *fakeStore) SaveMovieReview(review *database.MovieReview) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, r := range f.reviews {
+		if r.GuildID == review.GuildID && r.SuggestionID == review.SuggestionID && r.UserID == review.UserID {
+			r.Rating = review.Rating
+			r.ReviewText = review.ReviewText
+			r.Spoiler = review.Spoiler
+			r.ReviewedAt = time.Now()
+			return nil
+		}
+	}
+
+	f.nextID++
+	saved := *review
+	saved.ID = f.nextID
+	saved.ReviewedAt = time.Now()
+	f.reviews = append(f.reviews, &saved)
+	return nil
+}
+
+func (f *fakeStore) GetMovieReviews(guildID string, suggestionID int) ([]database.MovieReview, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var reviews []database.MovieReview
+	for _, r := range f.reviews {
+		if r.GuildID == guildID && r.SuggestionID == suggestionID {
+			reviews = append(reviews, *r)
+		}
+	}
+	return reviews, nil
+}
+
+func (f *fakeStore) GetUserReview(guildID string, suggestionID int, userID string) (*database.MovieReview, error) {
+	reviews, _ := f.GetMovieReviews(guildID, suggestionID)
+	for _, r := range reviews {
+		if r.UserID == userID {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeStore) GetAverageMovieRating(guildID string, suggestionID int) (float64, int, error) {
+	reviews, _ := f.GetMovieReviews(guildID, suggestionID)
+	if len(reviews) == 0 {
+		return 0, 0, nil
+	}
+
+	var total float64
+	for _, r := range reviews {
+		total += r.Rating
+	}
+	return total / float64(len(reviews)), len(reviews), nil
+}
+
+func filterReviews(reviews []*database.MovieReview, keep func(r *database.MovieReview) bool) []*database.MovieReview {
+	var result []*database.MovieReview
+	for _, r := range reviews {
+		if keep(r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func (f *fakeStore) GetRandomMovies(guildID string, filter database.MovieFilter, limit int) ([]database.MovieResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	candidates := f.pickable(guildID, filter)
+	rand.Shuffle(len(candidates), func(a, b int) { candidates[a], candidates[b] = candidates[b], candidates[a] })
+
+	var movies []database.MovieResult
+	for _, s := range candidates {
+		if len(movies) == limit {
+			break
+		}
+		movies = append(movies, *movieResult(s))
+	}
+	return movies, nil
+}
+
+func (f *fakeStore) CreatePoll(poll *database.MoviePoll) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	saved := &fakePoll{poll: *poll, ballots: map[string]map[int]int{}}
+	saved.poll.ID = f.nextID
+	saved.poll.CreatedAt = time.Now()
+	saved.poll.Candidates = nil
+	for _, candidate := range poll.Candidates {
+		saved.candidates = append(saved.candidates, candidate.ID)
+	}
+	f.polls[saved.poll.ID] = saved
+	return int64(saved.poll.ID), nil
+}
+
+func (f *fakeStore) SetPollMessage(pollID int, messageID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if p, ok := f.polls[pollID]; ok {
+		p.poll.MessageID = messageID
+	}
+	return nil
+}
+
+func (f *fakeStore) loadPoll(p *fakePoll) database.MoviePoll {
+	poll := p.poll
+	for _, id := range p.candidates {
+		if s := f.findSuggestion("", id); s != nil {
+			poll.Candidates = append(poll.Candidates, *movieResult(s))
+		}
+	}
+	return poll
+}
+
+func (f *fakeStore) GetPoll(guildID string, pollID int) (*database.MoviePoll, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.polls[pollID]
+	if !ok || p.poll.GuildID != guildID {
+		return nil, nil
+	}
+	poll := f.loadPoll(p)
+	return &poll, nil
+}
+
+func (f *fakeStore) GetOpenPolls() ([]database.MoviePoll, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var polls []database.MoviePoll
+	for _, p := range f.polls {
+		if p.poll.IsOpen() {
+			polls = append(polls, f.loadPoll(p))
+		}
+	}
+	sort.Slice(polls, func(a, b int) bool { return polls[a].ClosesAt.Before(polls[b].ClosesAt) })
+	return polls, nil
+}
+
+func (f *fakeStore) SaveBallotChoice(pollID int, userID string, rank, suggestionID int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.polls[pollID]
+	if !ok {
+		return errors.New("FOREIGN KEY constraint failed")
+	}
+	ballot := p.ballots[userID]
+	if ballot == nil {
+		ballot = map[int]int{}
+		p.ballots[userID] = ballot
+	}
+	for r, id := range ballot {
+		if id == suggestionID {
+			delete(ballot, r)
+		}
+	}
+	ballot[rank] = suggestionID
+	return nil
+}
+
+func (f *fakeStore) ClearBallot(pollID int, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if p, ok := f.polls[pollID]; ok {
+		delete(p.ballots, userID)
+	}
+	return nil
+}
+
+func orderedBallot(ballot map[int]int) []int {
+	var ranks []int
+	for rank := range ballot {
+		ranks = append(ranks, rank)
+	}
+	sort.Ints(ranks)
+
+	var result []int
+	for _, rank := range ranks {
+		result = append(result, ballot[rank])
+	}
+	return result
+}
+
+func (f *fakeStore) GetBallot(pollID int, userID string) ([]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.polls[pollID]
+	if !ok {
+		return nil, nil
+	}
+	return orderedBallot(p.ballots[userID]), nil
+}
+
+func (f *fakeStore) GetPollBallots(pollID int) ([][]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.polls[pollID]
+	if !ok {
+		return nil, nil
+	}
+
+	var users []string
+	for userID, ballot := range p.ballots {
+		if len(ballot) > 0 {
+			users = append(users, userID)
+		}
+	}
+	sort.Strings(users)
+
+	var ballots [][]int
+	for _, userID := range users {
+		ballots = append(ballots, orderedBallot(p.ballots[userID]))
+	}
+	return ballots, nil
+}
+
+func (f *fakeStore) ClosePoll(pollID, winnerID int) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.polls[pollID]
+	if !ok || !p.poll.IsOpen() {
+		return false, nil
+	}
+	now := time.Now()
+	p.poll.ClosedAt = &now
+	p.poll.WinnerID = winnerID
+	return true, nil
+}
+
+func (f *fakeStore) countVetoes(guildID, userID string) int {
+	used := 0
+	for _, v := range f.vetoes {
+		if v.guildID == guildID && v.userID == userID && v.season == f.seasons[guildID]+1 {
+			used++
+		}
+	}
+	return used
+}
+
+func (f *fakeStore) SaveVeto(guildID string, suggestionID int, userID, username string, banned bool, allowance int, filter database.MovieFilter) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	used := f.countVetoes(guildID, userID)
+	if used >= allowance {
+		return 0, database.ErrNoVetoesLeft
+	}
+	filter.ExcludeSuggestionID = suggestionID
+	if len(f.pickable(guildID, filter)) == 0 {
+		return 0, database.ErrNoReplacement
+	}
+	f.vetoes = append(f.vetoes, fakeVeto{guildID: guildID, suggestionID: suggestionID, userID: userID, season: f.seasons[guildID] + 1, banned: banned})
+	return allowance - used - 1, nil
+}
+
+func (f *fakeStore) GetUserVetoCount(guildID, userID string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.countVetoes(guildID, userID), nil
+}
+
+// sentMessage é uma mensagem enviada a um canal pelo fakeResponder
+type sentMessage struct {
+	channelID string
+	data      *discordgo.MessageSend
+}
+
+// fakeThread é um tópico aberto pelo fakeResponder; starter é a primeira mensagem dos posts de fórum
+type fakeThread struct {
+	id        string
+	parentID  string
+	messageID string
+	name      string
+	starter   *discordgo.MessageSend
+}
+
+// fakeResponder registra tudo o que os handlers mandariam ao Discord
+type fakeResponder struct {
+	responses []*discordgo.InteractionResponse
+	edits     []*discordgo.WebhookEdit
+	// followups são as mensagens extras enviadas depois da resposta à interação
+	followups []*discordgo.WebhookParams
+	sent      []sentMessage
+	// messageEdits são as edições feitas em mensagens de canal, fora do fluxo das interações
+	messageEdits []*discordgo.MessageEdit
+	deleted      []string
+	events       map[string]*discordgo.GuildScheduledEventParams
+	// threads são os tópicos abertos pelo bot, em mensagens ou em fóruns
+	threads []fakeThread
+
+	channels    map[string]*discordgo.Channel
+	permissions map[string]int64
+	sendErr     error
+	deleteErr   error
+	threadErr   error
+	// missing são as mensagens apagadas por alguém direto no Discord
+	missing     map[string]bool
+	nextEventID int
+	// dmClosed simula membros que não aceitam mensagens diretas do bot
+	dmClosed bool
+}
+
+func newFakeResponder() *fakeResponder {
+	return &fakeResponder{
+		events:      map[string]*discordgo.GuildScheduledEventParams{},
+		channels:    map[string]*discordgo.Channel{},
+		permissions: map[string]int64{},
+		missing:     map[string]bool{},
+	}
+}
+
+var _ Responder = (*fakeResponder)(nil)
+
+func (r *fakeResponder) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	r.responses = append(r.responses, resp)
+	return nil
+}
+
+func (r *fakeResponder) InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	r.edits = append(r.edits, newresp)
+	return &discordgo.Message{ID: fmt.Sprintf("response-%d", len(r.edits)), ChannelID: interaction.ChannelID}, nil
+}
+
+func (r *fakeResponder) FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	r.followups = append(r.followups, data)
+	return &discordgo.Message{ID: fmt.Sprintf("followup-%d", len(r.followups)), ChannelID: interaction.ChannelID}, nil
+}
+
+func (r *fakeResponder) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return r.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}})
+}
+
+func (r *fakeResponder) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if r.sendErr != nil {
+		return nil, r.sendErr
+	}
+	r.sent = append(r.sent, sentMessage{channelID: channelID, data: data})
+	return &discordgo.Message{ID: fmt.Sprintf("message-%d", len(r.sent)), ChannelID: channelID}, nil
+}
+
+func (r *fakeResponder) ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if r.missing[m.ID] {
+		return nil, unknownMessage()
+	}
+	r.messageEdits = append(r.messageEdits, m)
+	return &discordgo.Message{ID: m.ID, ChannelID: m.Channel}, nil
+}
+
+func (r *fakeResponder) ChannelMessageDelete(channelID, messageID string, options ...discordgo.RequestOption) error {
+	if r.missing[messageID] {
+		return unknownMessage()
+	}
+	if r.deleteErr != nil {
+		return r.deleteErr
+	}
+	r.deleted = append(r.deleted, messageID)
+	return nil
+}
+
+// unknownMessage é o erro que o Discord devolve para mensagens que não existem mais
+func unknownMessage() error {
+	return &discordgo.RESTError{
+		Response: &http.Response{StatusCode: http.StatusNotFound},
+		Message:  &discordgo.APIErrorMessage{Code: discordgo.ErrCodeUnknownMessage, Message: "Unknown Message"},
+	}
+}
+
+func (r *fakeResponder) MessageThreadStartComplex(channelID, messageID string, data *discordgo.ThreadStart, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return r.startThread(fakeThread{parentID: channelID, messageID: messageID, name: data.Name})
+}
+
+func (r *fakeResponder) ForumThreadStartComplex(channelID string, threadData *discordgo.ThreadStart, messageData *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return r.startThread(fakeThread{parentID: channelID, name: threadData.Name, starter: messageData})
+}
+
+func (r *fakeResponder) startThread(thread fakeThread) (*discordgo.Channel, error) {
+	if r.threadErr != nil {
+		return nil, r.threadErr
+	}
+	thread.id = fmt.Sprintf("thread-%d", len(r.threads)+1)
+	r.threads = append(r.threads, thread)
+	return &discordgo.Channel{ID: thread.id, ParentID: thread.parentID, Type: discordgo.ChannelTypeGuildPublicThread}, nil
+}
+
+func (r *fakeResponder) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	if r.dmClosed {
+		return nil, errors.New("HTTP 403 Forbidden, Cannot send messages to this user")
+	}
+	return &discordgo.Channel{ID: "dm-" + recipientID, Type: discordgo.ChannelTypeDM}, nil
+}
+
+func (r *fakeResponder) Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	channel, ok := r.channels[channelID]
+	if !ok {
+		return nil, errors.New("HTTP 404 Not Found")
+	}
+	return channel, nil
+}
+
+func (r *fakeResponder) Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error) {
+	return &discordgo.Guild{ID: guildID, Name: "Test Server"}, nil
+}
+
+func (r *fakeResponder) UserChannelPermissions(userID, channelID string, fetchOptions ...discordgo.RequestOption) (int64, error) {
+	return r.permissions[userID], nil
+}
+
+func (r *fakeResponder) GuildScheduledEventCreate(guildID string, event *discordgo.GuildScheduledEventParams, options ...discordgo.RequestOption) (*discordgo.GuildScheduledEvent, error) {
+	r.nextEventID++
+	id := fmt.Sprintf("event-%d", r.nextEventID)
+	r.events[id] = event
+	return &discordgo.GuildScheduledEvent{ID: id, GuildID: guildID, Name: event.Name}, nil
+}
+
+func (r *fakeResponder) GuildScheduledEventEdit(guildID, eventID string, event *discordgo.GuildScheduledEventParams, options ...discordgo.RequestOption) (*discordgo.GuildScheduledEvent, error) {
+	existing, ok := r.events[eventID]
+	if !ok {
+		return nil, errors.New("HTTP 404 Not Found")
+	}
+	if event.ScheduledStartTime != nil {
+		existing.ScheduledStartTime = event.ScheduledStartTime
+	}
+	if event.Status != 0 {
+		existing.Status = event.Status
+	}
+	return &discordgo.GuildScheduledEvent{ID: eventID, GuildID: guildID, Status: existing.Status}, nil
+}
+
+// lastMessage devolve o texto visível mais recente: a última edição ou, sem edições, a última resposta
+func (r *fakeResponder) lastMessage() string {
+	if len(r.edits) > 0 {
+		edit := r.edits[len(r.edits)-1]
+		var parts []string
+		if edit.Content != nil {
+			parts = append(parts, *edit.Content)
+		}
+		if edit.Embeds != nil {
+			parts = append(parts, embedText(*edit.Embeds)...)
+		}
+		return strings.Join(parts, "\n")
+	}
+	if len(r.responses) > 0 {
+		resp := r.responses[len(r.responses)-1]
+		if resp.Data == nil {
+			return ""
+		}
+		parts := []string{resp.Data.Content, resp.Data.Title}
+		parts = append(parts, embedText(resp.Data.Embeds)...)
+		for _, choice := range resp.Data.Choices {
+			parts = append(parts, choice.Name)
+		}
+		return strings.Join(parts, "\n")
+	}
+	return ""
+}
+
+func embedText(embeds []*discordgo.MessageEmbed) []string {
+	var parts []string
+	for _, embed := range embeds {
+		parts = append(parts, embed.Title, embed.Description)
+		for _, field := range embed.Fields {
+			parts = append(parts, field.Name, field.Value)
+		}
+		if embed.Footer != nil {
+			parts = append(parts, embed.Footer.Text)
+		}
+	}
+	return parts
+}
+
+// customIDs lista os custom IDs dos componentes da última resposta ou edição
+func (r *fakeResponder) customIDs() []string {
+	var components []discordgo.MessageComponent
+	if len(r.edits) > 0 && r.edits[len(r.edits)-1].Components != nil {
+		components = *r.edits[len(r.edits)-1].Components
+	} else if len(r.responses) > 0 && r.responses[len(r.responses)-1].Data != nil {
+		components = r.responses[len(r.responses)-1].Data.Components
+	}
+
+	var ids []string
+	for _, component := range components {
+		row, ok := component.(discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, inner := range row.Components {
+			switch c := inner.(type) {
+			case discordgo.Button:
+				ids = append(ids, c.CustomID)
+			case discordgo.SelectMenu:
+				ids = append(ids, c.CustomID)
+			}
+		}
+	}
+	return ids
+}
+
+// rateLimitedTMDBID é o ID que o servidor falso do TMDB sempre responde com 429
+const rateLimitedTMDBID = 429
+
+// Traduções servidas pelo newFakeTMDB quando language=pt-BR
+var (
+	ptBRTitles = map[int]string{348: "Alien, o Oitavo Passageiro", 949: "Fogo contra Fogo"}
+	ptBRGenres = []tmdb.Genre{{ID: 27, Name: "Terror"}, {ID: 28, Name: "Ação"}, {ID: 80, Name: "Crime"}, {ID: 878, Name: "Ficção científica"}}
+)
+
+// imdbIDs são os IDs do IMDb que o /find do newFakeTMDB conhece; tvIMDbIDs, os das séries
+var (
+	imdbIDs   = map[int]string{348: "tt0078748", 949: "tt0113277"}
+	tvIMDbIDs = map[int]string{87108: "tt7366338"}
+)
+
+// localizeMovie devolve o filme como o TMDB o descreveria no idioma pedido, com o título original preservado
+func localizeMovie(movie tmdb.Movie, language string) tmdb.Movie {
+	movie.OriginalTitle = movie.Title
+	if title, ok := ptBRTitles[movie.ID]; ok && language == "pt-BR" {
+		movie.Title = title
+	}
+	return movie
+}
+
+// newFakeTMDB sobe um servidor local com as rotas do TMDB usadas pelo bot e devolve um cliente apontado para ele.
+// As séries são sempre as de testShows.
+func newFakeTMDB(t *testing.T, movies ...tmdb.Movie) *tmdb.Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/movie", func(w http.ResponseWriter, r *http.Request) {
+		query := strings.ToLower(r.URL.Query().Get("query"))
+		language := r.URL.Query().Get("language")
+		results := []tmdb.Movie{}
+		for _, movie := range movies {
+			localized := localizeMovie(movie, language)
+			if strings.Contains(strings.ToLower(movie.Title), query) || strings.Contains(strings.ToLower(localized.Title), query) {
+				results = append(results, localized)
+			}
+		}
+		json.NewEncoder(w).Encode(tmdb.SearchResponse{Results: results})
+	})
+	mux.HandleFunc("/movie/", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/movie/"))
+		if id == rateLimitedTMDBID {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		for _, movie := range movies {
+			if movie.ID == id {
+				json.NewEncoder(w).Encode(localizeMovie(movie, r.URL.Query().Get("language")))
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/find/", func(w http.ResponseWriter, r *http.Request) {
+		results := []tmdb.Movie{}
+		for _, movie := range movies {
+			if imdbIDs[movie.ID] == strings.TrimPrefix(r.URL.Path, "/find/") {
+				results = append(results, localizeMovie(movie, r.URL.Query().Get("language")))
+			}
+		}
+		shows := []tmdb.TVShow{}
+		for _, show := range testShows {
+			if tvIMDbIDs[show.ID] == strings.TrimPrefix(r.URL.Path, "/find/") {
+				shows = append(shows, show)
+			}
+		}
+		json.NewEncoder(w).Encode(tmdb.FindResponse{MovieResults: results, TVResults: shows})
+	})
+	mux.HandleFunc("/search/tv", func(w http.ResponseWriter, r *http.Request) {
+		query := strings.ToLower(r.URL.Query().Get("query"))
+		results := []tmdb.TVShow{}
+		for _, show := range testShows {
+			if strings.Contains(strings.ToLower(show.Name), query) {
+				results = append(results, show)
+			}
+		}
+		json.NewEncoder(w).Encode(tmdb.TVSearchResponse{Results: results})
+	})
+	mux.HandleFunc("/tv/", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/tv/"))
+		for _, show := range testShows {
+			if show.ID == id {
+				json.NewEncoder(w).Encode(show)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/genre/movie/list", func(w http.ResponseWriter, r *http.Request) {
+		genres := tmdb.MovieGenres()
+		if r.URL.Query().Get("language") == "pt-BR" {
+			genres = ptBRGenres
+		}
+		json.NewEncoder(w).Encode(map[string][]tmdb.Genre{"genres": genres})
+	})
+	mux.HandleFunc("/genre/tv/list", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string][]tmdb.Genre{"genres": {{ID: 18, Name: "Drama"}, {ID: 10765, Name: "Sci-Fi & Fantasy"}}})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := tmdb.NewClient("test-key")
+	client.SetBaseURL(server.URL)
+	// As novas tentativas têm testes próprios no pacote tmdb; aqui o erro chega direto ao handler
+	client.SetRetries(0, 0)
+	return client
+}
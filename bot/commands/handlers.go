@@ -2,17 +2,163 @@ package commands
 
 import (
 	"clapper/database"
+	"clapper/links"
 	"clapper/tmdb"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Store é o subconjunto do banco usado pelos handlers; *database.Database o implementa
+type Store interface {
+	GetGuildConfig(guildID string) (*database.GuildConfig, error)
+	SaveGuildConfig(guildID, channelID string) error
+	SaveEventSettings(guildID, eventChannelID, timezone string) error
+	SaveRoleSettings(guildID, hostRoleID, suggesterRoleID string) error
+	SavePickStrategy(guildID, strategy string) error
+	SaveVetoSettings(guildID string, vetoesPerSeason int, banVetoed bool) error
+	SaveSuggestionLimits(guildID string, maxOpen int, cooldown time.Duration) error
+	SaveModerationSettings(guildID string, requireApproval bool, modChannelID string) error
+	SaveDiscussionSettings(guildID, forumID string) error
+	SaveLanguage(guildID, language string) error
+
+	SaveSuggestionWithinLimits(s *database.Suggestion, maxOpen int, cooldown time.Duration) (int64, error)
+	RemoveSuggestion(suggestionID int) error
+	DiscardSuggestion(suggestionID int) error
+	MovieAlreadySuggested(guildID, mediaType string, tmdbID int) (bool, error)
+	GetMovieSuggester(guildID, mediaType string, tmdbID int) (string, error)
+	GetSuggestion(guildID string, suggestionID int) (*database.Suggestion, error)
+	GetUserSuggestions(guildID, userID string) ([]database.Suggestion, error)
+	GetAllSuggestions(guildID, status string) ([]database.Suggestion, error)
+	GetAllSuggestionsCount(guildID string) (int, error)
+	GetUserStats(guildID, userID string) (count int, avgRating float64, err error)
+	GetUserSuggestionActivity(guildID, userID string) (open int, lastSuggestedAt time.Time, err error)
+	SearchSuggestionsByPrefix(guildID, prefix string, limit int) ([]database.Suggestion, error)
+	SearchUserSuggestionsByPrefix(guildID, userID, prefix string, limit int) ([]database.Suggestion, error)
+	GetSuggestionsForMetadataRefresh(guildID string, all bool) ([]database.Suggestion, error)
+	UpdateSuggestionMetadata(s *database.Suggestion) error
+	SetSuggestionStatus(guildID string, suggestionID int, from, to, moderatorID, reason string) (bool, error)
+	GetPendingSuggestionsCount(guildID string) (int, error)
+	SetSuggestionMessage(suggestionID int, channelID, messageID, postHash string) error
+	SetSuggestionPostHash(suggestionID int, postHash string) error
+	GetPostedSuggestions() ([]database.Suggestion, error)
+
+	SaveVote(guildID string, suggestionID int, userID string, vote int) error
+	GetVoteTotals(suggestionID int) (upvotes, downvotes int, err error)
+
+	GetRandomMovie(guildID string, filter database.MovieFilter) (*database.MovieResult, error)
+	GetRandomMovies(guildID string, filter database.MovieFilter, limit int) ([]database.MovieResult, error)
+	GetVoteWeightedRandomMovie(guildID string, filter database.MovieFilter) (*database.MovieResult, error)
+	GetRoundRobinRandomMovie(guildID string, filter database.MovieFilter) (*database.MovieResult, error)
+	GetWaitWeightedRandomMovie(guildID string, filter database.MovieFilter) (*database.MovieResult, error)
+	GetMovieByID(suggestionID int) (*database.MovieResult, error)
+
+	MarkMovieSelected(guildID string, suggestionID int) error
+	GetSelectedMovie(guildID string, suggestionID int) (*database.MovieResult, error)
+	GetSelectedMoviesCount(guildID string) (int, error)
+	GetAllSelectedMovies(guildID string) ([]database.SelectedMovieWithReviews, error)
+	SearchSelectedMoviesByPrefix(guildID, prefix string, limit int) ([]database.MovieResult, error)
+	UnselectMovie(guildID string, suggestionID int, archiveReviews bool) error
+	ResetSelections(guildID string) (season int, archived int, err error)
+	GetCurrentSeason(guildID string) (int, error)
+
+	SetScreening(guildID string, suggestionID int, eventID string, scheduledFor time.Time) error
+	ClearScreening(guildID string, suggestionID int) error
+	GetScreening(guildID string, suggestionID int) (*database.Screening, error)
+	SetDiscussionThread(guildID string, suggestionID int, threadID string) error
+	SetBlindRatings(guildID string, suggestionID int, revealAt *time.Time, revealAfter int) error
+	GetBlindRating(guildID string, suggestionID int) (*database.BlindRating, error)
+	RevealRatings(guildID string, suggestionID int) (bool, error)
+	GetPendingReveals() ([]database.BlindRating, error)
+
+	CreatePoll(poll *database.MoviePoll) (int64, error)
+	SetPollMessage(pollID int, messageID string) error
+	GetPoll(guildID string, pollID int) (*database.MoviePoll, error)
+	GetOpenPolls() ([]database.MoviePoll, error)
+	SaveBallotChoice(pollID int, userID string, rank, suggestionID int) error
+	ClearBallot(pollID int, userID string) error
+	GetBallot(pollID int, userID string) ([]int, error)
+	GetPollBallots(pollID int) ([][]int, error)
+	ClosePoll(pollID, winnerID int) (bool, error)
+
+	SaveVeto(guildID string, suggestionID int, userID, username string, banned bool, allowance int, filter database.MovieFilter) (int, error)
+	GetUserVetoCount(guildID, userID string) (int, error)
+
+	SaveMovieReview(review *database.MovieReview) error
+	GetMovieReviews(guildID string, suggestionID int) ([]database.MovieReview, error)
+	GetUserReview(guildID string, suggestionID int, userID string) (*database.MovieReview, error)
+	GetAverageMovieRating(guildID string, suggestionID int) (float64, int, error)
+}
+
+// MovieProvider fornece os metadados de filmes e séries; *tmdb.Client o implementa
+type MovieProvider interface {
+	SearchMovies(ctx context.Context, movieName, language string) ([]tmdb.Movie, error)
+	GetMovieByID(ctx context.Context, movieID int, language string) (*tmdb.Movie, error)
+	FindByIMDbID(ctx context.Context, imdbID, language string) (*tmdb.Movie, error)
+	SearchTV(ctx context.Context, name, language string) ([]tmdb.Movie, error)
+	GetTVByID(ctx context.Context, tvID int, language string) (*tmdb.Movie, error)
+	GetPosterURL(posterPath string) string
+	LoadGenres(ctx context.Context, language string) error
+	FormatGenres(language string, genreIDs []int) string
+}
+
+// Responder reúne os métodos do *discordgo.Session chamados pelos handlers
+type Responder interface {
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error
+	InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageDelete(channelID, messageID string, options ...discordgo.RequestOption) error
+	MessageThreadStartComplex(channelID, messageID string, data *discordgo.ThreadStart, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	ForumThreadStartComplex(channelID string, threadData *discordgo.ThreadStart, messageData *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error)
+	UserChannelPermissions(userID, channelID string, fetchOptions ...discordgo.RequestOption) (int64, error)
+	GuildScheduledEventCreate(guildID string, event *discordgo.GuildScheduledEventParams, options ...discordgo.RequestOption) (*discordgo.GuildScheduledEvent, error)
+	GuildScheduledEventEdit(guildID, eventID string, event *discordgo.GuildScheduledEventParams, options ...discordgo.RequestOption) (*discordgo.GuildScheduledEvent, error)
+}
+
+var (
+	_ Store         = (*database.Database)(nil)
+	_ MovieProvider = (*tmdb.Client)(nil)
+	_ Responder     = (*discordgo.Session)(nil)
 )
 
 type Handlers struct {
-	db   *database.Database
-	tmdb *tmdb.Client
+	db   Store
+	tmdb MovieProvider
+	// resolver traduz os links colados no /suggestion para o ID do filme no TMDB
+	resolver links.Resolver
+
+	// refreshing marca os servidores com um /refreshmetadata em andamento
+	refreshMu  sync.Mutex
+	refreshing map[string]bool
+	// pollTimers guarda o timer de apuração de cada votação aberta
+	pollMu     sync.Mutex
+	pollTimers map[int]*time.Timer
+	// revealTimers guarda o timer de revelação de cada avaliação às cegas, por sugestão
+	revealMu     sync.Mutex
+	revealTimers map[int]*time.Timer
+	// background acompanha as tarefas disparadas pelos handlers que continuam após a resposta
+	background sync.WaitGroup
+	// stopping é fechado pelo Shutdown para as tarefas longas pararem antes de terminar
+	stopping chan struct{}
+	stopOnce sync.Once
 }
 
-func NewHandlers(db *database.Database, tmdb *tmdb.Client) *Handlers {
+func NewHandlers(db Store, tmdb MovieProvider) *Handlers {
 	return &Handlers{
-		db:   db,
-		tmdb: tmdb,
+		db:           db,
+		tmdb:         tmdb,
+		resolver:     links.NewChain(tmdb, nil),
+		refreshing:   map[string]bool{},
+		pollTimers:   map[int]*time.Timer{},
+		revealTimers: map[int]*time.Timer{},
+		stopping:     make(chan struct{}),
 	}
-}
\ No newline at end of file
+}
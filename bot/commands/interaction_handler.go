@@ -6,7 +6,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func (h *Handlers) HandleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleInteraction(s Responder, i *discordgo.InteractionCreate) {
 	switch i.Type {
 	case discordgo.InteractionApplicationCommand:
 		switch i.ApplicationCommandData().Name {
@@ -34,11 +34,31 @@ func (h *Handlers) HandleInteraction(s *discordgo.Session, i *discordgo.Interact
 			h.HandleMovieReviews(s, i)
 		case "selectedmovies":
 			h.HandleSelectedMovies(s, i)
+		case "schedule":
+			h.HandleSchedule(s, i)
+		case "unschedule":
+			h.HandleUnschedule(s, i)
+		case "unselect":
+			h.HandleUnselect(s, i)
+		case "blindratings":
+			h.HandleBlindRatings(s, i)
+		case "revealratings":
+			h.HandleRevealRatings(s, i)
+		case "resetselections":
+			h.HandleResetSelections(s, i)
+		case "refreshmetadata":
+			h.HandleRefreshMetadata(s, i)
+		case "moviepoll":
+			h.HandleMoviePoll(s, i)
 		}
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		h.HandleAutocomplete(s, i)
 	case discordgo.InteractionMessageComponent:
 		customID := i.MessageComponentData().CustomID
 		if strings.HasPrefix(customID, "reroll_movie_") {
 			h.HandleRerollMovie(s, i)
+		} else if strings.HasPrefix(customID, "veto_movie_") {
+			h.HandleVetoMovie(s, i)
 		} else if strings.HasPrefix(customID, "confirm_movie_") {
 			h.HandleConfirmMovie(s, i)
 		} else if strings.HasPrefix(customID, "mysuggestions_prev_") {
@@ -49,6 +69,33 @@ func (h *Handlers) HandleInteraction(s *discordgo.Session, i *discordgo.Interact
 			h.HandleSuggestionsPrev(s, i)
 		} else if strings.HasPrefix(customID, "suggestions_next_") {
 			h.HandleSuggestionsNext(s, i)
+		} else if strings.HasPrefix(customID, "suggestion_select_") {
+			h.HandleSuggestionSelect(s, i)
+		} else if strings.HasPrefix(customID, "vote_up_") || strings.HasPrefix(customID, "vote_down_") {
+			h.HandleSuggestionVote(s, i)
+		} else if strings.HasPrefix(customID, "reset_selections_") {
+			h.HandleResetSelectionsConfirm(s, i)
+		} else if strings.HasPrefix(customID, "poll_rank_") {
+			h.HandlePollRank(s, i)
+		} else if strings.HasPrefix(customID, "poll_clear_") {
+			h.HandlePollClear(s, i)
+		} else if strings.HasPrefix(customID, "poll_close_") {
+			h.HandlePollClose(s, i)
+		} else if strings.HasPrefix(customID, "approve_suggestion_") {
+			h.HandleApproveSuggestion(s, i)
+		} else if strings.HasPrefix(customID, "reject_suggestion_") {
+			h.HandleRejectSuggestion(s, i)
+		} else if strings.HasPrefix(customID, "rate_movie_") {
+			h.HandleRateButton(s, i)
+		}
+	case discordgo.InteractionModalSubmit:
+		customID := i.ModalSubmitData().CustomID
+		if strings.HasPrefix(customID, "confirm_modal_") {
+			h.HandleConfirmMovieSubmit(s, i)
+		} else if strings.HasPrefix(customID, "reject_modal_") {
+			h.HandleRejectSuggestionSubmit(s, i)
+		} else if strings.HasPrefix(customID, "rate_modal_") {
+			h.HandleRateModalSubmit(s, i)
 		}
 	}
 }
\ No newline at end of file
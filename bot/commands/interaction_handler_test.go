@@ -0,0 +1,284 @@
+package commands
+
+import (
+	"clapper/database"
+	"clapper/tmdb"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	testGuildID        = "guild1"
+	testChannelID      = "100"
+	testEventChannelID = "200"
+	testHostRoleID     = "300"
+	testSuggesterRole  = "400"
+	testModChannelID   = "500"
+	testForumID        = "600"
+)
+
+var (
+	testAdmin  = &discordgo.Member{User: &discordgo.User{ID: "1", Username: "admin"}}
+	testHost   = &discordgo.Member{User: &discordgo.User{ID: "2", Username: "host"}, Roles: []string{testHostRoleID}}
+	testMember = &discordgo.Member{User: &discordgo.User{ID: "3", Username: "member"}}
+	testOther  = &discordgo.Member{User: &discordgo.User{ID: "4", Username: "other"}}
+)
+
+var (
+	movieAlien    = tmdb.Movie{ID: 348, Title: "Alien", ReleaseDate: "1979-05-25", VoteAverage: 8.2, Runtime: 117, GenreIDs: []int{27, 878}, Overview: "A crew meets a creature."}
+	movieAliens   = tmdb.Movie{ID: 679, Title: "Aliens", ReleaseDate: "1986-07-18", VoteAverage: 7.9, Runtime: 137, GenreIDs: []int{28, 878}}
+	movieHeat     = tmdb.Movie{ID: 949, Title: "Heat", ReleaseDate: "1995-12-15", VoteAverage: 7.9, Runtime: 170, GenreIDs: []int{28, 80}}
+	movieParasite = tmdb.Movie{ID: 496243, Title: "Parasite", ReleaseDate: "2019-05-30", VoteAverage: 8.5, Runtime: 133, GenreIDs: []int{35, 53, 18}}
+)
+
+// testShows são as séries do newFakeTMDB. A segunda tem o mesmo ID de movieHeat, como acontece no TMDB,
+// onde filmes e séries são numerados separadamente.
+var testShows = []tmdb.TVShow{
+	{ID: 87108, Name: "Chernobyl", FirstAirDate: "2019-05-06", VoteAverage: 8.7, NumberOfSeasons: 1, NumberOfEpisodes: 5, GenreIDs: []int{18}},
+	{ID: 949, Name: "Heat Wave", FirstAirDate: "2003-09-22", VoteAverage: 6.4, NumberOfSeasons: 2, NumberOfEpisodes: 16, GenreIDs: []int{18, 10765}},
+}
+
+// testEnv reúne os fakes usados por um caso de teste
+type testEnv struct {
+	store     *fakeStore
+	responder *fakeResponder
+	handlers  *Handlers
+	ids       map[string]int
+	// lastPoll é o ID da última votação aberta por poll
+	lastPoll int
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	metadataRefreshDelay = 0
+	suggestionPostSyncDelay = 0
+
+	store := newFakeStore()
+	responder := newFakeResponder()
+	responder.permissions[testAdmin.User.ID] = discordgo.PermissionAdministrator
+	responder.channels[testChannelID] = &discordgo.Channel{ID: testChannelID, Type: discordgo.ChannelTypeGuildText}
+	responder.channels[testEventChannelID] = &discordgo.Channel{ID: testEventChannelID, Type: discordgo.ChannelTypeGuildVoice}
+	responder.channels[testModChannelID] = &discordgo.Channel{ID: testModChannelID, Type: discordgo.ChannelTypeGuildText}
+	responder.channels[testForumID] = &discordgo.Channel{ID: testForumID, Type: discordgo.ChannelTypeGuildForum}
+
+	handlers := NewHandlers(store, newFakeTMDB(t, movieAlien, movieAliens, movieHeat, movieParasite))
+	t.Cleanup(handlers.Shutdown)
+
+	return &testEnv{
+		store:     store,
+		responder: responder,
+		handlers:  handlers,
+		ids:       map[string]int{},
+	}
+}
+
+func (env *testEnv) configure() {
+	env.store.SaveGuildConfig(testGuildID, testChannelID)
+	env.store.SaveRoleSettings(testGuildID, testHostRoleID, "")
+}
+
+func (env *testEnv) suggest(movie tmdb.Movie, member *discordgo.Member) int {
+	mediaType := movie.MediaType
+	if mediaType == "" {
+		mediaType = database.MediaMovie
+	}
+	id, _ := env.store.SaveSuggestion(&database.Suggestion{
+		GuildID:       testGuildID,
+		MovieName:     movie.Title,
+		UserID:        member.User.ID,
+		Username:      member.User.Username,
+		TMDBID:        movie.ID,
+		Rating:        movie.VoteAverage,
+		Genres:        env.handlers.tmdb.FormatGenres("", movie.GenreIDs),
+		ReleaseYear:   releaseYear(movie.ReleaseDate),
+		GenreIDs:      movie.GenreIDs,
+		MovieMetadata: movieMetadata(mediaType, &movie),
+	})
+	env.ids[movie.Title] = int(id)
+	return int(id)
+}
+
+// pending semeia uma sugestão que ainda aguarda aprovação, com a fila de moderação ligada
+func (env *testEnv) pending(movie tmdb.Movie, member *discordgo.Member) int {
+	env.store.SaveModerationSettings(testGuildID, true, testModChannelID)
+	id := env.suggest(movie, member)
+	env.store.findSuggestion("", id).Status = database.SuggestionPending
+	return id
+}
+
+func (env *testEnv) selectMovie(movie tmdb.Movie, member *discordgo.Member) int {
+	id := env.suggest(movie, member)
+	env.store.MarkMovieSelected(testGuildID, id)
+	return id
+}
+
+// post simula a mensagem da sugestão no canal de sugestões e devolve o seu ID
+func (env *testEnv) post(movie tmdb.Movie) string {
+	messageID := "post-" + env.ref(movie)
+	env.store.SetSuggestionMessage(env.ids[movie.Title], testChannelID, messageID, "")
+	return messageID
+}
+
+// postEdit devolve o texto da última edição feita na mensagem, ou falha se ela não foi editada
+func (env *testEnv) postEdit(t *testing.T, messageID string) (string, *discordgo.MessageEdit) {
+	t.Helper()
+	for idx := len(env.responder.messageEdits) - 1; idx >= 0; idx-- {
+		if edit := env.responder.messageEdits[idx]; edit.ID == messageID {
+			return strings.Join(embedText(*edit.Embeds), "\n"), edit
+		}
+	}
+	t.Fatalf("message %s was not edited, edits: %+v", messageID, env.responder.messageEdits)
+	return "", nil
+}
+
+// poll abre uma votação de uma hora entre filmes já sugeridos e devolve o seu ID
+func (env *testEnv) poll(movies ...tmdb.Movie) int {
+	var candidates []database.MovieResult
+	for _, movie := range movies {
+		candidates = append(candidates, database.MovieResult{ID: env.ids[movie.Title]})
+	}
+	id, _ := env.store.CreatePoll(&database.MoviePoll{
+		GuildID:    testGuildID,
+		ChannelID:  testChannelID,
+		MessageID:  "poll-message",
+		CreatedBy:  testHost.User.ID,
+		ClosesAt:   time.Now().Add(time.Hour),
+		Candidates: candidates,
+	})
+	env.lastPoll = int(id)
+	return env.lastPoll
+}
+
+// ref devolve o ID de sugestão de um filme semeado, no formato enviado pelo autocomplete
+func (env *testEnv) ref(movie tmdb.Movie) string {
+	return fmt.Sprint(env.ids[movie.Title])
+}
+
+func slashCommand(member *discordgo.Member, name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:      discordgo.InteractionApplicationCommand,
+		GuildID:   testGuildID,
+		ChannelID: testChannelID,
+		Member:    member,
+		Data:      discordgo.ApplicationCommandInteractionData{Name: name, Options: options},
+	}}
+}
+
+func autocompleteRequest(member *discordgo.Member, name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	i := slashCommand(member, name, options...)
+	i.Type = discordgo.InteractionApplicationCommandAutocomplete
+	return i
+}
+
+func buttonPress(member *discordgo.Member, customID string, values ...string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:      discordgo.InteractionMessageComponent,
+		GuildID:   testGuildID,
+		ChannelID: testChannelID,
+		Member:    member,
+		Message:   &discordgo.Message{},
+		Data:      discordgo.MessageComponentInteractionData{CustomID: customID, Values: values},
+	}}
+}
+
+func modalSubmit(member *discordgo.Member, customID string, fields map[string]string) *discordgo.InteractionCreate {
+	var rows []discordgo.MessageComponent
+	for id, value := range fields {
+		rows = append(rows, &discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{&discordgo.TextInput{CustomID: id, Value: value}},
+		})
+	}
+
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:      discordgo.InteractionModalSubmit,
+		GuildID:   testGuildID,
+		ChannelID: testChannelID,
+		Member:    member,
+		Data:      discordgo.ModalSubmitInteractionData{CustomID: customID, Components: rows},
+	}}
+}
+
+func option(name string, optionType discordgo.ApplicationCommandOptionType, value interface{}) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: optionType, Value: value}
+}
+
+func stringOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return option(name, discordgo.ApplicationCommandOptionString, value)
+}
+
+func intOption(name string, value int) *discordgo.ApplicationCommandInteractionDataOption {
+	// Números chegam do Discord como float64 depois do JSON
+	return option(name, discordgo.ApplicationCommandOptionInteger, float64(value))
+}
+
+func boolOption(name string, value bool) *discordgo.ApplicationCommandInteractionDataOption {
+	return option(name, discordgo.ApplicationCommandOptionBoolean, value)
+}
+
+func focusedOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	opt := stringOption(name, value)
+	opt.Focused = true
+	return opt
+}
+
+func subcommand(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionSubCommand, Options: options}
+}
+
+func futureScreening() string {
+	return time.Now().UTC().AddDate(0, 0, 7).Format(screeningTimeLayout)
+}
+
+// handlerTest é um caso de teste de interação: setup prepara o ambiente, interaction monta a interação enviada
+// ao HandleInteraction, want lista trechos esperados na última resposta e check faz as demais verificações
+type handlerTest struct {
+	name        string
+	setup       func(env *testEnv)
+	interaction func(env *testEnv) *discordgo.InteractionCreate
+	want        []string
+	check       func(t *testing.T, env *testEnv)
+}
+
+// runHandlerTests roda cada caso em um ambiente novo; os arquivos de teste de cada comando montam suas tabelas
+func runHandlerTests(t *testing.T, tests []handlerTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			if tt.setup != nil {
+				tt.setup(env)
+			}
+
+			env.handlers.HandleInteraction(env.responder, tt.interaction(env))
+			env.handlers.background.Wait()
+
+			got := env.responder.lastMessage()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("response does not contain %q:\n%s", want, got)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, env)
+			}
+		})
+	}
+}
+
+func TestHandleInteraction(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "commands outside a server are refused",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				i := slashCommand(testMember, "mystats")
+				i.GuildID = ""
+				return i
+			},
+			want: []string{"can only be used in a server"},
+		},
+	})
+}
@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"clapper/database"
+	"clapper/tmdb"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// metadataRefreshDelay espaça as chamadas ao TMDB durante o /refreshmetadata para não estourar o limite da API
+var metadataRefreshDelay = 250 * time.Millisecond
+
+// metadataProgressInterval limita a frequência com que a mensagem de progresso é editada
+const metadataProgressInterval = 3 * time.Second
+
+func movieMetadata(mediaType string, movie *tmdb.Movie) database.MovieMetadata {
+	return database.MovieMetadata{
+		MediaType:        mediaType,
+		PosterPath:       movie.PosterPath,
+		BackdropPath:     movie.BackdropPath,
+		Overview:         movie.Overview,
+		Runtime:          movie.Runtime,
+		OriginalTitle:    movie.OriginalTitle,
+		OriginalLanguage: movie.OriginalLanguage,
+		IMDbID:           movie.IMDbID,
+		Seasons:          movie.NumberOfSeasons,
+		Episodes:         movie.NumberOfEpisodes,
+	}
+}
+
+func (h *Handlers) HandleRefreshMetadata(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This command can only be used in a server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if !h.authorize(s, i, permissionConfigure) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators can refresh movie metadata!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	all := false
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "all" {
+			all = opt.BoolValue()
+		}
+	}
+
+	h.refreshMu.Lock()
+	if h.refreshing[guildID] {
+		h.refreshMu.Unlock()
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("⏳ A metadata refresh is already running for this server."),
+		})
+		return
+	}
+	h.refreshing[guildID] = true
+	h.refreshMu.Unlock()
+
+	suggestions, err := h.db.GetSuggestionsForMetadataRefresh(guildID, all)
+	if err != nil || len(suggestions) == 0 {
+		h.finishMetadataRefresh(guildID)
+
+		msg := "✅ Every suggestion already has full metadata. Use `all: True` to refresh them anyway."
+		if err != nil {
+			msg = "❌ An error occurred while fetching suggestions. Please try again."
+		}
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString(msg),
+		})
+		return
+	}
+
+	// Sem configuração o TMDB responde no idioma padrão do bot
+	language := ""
+	if config, err := h.db.GetGuildConfig(guildID); err == nil && config != nil {
+		language = config.Language
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: ptrString(metadataProgress(0, len(suggestions))),
+	})
+
+	// A atualização continua em segundo plano; a resposta efêmera é editada com o progresso
+	h.background.Add(1)
+	go func() {
+		defer h.background.Done()
+		defer h.finishMetadataRefresh(guildID)
+		h.refreshMetadata(s, i, language, suggestions)
+	}()
+}
+
+func (h *Handlers) finishMetadataRefresh(guildID string) {
+	h.refreshMu.Lock()
+	delete(h.refreshing, guildID)
+	h.refreshMu.Unlock()
+}
+
+func (h *Handlers) refreshMetadata(s Responder, i *discordgo.InteractionCreate, language string, suggestions []database.Suggestion) {
+	updated, failed := 0, 0
+	lastReport := time.Now()
+
+	for idx := range suggestions {
+		if idx > 0 {
+			time.Sleep(metadataRefreshDelay)
+		}
+
+		if err := h.refreshSuggestionMetadata(&suggestions[idx], language); err != nil {
+			log.Printf("Erro ao atualizar metadados da sugestão %d (TMDB %d): %v", suggestions[idx].ID, suggestions[idx].TMDBID, err)
+			failed++
+		} else {
+			updated++
+			h.syncSuggestionPosts(s, suggestions[idx].GuildID, suggestions[idx].ID)
+		}
+
+		if time.Since(lastReport) >= metadataProgressInterval {
+			lastReport = time.Now()
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: ptrString(metadataProgress(idx+1, len(suggestions))),
+			})
+		}
+	}
+
+	msg := fmt.Sprintf("✅ Metadata refresh finished: **%d** updated, **%d** failed out of %d suggestion%s.",
+		updated, failed, len(suggestions), pluralize(len(suggestions)))
+	if failed > 0 {
+		msg += "\nFailed movies keep their old data and will be retried the next time you run `/refreshmetadata`."
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: ptrString(msg),
+	})
+}
+
+// refreshSuggestionMetadata também traduz o título para o idioma atual do servidor
+func (h *Handlers) refreshSuggestionMetadata(suggestion *database.Suggestion, language string) error {
+	ctx, cancel := tmdbContext()
+	defer cancel()
+
+	movie, err := h.getTitle(ctx, suggestion.MediaType, suggestion.TMDBID, language)
+	if err != nil {
+		return err
+	}
+
+	if movie.Title != "" {
+		suggestion.MovieName = movie.Title
+	}
+	suggestion.Rating = movie.VoteAverage
+	suggestion.Genres = h.tmdb.FormatGenres(language, movie.GenreIDs)
+	suggestion.ReleaseYear = releaseYear(movie.ReleaseDate)
+	suggestion.GenreIDs = movie.GenreIDs
+	suggestion.MovieMetadata = movieMetadata(suggestion.MediaType, movie)
+
+	return h.db.UpdateSuggestionMetadata(suggestion)
+}
+
+func metadataProgress(done, total int) string {
+	return fmt.Sprintf("🔄 Refreshing metadata from TMDB... **%d/%d** done", done, total)
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"clapper/database"
+	"clapper/tmdb"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRefreshMetadata(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "refreshmetadata fills legacy suggestions in the background",
+			setup: func(env *testEnv) {
+				id := env.suggest(movieAlien, testMember)
+				env.suggest(movieHeat, testMember)
+				// Simula uma sugestão antiga, salva antes dos metadados completos
+				delete(env.store.refreshed, id)
+				env.store.findSuggestion("", id).MovieMetadata = database.MovieMetadata{}
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "refreshmetadata")
+			},
+			want: []string{"**1** updated, **0** failed out of 1 suggestion."},
+			check: func(t *testing.T, env *testEnv) {
+				if got := env.store.findSuggestion("", env.ids["Alien"]).Overview; got != movieAlien.Overview {
+					t.Errorf("expected overview to be refreshed, got %q", got)
+				}
+			},
+		},
+		{
+			name: "refreshmetadata translates titles to the server language",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieAlien, testMember)
+				env.store.SaveLanguage(testGuildID, "pt-BR")
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "refreshmetadata", boolOption("all", true))
+			},
+			want: []string{"**1** updated"},
+			check: func(t *testing.T, env *testEnv) {
+				suggestion := env.store.findSuggestion("", env.ids["Alien"])
+				if suggestion.MovieName != "Alien, o Oitavo Passageiro" || suggestion.OriginalTitle != "Alien" {
+					t.Errorf("expected the translated title, got %q (original %q)", suggestion.MovieName, suggestion.OriginalTitle)
+				}
+			},
+		},
+		{
+			name: "refreshmetadata all reports movies missing on TMDB",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.suggest(tmdb.Movie{ID: 1, Title: "Gone"}, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "refreshmetadata", boolOption("all", true))
+			},
+			want: []string{"**1** updated, **1** failed out of 2 suggestions.", "retried"},
+		},
+		{
+			name:  "refreshmetadata with nothing to refresh",
+			setup: func(env *testEnv) { env.suggest(movieHeat, testMember) },
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "refreshmetadata")
+			},
+			want: []string{"Every suggestion already has full metadata"},
+		},
+		{
+			name: "refreshmetadata requires the configure permission",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "refreshmetadata")
+			},
+			want: []string{"Only administrators can refresh movie metadata"},
+		},
+	})
+}
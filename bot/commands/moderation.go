@@ -0,0 +1,268 @@
+package commands
+
+import (
+	"clapper/database"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func moderationComponents(guildID string, suggestionID int) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Approve",
+					Style:    discordgo.SuccessButton,
+					CustomID: fmt.Sprintf("approve_suggestion_%s_%d", guildID, suggestionID),
+					Emoji:    &discordgo.ComponentEmoji{Name: "✅"},
+				},
+				discordgo.Button{
+					Label:    "Reject",
+					Style:    discordgo.DangerButton,
+					CustomID: fmt.Sprintf("reject_suggestion_%s_%d", guildID, suggestionID),
+					Emoji:    &discordgo.ComponentEmoji{Name: "❌"},
+				},
+			},
+		},
+	}
+}
+
+// pendingSuggestion valida o botão ou modal de moderação e devolve a sugestão que ainda aguarda decisão.
+// Quando algo impede a moderação, responde ao moderador e devolve nil.
+func (h *Handlers) pendingSuggestion(s Responder, i *discordgo.InteractionCreate, re *regexp.Regexp, customID string) *database.Suggestion {
+	respond := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	if !h.authorize(s, i, permissionHost) {
+		respond("❌ Only administrators and movie night hosts can moderate suggestions!")
+		return nil
+	}
+
+	matches := re.FindStringSubmatch(customID)
+	if len(matches) < 3 || matches[1] != i.GuildID {
+		respond("❌ This suggestion is for a different server.")
+		return nil
+	}
+
+	suggestionID, _ := strconv.Atoi(matches[2])
+	suggestion, err := h.db.GetSuggestion(i.GuildID, suggestionID)
+	if err != nil || suggestion == nil {
+		respond("❌ This suggestion no longer exists.")
+		return nil
+	}
+
+	if suggestion.Status != database.SuggestionPending {
+		respond(fmt.Sprintf("⚠️ **%s** has already been %s by another moderator.", suggestion.MovieName, suggestion.Status))
+		return nil
+	}
+
+	return suggestion
+}
+
+// moderatedEmbed é o cartão da fila depois da decisão, com quem decidiu e o motivo da rejeição
+func (h *Handlers) moderatedEmbed(suggestion *database.Suggestion, approved bool, moderator, reason string) *discordgo.MessageEmbed {
+	embed := h.suggestionEmbed(suggestion)
+	if approved {
+		embed.Color = 0x2ECC71
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "✅ Approved",
+			Value:  fmt.Sprintf("Approved by %s", moderator),
+			Inline: false,
+		})
+		return embed
+	}
+
+	value := fmt.Sprintf("Rejected by %s", moderator)
+	if reason != "" {
+		value += fmt.Sprintf("\n**Reason:** %s", reason)
+	}
+	embed.Color = 0xE74C3C
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "❌ Rejected", Value: value, Inline: false})
+	return embed
+}
+
+func (h *Handlers) HandleApproveSuggestion(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" || i.Member == nil {
+		return
+	}
+
+	suggestion := h.pendingSuggestion(s, i, regexp.MustCompile(`approve_suggestion_([^_]+)_(\d+)`), i.MessageComponentData().CustomID)
+	if suggestion == nil {
+		return
+	}
+
+	config, err := h.db.GetGuildConfig(guildID)
+	if err != nil || config == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This server has not been configured yet! Run `/setup channels` first.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	approved, err := h.db.SetSuggestionStatus(guildID, suggestion.ID, database.SuggestionPending, database.SuggestionApproved, i.Member.User.ID, "")
+	if err != nil || !approved {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("⚠️ **%s** has already been moderated by someone else.", suggestion.MovieName),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	embed := h.suggestionEmbed(suggestion)
+	components := voteComponents(guildID, suggestion.ID, 0, 0)
+	message, err := s.ChannelMessageSendComplex(config.SuggestionChannelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
+	if err != nil {
+		// Sem a mensagem pública ninguém consegue votar, então a sugestão volta para a fila
+		h.db.SetSuggestionStatus(guildID, suggestion.ID, database.SuggestionApproved, database.SuggestionPending, "", "")
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Could not post to the suggestion channel, so the suggestion is still awaiting approval. Please check the bot's permissions and try again.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	if err := h.db.SetSuggestionMessage(suggestion.ID, message.ChannelID, message.ID, suggestionPostHash(embed, components)); err != nil {
+		log.Printf("Erro ao gravar a mensagem da sugestão %d: %v", suggestion.ID, err)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{h.moderatedEmbed(suggestion, true, i.Member.User.Username, "")},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
+
+// HandleRejectSuggestion abre o modal com o motivo da rejeição; a decisão é gravada no envio do modal
+func (h *Handlers) HandleRejectSuggestion(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" || i.Member == nil {
+		return
+	}
+
+	suggestion := h.pendingSuggestion(s, i, regexp.MustCompile(`reject_suggestion_([^_]+)_(\d+)`), i.MessageComponentData().CustomID)
+	if suggestion == nil {
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: fmt.Sprintf("reject_modal_%s_%d", guildID, suggestion.ID),
+			Title:    truncate(fmt.Sprintf("Reject %s", suggestion.MovieName), 45),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "reason",
+							Label:       "Reason (sent to the suggester)",
+							Style:       discordgo.TextInputParagraph,
+							Placeholder: "Optional — e.g. already watched last season",
+							Required:    false,
+							MaxLength:   500,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func (h *Handlers) HandleRejectSuggestionSubmit(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" || i.Member == nil {
+		return
+	}
+
+	data := i.ModalSubmitData()
+	suggestion := h.pendingSuggestion(s, i, regexp.MustCompile(`reject_modal_([^_]+)_(\d+)`), data.CustomID)
+	if suggestion == nil {
+		return
+	}
+
+	reason := strings.TrimSpace(modalValue(data, "reason"))
+	rejected, err := h.db.SetSuggestionStatus(guildID, suggestion.ID, database.SuggestionPending, database.SuggestionRejected, i.Member.User.ID, reason)
+	if err != nil || !rejected {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("⚠️ **%s** has already been moderated by someone else.", suggestion.MovieName),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{h.moderatedEmbed(suggestion, false, i.Member.User.Username, reason)},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+
+	// Sem DM o motivo continua privado: o autor o vê no /mysuggestions e só o moderador é avisado da falha
+	if err := h.notifyRejection(s, guildID, suggestion, reason); err != nil {
+		s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+			Content: fmt.Sprintf("⚠️ Could not send a DM to <@%s>. They can still see the reason in `/mysuggestions`.", suggestion.UserID),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	}
+}
+
+// notifyRejection avisa o autor da sugestão por DM
+func (h *Handlers) notifyRejection(s Responder, guildID string, suggestion *database.Suggestion, reason string) error {
+	channel, err := s.UserChannelCreate(suggestion.UserID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.ChannelMessageSendEmbed(channel.ID, rejectionEmbed(s, guildID, suggestion, reason))
+	return err
+}
+
+func rejectionEmbed(s Responder, guildID string, suggestion *database.Suggestion, reason string) *discordgo.MessageEmbed {
+	serverName := "the server"
+	if guild, err := s.Guild(guildID); err == nil {
+		serverName = guild.Name
+	}
+
+	if reason == "" {
+		reason = "No reason was given."
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("❌ Suggestion Rejected: %s (%s)", suggestion.MovieName, suggestion.ReleaseYear),
+		Description: fmt.Sprintf("The moderators of **%s** did not approve your suggestion.", serverName),
+		Color:       0xE74C3C,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "📝 Reason", Value: reason, Inline: false},
+		},
+	}
+}
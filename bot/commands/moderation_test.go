@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"clapper/database"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestModeration(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "suggestion waits in the moderation queue",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveModerationSettings(testGuildID, true, testModChannelID)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"**Heat** was sent to the moderators for approval"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.sent) != 1 || env.responder.sent[0].channelID != testModChannelID {
+					t.Fatalf("expected one message in the moderation channel, got %+v", env.responder.sent)
+				}
+				suggestions, _ := env.store.GetAllSuggestions(testGuildID, database.SuggestionPending)
+				if len(suggestions) != 1 {
+					t.Fatalf("expected a pending suggestion, got %+v", suggestions)
+				}
+				want := fmt.Sprintf("approve_suggestion_%s_%d", testGuildID, suggestions[0].ID)
+				if row := env.responder.sent[0].data.Components[0].(discordgo.ActionsRow); row.Components[0].(discordgo.Button).CustomID != want {
+					t.Errorf("expected a %s button, got %+v", want, row.Components)
+				}
+				if movies, _ := env.store.GetRandomMovies(testGuildID, database.MovieFilter{}, 10); len(movies) != 0 {
+					t.Errorf("pending suggestions should not be pickable, got %+v", movies)
+				}
+			},
+		},
+		{
+			name: "approve posts the suggestion publicly",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.pending(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, fmt.Sprintf("approve_suggestion_%s_%s", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"Heat (1995)", "Approved by host"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.sent) != 1 || env.responder.sent[0].channelID != testChannelID {
+					t.Fatalf("expected one message in the suggestion channel, got %+v", env.responder.sent)
+				}
+				if movies, _ := env.store.GetRandomMovies(testGuildID, database.MovieFilter{}, 10); len(movies) != 1 {
+					t.Errorf("approved suggestion should be pickable, got %+v", movies)
+				}
+			},
+		},
+		{
+			name: "approve keeps the suggestion pending when the channel post fails",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.pending(movieHeat, testMember)
+				env.responder.sendErr = fmt.Errorf("HTTP 403 Forbidden")
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, fmt.Sprintf("approve_suggestion_%s_%s", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"still awaiting approval"},
+			check: func(t *testing.T, env *testEnv) {
+				if suggestion, _ := env.store.GetSuggestion(testGuildID, env.ids["Heat"]); suggestion.Status != database.SuggestionPending {
+					t.Errorf("expected the suggestion to stay pending, got %q", suggestion.Status)
+				}
+			},
+		},
+		{
+			name: "approve requires the host permission",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.pending(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("approve_suggestion_%s_%s", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"can moderate suggestions"},
+		},
+		{
+			name: "approve of a suggestion that was already moderated",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, fmt.Sprintf("approve_suggestion_%s_%s", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"**Heat** has already been approved"},
+		},
+		{
+			name: "reject asks for a reason",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.pending(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, fmt.Sprintf("reject_suggestion_%s_%s", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"Reject Heat"},
+			check: func(t *testing.T, env *testEnv) {
+				resp := env.responder.responses[len(env.responder.responses)-1]
+				if resp.Type != discordgo.InteractionResponseModal || resp.Data.CustomID != fmt.Sprintf("reject_modal_%s_%s", testGuildID, env.ref(movieHeat)) {
+					t.Errorf("expected the rejection modal, got %+v", resp)
+				}
+			},
+		},
+		{
+			name: "reject sends the reason to the suggester",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.pending(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testHost, fmt.Sprintf("reject_modal_%s_%s", testGuildID, env.ref(movieHeat)), map[string]string{"reason": "Watched it last season"})
+			},
+			want: []string{"Rejected by host", "**Reason:** Watched it last season"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.sent) != 1 || env.responder.sent[0].channelID != "dm-"+testMember.User.ID {
+					t.Fatalf("expected a DM to the suggester, got %+v", env.responder.sent)
+				}
+				if dm := strings.Join(embedText(env.responder.sent[0].data.Embeds), "\n"); !strings.Contains(dm, "Watched it last season") {
+					t.Errorf("DM should carry the reason, got %q", dm)
+				}
+				if suggestion, _ := env.store.GetSuggestion(testGuildID, env.ids["Heat"]); suggestion.Status != database.SuggestionRejected {
+					t.Errorf("expected the suggestion to be rejected, got %q", suggestion.Status)
+				}
+			},
+		},
+		{
+			name: "reject tells only the moderator when the DM fails",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.pending(movieHeat, testMember)
+				env.responder.dmClosed = true
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testHost, fmt.Sprintf("reject_modal_%s_%s", testGuildID, env.ref(movieHeat)), map[string]string{"reason": "Too long"})
+			},
+			want: []string{"Rejected by host"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.sent) != 0 {
+					t.Fatalf("a rejection must never be posted in a channel, got %+v", env.responder.sent)
+				}
+				if len(env.responder.followups) != 1 {
+					t.Fatalf("expected one follow-up for the moderator, got %d", len(env.responder.followups))
+				}
+				note := env.responder.followups[0]
+				if note.Flags&discordgo.MessageFlagsEphemeral == 0 {
+					t.Error("the DM failure note should be ephemeral")
+				}
+				if !strings.Contains(note.Content, "Could not send a DM to <@"+testMember.User.ID+">") ||
+					!strings.Contains(note.Content, "`/mysuggestions`") || strings.Contains(note.Content, "Too long") {
+					t.Errorf("unexpected follow-up %q", note.Content)
+				}
+			},
+		},
+		{
+			name: "reject sends no follow-up when the DM arrives",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.pending(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testHost, fmt.Sprintf("reject_modal_%s_%s", testGuildID, env.ref(movieHeat)), map[string]string{"reason": ""})
+			},
+			want: []string{"Rejected by host"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.followups) != 0 {
+					t.Errorf("expected no follow-up, got %+v", env.responder.followups)
+				}
+			},
+		},
+		{
+			name: "suggestions lists only approved movies by default",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieAlien, testMember)
+				env.pending(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestions")
+			},
+			want: []string{"Alien (1979)", "Page 1 of 1"},
+			check: func(t *testing.T, env *testEnv) {
+				if ids := env.responder.customIDs(); len(ids) != 2 || !strings.HasSuffix(ids[1], "_approved") {
+					t.Errorf("pagination should keep the status filter, got %v", ids)
+				}
+			},
+		},
+		{
+			name: "suggestions filters by status",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieAlien, testMember)
+				env.pending(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestions", stringOption("status", database.SuggestionPending))
+			},
+			want: []string{"Heat (1995)", "Awaiting approval", "Page 1 of 1"},
+		},
+		{
+			name:  "suggestions with no rejected movies",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestions", stringOption("status", database.SuggestionRejected))
+			},
+			want: []string{"There are no rejected suggestions"},
+		},
+		{
+			name: "mysuggestions shows why a suggestion was rejected",
+			setup: func(env *testEnv) {
+				env.configure()
+				id := env.pending(movieHeat, testMember)
+				env.store.SetSuggestionStatus(testGuildID, id, database.SuggestionPending, database.SuggestionRejected, testHost.User.ID, "Too long")
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "mysuggestions")
+			},
+			want: []string{"Rejected by the moderators", "Rejection Reason\nToo long"},
+		},
+	})
+}
@@ -0,0 +1,674 @@
+package commands
+
+import (
+	"clapper/database"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	minPollCandidates = 2
+	maxPollCandidates = 10
+	// maxPollRanks é o número de menus de ranking; a quinta linha de componentes fica para os botões
+	maxPollRanks = 4
+
+	minPollDuration = time.Minute
+	maxPollDuration = 7 * 24 * time.Hour
+)
+
+// parsePollDuration aceita durações do Go ("90m", "1h30m") e dias inteiros ("2d")
+func parsePollDuration(input string) (time.Duration, error) {
+	duration, err := parseDuration(input)
+	if err != nil {
+		return 0, err
+	}
+
+	if duration < minPollDuration || duration > maxPollDuration {
+		return 0, fmt.Errorf("the poll must last between 1 minute and 7 days")
+	}
+	return duration, nil
+}
+
+func rankLabel(rank int) string {
+	switch rank {
+	case 1:
+		return "1st"
+	case 2:
+		return "2nd"
+	case 3:
+		return "3rd"
+	}
+	return fmt.Sprintf("%dth", rank)
+}
+
+func pollRanks(poll *database.MoviePoll) int {
+	if len(poll.Candidates) < maxPollRanks {
+		return len(poll.Candidates)
+	}
+	return maxPollRanks
+}
+
+func candidateLabel(movie database.MovieResult) string {
+	return truncate(fmt.Sprintf("%s (%s)", movie.MovieName, movie.ReleaseYear), 100)
+}
+
+func pollEmbed(poll *database.MoviePoll, startedBy string) *discordgo.MessageEmbed {
+	var fields []*discordgo.MessageEmbedField
+	for idx, movie := range poll.Candidates {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("%d. %s", idx+1, candidateLabel(movie)),
+			Value:  fmt.Sprintf("⭐ %.1f/10 · 🎭 %s · 👤 %s", movie.Rating, movie.Genres, movie.Username),
+			Inline: false,
+		})
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: "🗳️ Movie Night Poll",
+		Description: fmt.Sprintf("Rank the candidates with the menus below. Your 1st choice counts first; if it is eliminated, "+
+			"your vote moves to your next choice.\n\nThe poll closes <t:%d:F> (<t:%d:R>).", poll.ClosesAt.Unix(), poll.ClosesAt.Unix()),
+		Color:     0x5865F2,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Fields:    fields,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Poll #%d · Started by %s", poll.ID, startedBy),
+		},
+	}
+}
+
+func pollComponents(poll *database.MoviePoll) []discordgo.MessageComponent {
+	var options []discordgo.SelectMenuOption
+	for _, movie := range poll.Candidates {
+		options = append(options, discordgo.SelectMenuOption{
+			Label: candidateLabel(movie),
+			Value: strconv.Itoa(movie.ID),
+		})
+	}
+
+	var components []discordgo.MessageComponent
+	for rank := 1; rank <= pollRanks(poll); rank++ {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    fmt.Sprintf("poll_rank_%s_%d_%d", poll.GuildID, poll.ID, rank),
+					Placeholder: fmt.Sprintf("Your %s choice", rankLabel(rank)),
+					Options:     options,
+				},
+			},
+		})
+	}
+
+	components = append(components, discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Clear My Ballot",
+				Style:    discordgo.SecondaryButton,
+				CustomID: fmt.Sprintf("poll_clear_%s_%d", poll.GuildID, poll.ID),
+				Emoji: &discordgo.ComponentEmoji{
+					Name: "🧹",
+				},
+			},
+			discordgo.Button{
+				Label:    "End Poll Now",
+				Style:    discordgo.DangerButton,
+				CustomID: fmt.Sprintf("poll_close_%s_%d", poll.GuildID, poll.ID),
+				Emoji: &discordgo.ComponentEmoji{
+					Name: "🏁",
+				},
+			},
+		},
+	})
+
+	return components
+}
+
+func (h *Handlers) HandleMoviePoll(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This command can only be used in a server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can start movie polls!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	candidates := minPollCandidates
+	var durationInput string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "candidates":
+			candidates = int(opt.IntValue())
+		case "duration":
+			durationInput = opt.StringValue()
+		}
+	}
+
+	duration, err := parsePollDuration(durationInput)
+	if err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("❌ Could not read \"%s\": %s.", durationInput, err),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	movies, err := h.db.GetRandomMovies(guildID, database.MovieFilter{}, candidates)
+	if err != nil || len(movies) < minPollCandidates {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ A poll needs at least 2 movies that haven't been selected yet!"),
+		})
+		return
+	}
+
+	poll := &database.MoviePoll{
+		GuildID:    guildID,
+		ChannelID:  i.ChannelID,
+		CreatedBy:  i.Member.User.ID,
+		ClosesAt:   time.Now().Add(duration),
+		Candidates: movies,
+	}
+
+	pollID, err := h.db.CreatePoll(poll)
+	if err != nil {
+		log.Printf("Erro ao criar votação: %v", err)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while starting the poll. Please try again."),
+		})
+		return
+	}
+	poll.ID = int(pollID)
+
+	embed := pollEmbed(poll, i.Member.User.Username)
+	components := pollComponents(poll)
+
+	content := ""
+	if len(movies) < candidates {
+		content = fmt.Sprintf("ℹ️ Only %d movies were available, so the poll has fewer candidates than requested.", len(movies))
+	}
+
+	message, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    ptrString(content),
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	})
+	if err == nil && message != nil && message.ID != "" {
+		// O token da interação expira em 15 minutos; o resultado é publicado editando a mensagem pelo canal
+		if err := h.db.SetPollMessage(poll.ID, message.ID); err != nil {
+			log.Printf("Erro ao salvar a mensagem da votação %d: %v", poll.ID, err)
+		}
+	}
+
+	h.schedulePollClose(s, guildID, poll.ID, poll.ClosesAt)
+}
+
+// pollFromCustomID valida o custom ID de um componente da votação e carrega a votação correspondente
+func (h *Handlers) pollFromCustomID(s Responder, i *discordgo.InteractionCreate, re *regexp.Regexp) (*database.MoviePoll, []string) {
+	matches := re.FindStringSubmatch(i.MessageComponentData().CustomID)
+	if len(matches) < 3 || matches[1] != i.GuildID {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ An error occurred. Please try again.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return nil, nil
+	}
+
+	pollID, _ := strconv.Atoi(matches[2])
+	poll, err := h.db.GetPoll(i.GuildID, pollID)
+	if err != nil || poll == nil || !poll.IsOpen() {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This poll has already closed.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return nil, nil
+	}
+
+	return poll, matches
+}
+
+func describeBallot(poll *database.MoviePoll, ballot []int) string {
+	names := map[int]string{}
+	for _, movie := range poll.Candidates {
+		names[movie.ID] = candidateLabel(movie)
+	}
+
+	var lines []string
+	for _, suggestionID := range ballot {
+		if name, ok := names[suggestionID]; ok {
+			lines = append(lines, fmt.Sprintf("%d. %s", len(lines)+1, name))
+		}
+	}
+	if len(lines) == 0 {
+		return "Your ballot is empty."
+	}
+	return "🗳️ **Your ballot:**\n" + strings.Join(lines, "\n")
+}
+
+func (h *Handlers) HandlePollRank(s Responder, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" || i.Member == nil {
+		return
+	}
+
+	poll, matches := h.pollFromCustomID(s, i, regexp.MustCompile(`poll_rank_([^_]+)_(\d+)_(\d+)`))
+	if poll == nil {
+		return
+	}
+
+	rank, _ := strconv.Atoi(matches[3])
+	values := i.MessageComponentData().Values
+	suggestionID := 0
+	if len(values) > 0 {
+		suggestionID, _ = strconv.Atoi(values[0])
+	}
+
+	valid := false
+	for _, movie := range poll.Candidates {
+		valid = valid || movie.ID == suggestionID
+	}
+	if !valid || rank < 1 || rank > pollRanks(poll) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This movie is no longer part of the poll.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if err := h.db.SaveBallotChoice(poll.ID, i.Member.User.ID, rank, suggestionID); err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ An error occurred while saving your ballot. Please try again.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	ballot, _ := h.db.GetBallot(poll.ID, i.Member.User.ID)
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: describeBallot(poll, ballot) + "\n\nPicking a movie again in another slot moves it there.",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *Handlers) HandlePollClear(s Responder, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" || i.Member == nil {
+		return
+	}
+
+	poll, _ := h.pollFromCustomID(s, i, regexp.MustCompile(`poll_clear_([^_]+)_(\d+)`))
+	if poll == nil {
+		return
+	}
+
+	msg := "🧹 Your ballot has been cleared."
+	if err := h.db.ClearBallot(poll.ID, i.Member.User.ID); err != nil {
+		msg = "❌ An error occurred while clearing your ballot. Please try again."
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: msg,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *Handlers) HandlePollClose(s Responder, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		return
+	}
+
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can end polls!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	poll, _ := h.pollFromCustomID(s, i, regexp.MustCompile(`poll_close_([^_]+)_(\d+)`))
+	if poll == nil {
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	msg := "✅ The poll has been closed and the results were posted."
+	if !h.closePoll(s, i.GuildID, poll.ID) {
+		msg = "❌ This poll has already closed."
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: ptrString(msg),
+	})
+}
+
+// schedulePollClose agenda a apuração da votação para o fim do prazo
+func (h *Handlers) schedulePollClose(s Responder, guildID string, pollID int, closesAt time.Time) {
+	h.pollMu.Lock()
+	defer h.pollMu.Unlock()
+
+	if timer, ok := h.pollTimers[pollID]; ok {
+		timer.Stop()
+	}
+	h.pollTimers[pollID] = time.AfterFunc(time.Until(closesAt), func() {
+		// A apuração entra em background para o Shutdown esperá-la; depois que ele começa, o timer não faz mais nada
+		h.pollMu.Lock()
+		select {
+		case <-h.stopping:
+			h.pollMu.Unlock()
+			return
+		default:
+		}
+		h.background.Add(1)
+		h.pollMu.Unlock()
+
+		defer h.background.Done()
+		h.closePoll(s, guildID, pollID)
+	})
+}
+
+// ResumePolls reagenda as votações que continuavam abertas quando o bot foi desligado;
+// as que venceram nesse meio-tempo são apuradas na hora
+func (h *Handlers) ResumePolls(s Responder) error {
+	polls, err := h.db.GetOpenPolls()
+	if err != nil {
+		return err
+	}
+
+	for _, poll := range polls {
+		if poll.ClosesAt.After(time.Now()) {
+			h.schedulePollClose(s, poll.GuildID, poll.ID, poll.ClosesAt)
+		} else {
+			h.closePoll(s, poll.GuildID, poll.ID)
+		}
+	}
+	if len(polls) > 0 {
+		log.Printf("%d votação(ões) aberta(s) retomada(s)", len(polls))
+	}
+	return nil
+}
+
+// Shutdown cancela os timers das votações e das revelações, interrompe as tarefas longas e espera as
+// tarefas em segundo plano terminarem
+func (h *Handlers) Shutdown() {
+	h.pollMu.Lock()
+	h.stopOnce.Do(func() { close(h.stopping) })
+	for pollID, timer := range h.pollTimers {
+		timer.Stop()
+		delete(h.pollTimers, pollID)
+	}
+	h.pollMu.Unlock()
+
+	h.revealMu.Lock()
+	for suggestionID, timer := range h.revealTimers {
+		timer.Stop()
+		delete(h.revealTimers, suggestionID)
+	}
+	h.revealMu.Unlock()
+
+	h.background.Wait()
+}
+
+// closePoll apura a votação por segundo turno instantâneo, seleciona o vencedor e publica o resultado.
+// Devolve false se a votação já tinha sido encerrada por outro caminho.
+func (h *Handlers) closePoll(s Responder, guildID string, pollID int) bool {
+	h.pollMu.Lock()
+	if timer, ok := h.pollTimers[pollID]; ok {
+		timer.Stop()
+		delete(h.pollTimers, pollID)
+	}
+	h.pollMu.Unlock()
+
+	poll, err := h.db.GetPoll(guildID, pollID)
+	if err != nil || poll == nil || !poll.IsOpen() {
+		return false
+	}
+
+	// Filmes selecionados por outro caminho enquanto a votação estava aberta não podem vencer
+	var candidates []database.MovieResult
+	for _, movie := range poll.Candidates {
+		if selected, err := h.db.GetSelectedMovie(guildID, movie.ID); err == nil && selected == nil {
+			candidates = append(candidates, movie)
+		}
+	}
+
+	ballots, err := h.db.GetPollBallots(poll.ID)
+	if err != nil {
+		log.Printf("Erro ao carregar as cédulas da votação %d: %v", poll.ID, err)
+		return false
+	}
+
+	candidateIDs := make([]int, len(candidates))
+	for idx, movie := range candidates {
+		candidateIDs[idx] = movie.ID
+	}
+	winnerID, rounds := instantRunoff(candidateIDs, ballots)
+
+	closed, err := h.db.ClosePoll(poll.ID, winnerID)
+	if err != nil || !closed {
+		if err != nil {
+			log.Printf("Erro ao encerrar a votação %d: %v", poll.ID, err)
+		}
+		return false
+	}
+
+	var winner *database.MovieResult
+	for idx := range candidates {
+		if candidates[idx].ID == winnerID {
+			winner = &candidates[idx]
+		}
+	}
+	if winner != nil {
+		if err := h.db.MarkMovieSelected(guildID, winner.ID); err != nil {
+			log.Printf("Erro ao selecionar o vencedor da votação %d: %v", poll.ID, err)
+		} else {
+			h.syncSuggestionPosts(s, guildID, winner.ID)
+		}
+	}
+
+	embed := pollResultEmbed(poll, candidates, winner, rounds, len(ballots))
+	if posterURL := h.tmdb.GetPosterURL(winnerPoster(winner)); posterURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: posterURL}
+	}
+
+	if poll.MessageID != "" {
+		_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			ID:         poll.MessageID,
+			Channel:    poll.ChannelID,
+			Content:    ptrString(""),
+			Embeds:     &[]*discordgo.MessageEmbed{embed},
+			Components: &[]discordgo.MessageComponent{},
+		})
+		if err != nil {
+			log.Printf("Erro ao editar a mensagem da votação %d: %v", poll.ID, err)
+		}
+	}
+
+	announcement := "🗳️ The movie poll has closed. " + embed.Description
+	if winner != nil {
+		announcement = fmt.Sprintf("🏆 **%s (%s)** won the movie poll and has been selected! Hosts can schedule it with `/schedule`.",
+			winner.MovieName, winner.ReleaseYear)
+	}
+	_, err = s.ChannelMessageSendComplex(poll.ChannelID, &discordgo.MessageSend{
+		Content: announcement,
+		Embeds:  []*discordgo.MessageEmbed{embed},
+	})
+	if err != nil {
+		log.Printf("Erro ao anunciar o resultado da votação %d: %v", poll.ID, err)
+	}
+
+	return true
+}
+
+func winnerPoster(winner *database.MovieResult) string {
+	if winner == nil {
+		return ""
+	}
+	return winner.PosterPath
+}
+
+func pollResultEmbed(poll *database.MoviePoll, candidates []database.MovieResult, winner *database.MovieResult, rounds []runoffRound, voters int) *discordgo.MessageEmbed {
+	names := map[int]string{}
+	for _, movie := range candidates {
+		names[movie.ID] = candidateLabel(movie)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:     "🗳️ Movie Night Poll — Closed",
+		Color:     0x00FF00,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Poll #%d · %d ballot%s", poll.ID, voters, pluralize(voters)),
+		},
+	}
+
+	if winner == nil {
+		embed.Description = "Nobody voted, so no movie was selected."
+		if voters > 0 {
+			embed.Description = "None of the candidates can be selected anymore, so no movie was selected."
+		}
+		embed.Color = 0x808080
+		return embed
+	}
+
+	embed.Description = fmt.Sprintf("🏆 **%s** wins and has been selected!", candidateLabel(*winner))
+
+	var lines []string
+	for idx, round := range rounds {
+		var tallies []string
+		for _, id := range round.order {
+			tallies = append(tallies, fmt.Sprintf("%s: %d", names[id], round.tallies[id]))
+		}
+		line := fmt.Sprintf("**Round %d:** %s", idx+1, strings.Join(tallies, " · "))
+		if round.eliminated != 0 {
+			line += fmt.Sprintf(" → ❌ %s eliminated", names[round.eliminated])
+		}
+		lines = append(lines, line)
+	}
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "📊 Instant-runoff rounds", Value: truncate(strings.Join(lines, "\n"), 1024), Inline: false},
+	}
+
+	return embed
+}
+
+// runoffRound guarda a contagem de uma rodada do segundo turno instantâneo
+type runoffRound struct {
+	order      []int
+	tallies    map[int]int
+	eliminated int
+}
+
+// instantRunoff apura as cédulas (IDs em ordem de preferência) e devolve o vencedor, ou 0 se ninguém votou.
+// Em cada rodada cada cédula conta para o seu candidato preferido ainda na disputa; sem maioria absoluta
+// o menos votado é eliminado. Empates na eliminação caem sobre quem teve menos primeiras escolhas e,
+// persistindo, sobre quem foi sorteado por último.
+func instantRunoff(candidates []int, ballots [][]int) (int, []runoffRound) {
+	active := append([]int(nil), candidates...)
+	var rounds []runoffRound
+	var firstRound map[int]int
+
+	for len(active) > 0 {
+		inRace := map[int]bool{}
+		round := runoffRound{order: active, tallies: map[int]int{}}
+		for _, id := range active {
+			inRace[id] = true
+			round.tallies[id] = 0
+		}
+
+		total := 0
+		for _, ballot := range ballots {
+			for _, id := range ballot {
+				if inRace[id] {
+					round.tallies[id]++
+					total++
+					break
+				}
+			}
+		}
+		if firstRound == nil {
+			firstRound = round.tallies
+		}
+
+		if total == 0 {
+			return 0, rounds
+		}
+
+		for _, id := range active {
+			if round.tallies[id]*2 > total || len(active) == 1 {
+				rounds = append(rounds, round)
+				return id, rounds
+			}
+		}
+
+		loser := active[len(active)-1]
+		for idx := len(active) - 1; idx >= 0; idx-- {
+			id := active[idx]
+			if round.tallies[id] < round.tallies[loser] ||
+				(round.tallies[id] == round.tallies[loser] && firstRound[id] < firstRound[loser]) {
+				loser = id
+			}
+		}
+		round.eliminated = loser
+		rounds = append(rounds, round)
+
+		var next []int
+		for _, id := range active {
+			if id != loser {
+				next = append(next, id)
+			}
+		}
+		active = next
+	}
+
+	return 0, rounds
+}
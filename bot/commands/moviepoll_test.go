@@ -0,0 +1,323 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestInstantRunoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []int
+		ballots    [][]int
+		winner     int
+		rounds     int
+	}{
+		{
+			name:       "first-round majority",
+			candidates: []int{1, 2, 3},
+			ballots:    [][]int{{1}, {1, 2}, {3}},
+			winner:     1,
+			rounds:     1,
+		},
+		{
+			name:       "eliminated votes transfer to the next choice",
+			candidates: []int{1, 2, 3},
+			ballots:    [][]int{{1}, {1}, {2, 3}, {3, 2}, {3, 2}},
+			winner:     3,
+			rounds:     2,
+		},
+		{
+			name:       "exhausted ballots stop counting",
+			candidates: []int{1, 2, 3},
+			ballots:    [][]int{{1}, {1}, {2}, {3}},
+			winner:     1,
+			rounds:     2,
+		},
+		{
+			name:       "ties eliminate the candidate drawn last",
+			candidates: []int{1, 2},
+			ballots:    [][]int{{1}, {2}},
+			winner:     1,
+			rounds:     2,
+		},
+		{
+			name:       "ballots for unknown candidates are ignored",
+			candidates: []int{1, 2},
+			ballots:    [][]int{{9, 2}, {9}},
+			winner:     2,
+			rounds:     1,
+		},
+		{
+			name:       "no ballots",
+			candidates: []int{1, 2},
+			winner:     0,
+			rounds:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			winner, rounds := instantRunoff(tt.candidates, tt.ballots)
+			if winner != tt.winner || len(rounds) != tt.rounds {
+				t.Errorf("expected winner %d after %d rounds, got %d after %d rounds", tt.winner, tt.rounds, winner, len(rounds))
+			}
+		})
+	}
+}
+
+func TestResumePolls(t *testing.T) {
+	env := newTestEnv(t)
+	env.suggest(movieHeat, testMember)
+	env.suggest(movieAlien, testMember)
+
+	overdue := env.poll(movieHeat, movieAlien)
+	env.store.polls[overdue].poll.ClosesAt = time.Now().Add(-time.Minute)
+	env.store.SaveBallotChoice(overdue, testMember.User.ID, 1, env.ids["Heat"])
+	open := env.poll(movieHeat, movieAlien)
+
+	if err := env.handlers.ResumePolls(env.responder); err != nil {
+		t.Fatal(err)
+	}
+
+	if poll, _ := env.store.GetPoll(testGuildID, overdue); poll.IsOpen() || poll.WinnerID != env.ids["Heat"] {
+		t.Errorf("expected the overdue poll to be closed with Heat as winner, got %+v", poll)
+	}
+
+	var scheduled []int
+	for pollID := range env.handlers.pollTimers {
+		scheduled = append(scheduled, pollID)
+	}
+	if !reflect.DeepEqual(scheduled, []int{open}) {
+		t.Errorf("expected only poll %d to be scheduled, got %v", open, scheduled)
+	}
+
+	if openPolls, _ := env.store.GetOpenPolls(); len(openPolls) != 1 || openPolls[0].ID != open {
+		t.Errorf("expected poll %d to stay open, got %+v", open, openPolls)
+	}
+}
+
+func TestPollTimersAfterShutdown(t *testing.T) {
+	env := newTestEnv(t)
+	env.suggest(movieHeat, testMember)
+	env.suggest(movieAlien, testMember)
+	pollID := env.poll(movieHeat, movieAlien)
+
+	// Um timer que dispara depois do Shutdown não apura mais nada; a votação é retomada na próxima inicialização
+	env.handlers.Shutdown()
+	env.handlers.schedulePollClose(env.responder, testGuildID, pollID, time.Now())
+	time.Sleep(20 * time.Millisecond)
+	env.handlers.background.Wait()
+
+	if poll, _ := env.store.GetPoll(testGuildID, pollID); !poll.IsOpen() {
+		t.Errorf("expected the poll to stay open after shutdown, got %+v", poll)
+	}
+}
+
+func TestMoviePollInteractions(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "moviepoll draws candidates and posts rank menus",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+				env.suggest(movieParasite, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "moviepoll", intOption("candidates", 3), stringOption("duration", "2h"))
+			},
+			want: []string{"Movie Night Poll", "Heat (1995)", "Alien (1979)", "Parasite (2019)"},
+			check: func(t *testing.T, env *testEnv) {
+				polls, _ := env.store.GetOpenPolls()
+				if len(polls) != 1 || polls[0].MessageID == "" || time.Until(polls[0].ClosesAt) < 119*time.Minute {
+					t.Fatalf("expected one open two-hour poll with its message saved, got %+v", polls)
+				}
+				ids := strings.Join(env.responder.customIDs(), " ")
+				for _, prefix := range []string{"poll_rank_guild1_%d_1", "poll_rank_guild1_%d_3", "poll_clear_guild1_%d", "poll_close_guild1_%d"} {
+					if !strings.Contains(ids, fmt.Sprintf(prefix, polls[0].ID)) {
+						t.Errorf("missing component %s in %s", fmt.Sprintf(prefix, polls[0].ID), ids)
+					}
+				}
+				if len(env.handlers.pollTimers) != 1 {
+					t.Errorf("expected the poll to be scheduled, got %d timers", len(env.handlers.pollTimers))
+				}
+			},
+		},
+		{
+			name: "moviepoll with fewer movies than requested",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "moviepoll", intOption("candidates", 5), stringOption("duration", "1d"))
+			},
+			want: []string{"Only 2 movies were available", "Movie Night Poll"},
+		},
+		{
+			name: "moviepoll needs two available movies",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.selectMovie(movieAlien, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "moviepoll", intOption("candidates", 3), stringOption("duration", "1h"))
+			},
+			want: []string{"at least 2 movies"},
+		},
+		{
+			name:  "moviepoll rejects an invalid duration",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "moviepoll", intOption("candidates", 3), stringOption("duration", "8d"))
+			},
+			want: []string{`Could not read "8d"`, "between 1 minute and 7 days"},
+		},
+		{
+			name:  "moviepoll requires the host permission",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "moviepoll", intOption("candidates", 3), stringOption("duration", "1h"))
+			},
+			want: []string{"can start movie polls"},
+		},
+		{
+			name: "ranking a movie saves the ballot",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+				env.poll(movieHeat, movieAlien)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("poll_rank_%s_%d_1", testGuildID, env.lastPoll), env.ref(movieAlien))
+			},
+			want: []string{"Your ballot", "1. Alien (1979)"},
+		},
+		{
+			name: "ranking a movie again moves it to the new slot",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+				id := env.poll(movieHeat, movieAlien)
+				env.store.SaveBallotChoice(id, testMember.User.ID, 1, env.ids["Heat"])
+				env.store.SaveBallotChoice(id, testMember.User.ID, 2, env.ids["Alien"])
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("poll_rank_%s_%d_1", testGuildID, env.lastPoll), env.ref(movieAlien))
+			},
+			check: func(t *testing.T, env *testEnv) {
+				ballot, _ := env.store.GetBallot(env.lastPoll, testMember.User.ID)
+				if len(ballot) != 1 || ballot[0] != env.ids["Alien"] {
+					t.Errorf("expected only Alien in first place, got %v", ballot)
+				}
+			},
+		},
+		{
+			name: "clearing the ballot",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+				id := env.poll(movieHeat, movieAlien)
+				env.store.SaveBallotChoice(id, testMember.User.ID, 1, env.ids["Heat"])
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("poll_clear_%s_%d", testGuildID, env.lastPoll))
+			},
+			want: []string{"Your ballot has been cleared"},
+			check: func(t *testing.T, env *testEnv) {
+				if ballot, _ := env.store.GetBallot(env.lastPoll, testMember.User.ID); len(ballot) != 0 {
+					t.Errorf("expected an empty ballot, got %v", ballot)
+				}
+			},
+		},
+		{
+			name: "ending the poll selects the instant-runoff winner",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+				env.suggest(movieParasite, testMember)
+				id := env.poll(movieHeat, movieAlien, movieParasite)
+				env.store.SaveBallotChoice(id, "10", 1, env.ids["Heat"])
+				env.store.SaveBallotChoice(id, "11", 1, env.ids["Alien"])
+				env.store.SaveBallotChoice(id, "12", 1, env.ids["Parasite"])
+				env.store.SaveBallotChoice(id, "12", 2, env.ids["Alien"])
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, fmt.Sprintf("poll_close_%s_%d", testGuildID, env.lastPoll))
+			},
+			want: []string{"The poll has been closed"},
+			check: func(t *testing.T, env *testEnv) {
+				if selected, _ := env.store.GetSelectedMovie(testGuildID, env.ids["Alien"]); selected == nil {
+					t.Error("expected Alien to be selected")
+				}
+				if len(env.responder.sent) != 1 || !strings.Contains(env.responder.sent[0].data.Content, "**Alien (1979)** won") {
+					t.Errorf("expected a winner announcement, got %+v", env.responder.sent)
+				}
+				rounds := strings.Join(embedText(env.responder.sent[0].data.Embeds), "\n")
+				if !strings.Contains(rounds, "Parasite (2019) eliminated") {
+					t.Errorf("expected the runoff rounds in the results, got:\n%s", rounds)
+				}
+				if len(env.responder.messageEdits) != 1 || len(*env.responder.messageEdits[0].Components) != 0 {
+					t.Error("expected the poll message to be closed without components")
+				}
+				if poll, _ := env.store.GetPoll(testGuildID, env.lastPoll); poll.IsOpen() || poll.WinnerID != env.ids["Alien"] {
+					t.Errorf("expected the poll to be closed with Alien as winner, got %+v", poll)
+				}
+			},
+		},
+		{
+			name: "ending a poll without ballots selects nothing",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+				env.poll(movieHeat, movieAlien)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, fmt.Sprintf("poll_close_%s_%d", testGuildID, env.lastPoll))
+			},
+			check: func(t *testing.T, env *testEnv) {
+				if count, _ := env.store.GetSelectedMoviesCount(testGuildID); count != 0 {
+					t.Errorf("expected no selections, got %d", count)
+				}
+				if len(env.responder.sent) != 1 || !strings.Contains(env.responder.sent[0].data.Content, "Nobody voted") {
+					t.Errorf("expected a no-votes announcement, got %+v", env.responder.sent)
+				}
+			},
+		},
+		{
+			name: "ending a poll requires the host permission",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+				env.poll(movieHeat, movieAlien)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("poll_close_%s_%d", testGuildID, env.lastPoll))
+			},
+			want: []string{"can end polls"},
+		},
+		{
+			name: "voting on a closed poll",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+				env.store.ClosePoll(env.poll(movieHeat, movieAlien), 0)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("poll_rank_%s_%d_1", testGuildID, env.lastPoll), env.ref(movieHeat))
+			},
+			want: []string{"This poll has already closed"},
+		},
+	})
+}
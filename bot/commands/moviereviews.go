@@ -3,11 +3,12 @@ package commands
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func (h *Handlers) HandleMovieReviews(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleMovieReviews(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -28,12 +29,18 @@ func (h *Handlers) HandleMovieReviews(s *discordgo.Session, i *discordgo.Interac
 	})
 
 	options := i.ApplicationCommandData().Options
-	movieName := options[0].StringValue()
+	suggestionID, ok := parseSuggestionID(options[0].StringValue())
+	if !ok {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Please pick a movie from the list of selected movies shown while typing."),
+		})
+		return
+	}
 
-	movie, err := h.db.SearchSelectedMovie(guildID, movieName)
+	movie, err := h.db.GetSelectedMovie(guildID, suggestionID)
 	if err != nil || movie == nil {
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: ptrString(fmt.Sprintf("❌ Could not find a selected movie matching \"%s\".", movieName)),
+			Content: ptrString("❌ Could not find that selected movie."),
 		})
 		return
 	}
@@ -55,7 +62,12 @@ func (h *Handlers) HandleMovieReviews(s *discordgo.Session, i *discordgo.Interac
 		Fields:      []*discordgo.MessageEmbedField{},
 	}
 
-	if reviewCount > 0 {
+	// Às cegas, a média e as avaliações ficam de fora e só aparece quem já avaliou
+	blind := h.hiddenRatings(guildID, movie.ID)
+
+	if blind != nil {
+		embed.Fields = append(embed.Fields, blindRatingField(blind, reviews))
+	} else if reviewCount > 0 {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 			Name:   "📊 Community Rating",
 			Value:  fmt.Sprintf("⭐ **%.1f/10** based on %d review%s", avgRating, reviewCount, pluralize(reviewCount)),
@@ -77,7 +89,7 @@ func (h *Handlers) HandleMovieReviews(s *discordgo.Session, i *discordgo.Interac
 		Inline: true,
 	})
 
-	if len(reviews) > 0 {
+	if blind == nil && len(reviews) > 0 {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 			Name:   "━━━━━━━━━━━━━━━━━━━━",
 			Value:  "**User Reviews**",
@@ -95,9 +107,9 @@ func (h *Handlers) HandleMovieReviews(s *discordgo.Session, i *discordgo.Interac
 			value := fmt.Sprintf("⭐ **%.1f/10**", review.Rating)
 			
 			if review.ReviewText != "" {
-				reviewText := review.ReviewText
-				if len(reviewText) > 200 {
-					reviewText = reviewText[:200] + "..."
+				reviewText := truncate(review.ReviewText, 200)
+				if review.Spoiler {
+					reviewText = fmt.Sprintf("||%s||", reviewText)
 				}
 				value += fmt.Sprintf("\n*\"%s\"*", reviewText)
 			}
@@ -118,20 +130,26 @@ func (h *Handlers) HandleMovieReviews(s *discordgo.Session, i *discordgo.Interac
 		}
 	}
 
-	tmdbMovie, _ := h.tmdb.GetMovieByID(movie.TMDBID)
-	if tmdbMovie != nil {
-		posterURL := h.tmdb.GetPosterURL(tmdbMovie.PosterPath)
-		if posterURL != "" {
-			embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: posterURL}
-		}
+	if screening, err := h.db.GetScreening(guildID, movie.ID); err == nil && screening != nil && screening.ThreadID != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "💬 Discussion",
+			Value:  fmt.Sprintf("Join the conversation in <#%s>", screening.ThreadID),
+			Inline: false,
+		})
+	}
+
+	if posterURL := h.tmdb.GetPosterURL(movie.PosterPath); posterURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: posterURL}
 	}
 
+	components := rateComponents(guildID, movie.ID)
 	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Embeds: &[]*discordgo.MessageEmbed{embed},
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
 	})
 }
 
-func (h *Handlers) HandleSelectedMovies(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleSelectedMovies(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -178,13 +196,24 @@ func (h *Handlers) HandleSelectedMovies(s *discordgo.Session, i *discordgo.Inter
 		value.WriteString(fmt.Sprintf("**%s** (%s)\n", movie.MovieName, movie.ReleaseYear))
 		value.WriteString(fmt.Sprintf("🎭 TMDB: %.1f/10\n", movie.Rating))
 		
-		if movie.ReviewCount > 0 {
+		if movie.ReviewCount > 0 && h.hiddenRatings(guildID, movie.ID) != nil {
+			value.WriteString(fmt.Sprintf("⭐ Community: 🙈 Hidden until the reveal (%d review%s)\n",
+				movie.ReviewCount, pluralize(movie.ReviewCount)))
+		} else if movie.ReviewCount > 0 {
 			value.WriteString(fmt.Sprintf("⭐ Community: %.1f/10 (%d review%s)\n", 
 				movie.AverageScore, movie.ReviewCount, pluralize(movie.ReviewCount)))
 		} else {
 			value.WriteString("⭐ Community: No reviews yet\n")
 		}
 		
+		if movie.ScheduledFor != nil {
+			label := "Watched on"
+			if movie.ScheduledFor.After(time.Now()) {
+				label = "Scheduled for"
+			}
+			value.WriteString(fmt.Sprintf("🗓️ %s: <t:%d:F>\n", label, movie.ScheduledFor.Unix()))
+		}
+
 		value.WriteString(fmt.Sprintf("👤 Suggested by: %s", movie.Username))
 
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
@@ -6,7 +6,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func (h *Handlers) HandleMovieStats(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleMovieStats(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
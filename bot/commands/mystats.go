@@ -1,12 +1,15 @@
 package commands
 
 import (
+	"clapper/database"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func (h *Handlers) HandleMyStats(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleMyStats(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -30,6 +33,32 @@ func (h *Handlers) HandleMyStats(s *discordgo.Session, i *discordgo.InteractionC
 		},
 	}
 
+	config, err := h.db.GetGuildConfig(guildID)
+	if err != nil {
+		config = nil
+	}
+
+	if config != nil && (config.MaxOpenSuggestions > 0 || config.SuggestionCooldown > 0) {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Suggestion Quota",
+			Value:  h.suggestionQuotaDisplay(s, i, config),
+			Inline: true,
+		})
+	}
+
+	if config != nil && config.VetoesPerSeason > 0 {
+		used, _ := h.db.GetUserVetoCount(guildID, i.Member.User.ID)
+		left := config.VetoesPerSeason - used
+		if left < 0 {
+			left = 0
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Vetoes This Season",
+			Value:  fmt.Sprintf("%d used · %d of %d left", used, left, config.VetoesPerSeason),
+			Inline: true,
+		})
+	}
+
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -37,4 +66,33 @@ func (h *Handlers) HandleMyStats(s *discordgo.Session, i *discordgo.InteractionC
 			Flags:  discordgo.MessageFlagsEphemeral,
 		},
 	})
+}
+
+// suggestionQuotaDisplay mostra quantas sugestões o membro ainda pode abrir e quando pode sugerir de novo
+func (h *Handlers) suggestionQuotaDisplay(s Responder, i *discordgo.InteractionCreate, config *database.GuildConfig) string {
+	if isAdministrator(s, i) {
+		return "Unlimited (administrators are exempt)"
+	}
+
+	open, lastSuggestedAt, err := h.db.GetUserSuggestionActivity(i.GuildID, i.Member.User.ID)
+	if err != nil {
+		return "Unavailable"
+	}
+
+	var lines []string
+	if config.MaxOpenSuggestions > 0 {
+		left := config.MaxOpenSuggestions - open
+		if left < 0 {
+			left = 0
+		}
+		lines = append(lines, fmt.Sprintf("%d of %d open suggestions left", left, config.MaxOpenSuggestions))
+	}
+	if config.SuggestionCooldown > 0 {
+		if next := nextSuggestionAt(config, lastSuggestedAt); time.Now().Before(next) {
+			lines = append(lines, fmt.Sprintf("Next suggestion <t:%d:R>", next.Unix()))
+		} else {
+			lines = append(lines, "Next suggestion: available now")
+		}
+	}
+	return strings.Join(lines, "\n")
 }
\ No newline at end of file
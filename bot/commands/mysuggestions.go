@@ -9,7 +9,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func (h *Handlers) HandleMySuggestions(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleMySuggestions(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -40,24 +40,14 @@ func (h *Handlers) HandleMySuggestions(s *discordgo.Session, i *discordgo.Intera
 	h.showSuggestionPage(s, i, suggestions, 0)
 }
 
-func (h *Handlers) showSuggestionPage(s *discordgo.Session, i *discordgo.InteractionCreate, suggestions []database.Suggestion, currentIndex int) {
+func (h *Handlers) showSuggestionPage(s Responder, i *discordgo.InteractionCreate, suggestions []database.Suggestion, currentIndex int) {
 	if currentIndex < 0 || currentIndex >= len(suggestions) {
 		return
 	}
 
 	movie := suggestions[currentIndex]
 
-	tmdbMovie, _ := h.tmdb.GetMovieByID(movie.TMDBID)
-
-	statusEmoji := "⏳"
-	statusText := "Not selected yet"
-	embedColor := 0x3498DB
-
-	if movie.IsSelected {
-		statusEmoji = "✅"
-		statusText = "Already selected"
-		embedColor = 0x2ECC71
-	}
+	statusEmoji, statusText, embedColor := suggestionStatusDisplay(movie)
 
 	embed := &discordgo.MessageEmbed{
 		Title:       fmt.Sprintf("%s %s (%s)", statusEmoji, movie.MovieName, movie.ReleaseYear),
@@ -67,25 +57,27 @@ func (h *Handlers) showSuggestionPage(s *discordgo.Session, i *discordgo.Interac
 			{Name: "⭐ Rating", Value: fmt.Sprintf("%.1f/10", movie.Rating), Inline: true},
 			{Name: "🎭 Genres", Value: movie.Genres, Inline: true},
 			{Name: "📅 Year", Value: movie.ReleaseYear, Inline: true},
+			{Name: "🗳️ Votes", Value: formatVotes(movie.Upvotes, movie.Downvotes), Inline: false},
 		},
 		Footer: &discordgo.MessageEmbedFooter{
 			Text: fmt.Sprintf("Page %d of %d", currentIndex+1, len(suggestions)),
 		},
 	}
 
-	if tmdbMovie != nil {
-		posterURL := h.tmdb.GetPosterURL(tmdbMovie.PosterPath)
-		if posterURL != "" {
-			embed.Image = &discordgo.MessageEmbedImage{URL: posterURL}
-		}
+	if field := rejectionField(movie); field != nil {
+		embed.Fields = append(embed.Fields, field)
+	}
+
+	if posterURL := h.tmdb.GetPosterURL(movie.PosterPath); posterURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: posterURL}
+	}
 
-		if tmdbMovie.Overview != "" {
-			overview := tmdbMovie.Overview
-			if len(overview) > 200 {
-				overview = overview[:200] + "..."
-			}
-			embed.Description = fmt.Sprintf("%s\n\n%s", embed.Description, overview)
+	if movie.Overview != "" {
+		overview := movie.Overview
+		if len(overview) > 200 {
+			overview = overview[:200] + "..."
 		}
+		embed.Description = fmt.Sprintf("%s\n\n%s", embed.Description, overview)
 	}
 
 	components := []discordgo.MessageComponent{}
@@ -126,7 +118,7 @@ func (h *Handlers) showSuggestionPage(s *discordgo.Session, i *discordgo.Interac
 	})
 }
 
-func (h *Handlers) HandleMySuggestionsPrev(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleMySuggestionsPrev(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		return
@@ -160,7 +152,7 @@ func (h *Handlers) HandleMySuggestionsPrev(s *discordgo.Session, i *discordgo.In
 	h.showSuggestionPage(s, i, suggestions, newIndex)
 }
 
-func (h *Handlers) HandleMySuggestionsNext(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleMySuggestionsNext(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		return
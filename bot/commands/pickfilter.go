@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"clapper/database"
+	"clapper/tmdb"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// genreChoices lista no autocomplete do /pickmovie os gêneros de filme com o nome no idioma do servidor
+func (h *Handlers) genreChoices(guildID, prefix string) []*discordgo.ApplicationCommandOptionChoice {
+	language := h.guildLanguage(guildID)
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, genre := range tmdb.MovieGenres() {
+		name := h.tmdb.FormatGenres(language, []int{genre.ID})
+		if !strings.Contains(strings.ToLower(name), prefix) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  name,
+			Value: strconv.Itoa(genre.ID),
+		})
+	}
+	sort.Slice(choices, func(a, b int) bool { return choices[a].Name < choices[b].Name })
+
+	if len(choices) > maxAutocompleteChoices {
+		choices = choices[:maxAutocompleteChoices]
+	}
+	return choices
+}
+
+// parseGenreID lê o gênero escolhido no autocomplete; texto livre ou um ID que não é de gênero de filme não é aceito
+func parseGenreID(value string) (int, bool) {
+	id, err := strconv.Atoi(value)
+	if err != nil || tmdb.GenreName(id) == "" {
+		return 0, false
+	}
+	return id, true
+}
+
+// guildLanguage devolve o idioma do TMDB configurado no servidor; "" segue o idioma padrão do bot
+func (h *Handlers) guildLanguage(guildID string) string {
+	config, err := h.db.GetGuildConfig(guildID)
+	if err != nil || config == nil {
+		return ""
+	}
+	return config.Language
+}
+
+func pickFilterFromOptions(options []*discordgo.ApplicationCommandInteractionDataOption) database.MovieFilter {
+	var filter database.MovieFilter
+	for _, opt := range options {
+		switch opt.Name {
+		case "genre":
+			filter.GenreID, _ = parseGenreID(opt.StringValue())
+		case "min_year":
+			filter.MinYear = int(opt.IntValue())
+		case "max_year":
+			filter.MaxYear = int(opt.IntValue())
+		case "max_runtime":
+			filter.MaxRuntime = int(opt.IntValue())
+		case "min_tmdb_rating":
+			filter.MinTMDBRating = roundRating(opt.FloatValue())
+		case "exclude_suggester":
+			filter.ExcludeUserID = opt.UserValue(nil).ID
+		}
+	}
+	return filter
+}
+
+// maxCustomIDLength é o limite do Discord para o custom_id de componentes
+const maxCustomIDLength = 100
+
+// roundRating deixa a nota mínima com uma casa decimal, a mesma precisão mostrada e guardada no botão
+func roundRating(rating float64) float64 {
+	return math.Round(rating*10) / 10
+}
+
+// encodePickFilter serializa o filtro de forma compacta para caber no custom_id do botão de reroll
+// (limite de 100 caracteres do Discord), ex.: "g27,y1980,Y1999,r120,t7.5,x1234"
+func encodePickFilter(filter database.MovieFilter) string {
+	var parts []string
+	if filter.GenreID > 0 {
+		parts = append(parts, fmt.Sprintf("g%d", filter.GenreID))
+	}
+	if filter.MinYear > 0 {
+		parts = append(parts, fmt.Sprintf("y%d", filter.MinYear))
+	}
+	if filter.MaxYear > 0 {
+		parts = append(parts, fmt.Sprintf("Y%d", filter.MaxYear))
+	}
+	if filter.MaxRuntime > 0 {
+		parts = append(parts, fmt.Sprintf("r%d", filter.MaxRuntime))
+	}
+	if filter.MinTMDBRating > 0 {
+		parts = append(parts, "t"+strconv.FormatFloat(roundRating(filter.MinTMDBRating), 'f', -1, 64))
+	}
+	if filter.ExcludeUserID != "" {
+		parts = append(parts, "x"+filter.ExcludeUserID)
+	}
+	return strings.Join(parts, ",")
+}
+
+func decodePickFilter(encoded string) database.MovieFilter {
+	var filter database.MovieFilter
+	for _, part := range strings.Split(encoded, ",") {
+		if len(part) < 2 {
+			continue
+		}
+		value := part[1:]
+		switch part[0] {
+		case 'g':
+			filter.GenreID, _ = strconv.Atoi(value)
+		case 'y':
+			filter.MinYear, _ = strconv.Atoi(value)
+		case 'Y':
+			filter.MaxYear, _ = strconv.Atoi(value)
+		case 'r':
+			filter.MaxRuntime, _ = strconv.Atoi(value)
+		case 't':
+			filter.MinTMDBRating, _ = strconv.ParseFloat(value, 64)
+		case 'x':
+			filter.ExcludeUserID = value
+		}
+	}
+	return filter
+}
+
+// describePickFilter resume os filtros do sorteio, com o gênero no idioma do servidor
+func (h *Handlers) describePickFilter(language string, filter database.MovieFilter) string {
+	var parts []string
+	if filter.GenreID > 0 {
+		parts = append(parts, fmt.Sprintf("Genre: %s", h.tmdb.FormatGenres(language, []int{filter.GenreID})))
+	}
+	if filter.MinYear > 0 && filter.MaxYear > 0 {
+		parts = append(parts, fmt.Sprintf("Years: %d–%d", filter.MinYear, filter.MaxYear))
+	} else if filter.MinYear > 0 {
+		parts = append(parts, fmt.Sprintf("Years: %d or later", filter.MinYear))
+	} else if filter.MaxYear > 0 {
+		parts = append(parts, fmt.Sprintf("Years: %d or earlier", filter.MaxYear))
+	}
+	if filter.MaxRuntime > 0 {
+		parts = append(parts, fmt.Sprintf("Runtime: up to %d min", filter.MaxRuntime))
+	}
+	if filter.MinTMDBRating > 0 {
+		parts = append(parts, fmt.Sprintf("TMDB rating: %.1f+", filter.MinTMDBRating))
+	}
+	if filter.ExcludeUserID != "" {
+		parts = append(parts, fmt.Sprintf("Excluding suggestions by <@%s>", filter.ExcludeUserID))
+	}
+	return strings.Join(parts, "\n")
+}
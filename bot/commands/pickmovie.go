@@ -1,54 +1,123 @@
 package commands
 
 import (
+	"clapper/database"
+	"errors"
 	"fmt"
+	"log"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func (h *Handlers) HandlePickMovie(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	guildID := i.GuildID
-	if guildID == "" {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "❌ This command can only be used in a server.",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
-		return
+const (
+	pickModeRandom = "random"
+	pickModeVotes  = "votes"
+)
+
+// pickStrategyNames descreve as estratégias de sorteio aceitas em /setup picking
+var pickStrategyNames = map[string]string{
+	database.PickStrategyUniform:        "Uniform (every suggestion has the same odds)",
+	database.PickStrategyRoundRobin:     "Fair rotation between suggesters",
+	database.PickStrategyWeightedByWait: "Weighted by wait (older suggestions have better odds)",
+}
+
+// pickStrategy devolve a estratégia do modo aleatório configurada para o servidor; sem configuração vale a uniforme
+func (h *Handlers) pickStrategy(guildID string) string {
+	config, err := h.db.GetGuildConfig(guildID)
+	if err != nil || config == nil {
+		return database.PickStrategyUniform
+	}
+	if _, ok := pickStrategyNames[config.PickStrategy]; !ok {
+		return database.PickStrategyUniform
 	}
+	return config.PickStrategy
+}
 
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
-	})
+// drawMovie sorteia um filme não selecionado de acordo com o modo escolhido no /pickmovie.
+// O modo por votos ignora a estratégia; o modo aleatório segue a estratégia do servidor.
+func (h *Handlers) drawMovie(guildID, mode, strategy string, filter database.MovieFilter) (*database.MovieResult, error) {
+	if mode == pickModeVotes {
+		return h.db.GetVoteWeightedRandomMovie(guildID, filter)
+	}
 
-	movie, err := h.db.GetRandomMovie(guildID)
-	if err != nil || movie == nil {
-		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: ptrString("❌ No available movies to pick! All suggestions have been selected or there are no suggestions yet."),
-		})
-		return
+	switch strategy {
+	case database.PickStrategyRoundRobin:
+		return h.db.GetRoundRobinRandomMovie(guildID, filter)
+	case database.PickStrategyWeightedByWait:
+		return h.db.GetWaitWeightedRandomMovie(guildID, filter)
 	}
+	return h.db.GetRandomMovie(guildID, filter)
+}
+
+// pickButtonID monta o custom ID dos botões de reroll e veto, que carregam o modo e os filtros do sorteio original
+func pickButtonID(action, guildID string, movieID int, mode string, filter database.MovieFilter) string {
+	customID := fmt.Sprintf("%s_movie_%s_%d_%s", action, guildID, movieID, mode)
+	if filter.IsEmpty() {
+		return customID
+	}
+
+	// Com os limites das opções do /pickmovie o filtro sempre cabe; se um dia não couber, o botão perde o filtro em vez de quebrar a mensagem
+	withFilter := customID + "_" + encodePickFilter(filter)
+	if len(withFilter) > maxCustomIDLength {
+		log.Printf("Filtro do sorteio não cabe no custom ID %q; o botão seguirá sem ele", withFilter)
+		return customID
+	}
+	return withFilter
+}
+
+// pickDescription explica como o título foi sorteado; noun é "movie" ou "TV series", conforme mediaNoun
+func pickDescription(serverName, mode, strategy, noun string) string {
+	if mode == pickModeVotes {
+		return fmt.Sprintf("This %s has been drawn for %s, weighted by community votes!\n\nHosts can reroll or confirm the selection.", noun, serverName)
+	}
+	switch strategy {
+	case database.PickStrategyRoundRobin:
+		return fmt.Sprintf("This %s has been picked for %s by fair rotation between suggesters!\n\nHosts can reroll or confirm the selection.", noun, serverName)
+	case database.PickStrategyWeightedByWait:
+		return fmt.Sprintf("This %s has been drawn for %s, favoring suggestions that have waited the longest!\n\nHosts can reroll or confirm the selection.", noun, serverName)
+	}
+	return fmt.Sprintf("This %s has been randomly selected for %s!\n\nHosts can reroll or confirm the selection.", noun, serverName)
+}
 
+// parsePickButtonID lê o filme, o modo e os filtros de um botão de reroll ou veto.
+// Botões antigos não têm modo nem filtros; nesse caso o sorteio é uniforme e sem filtros.
+func parsePickButtonID(customID string) (movieID int, mode string, filter database.MovieFilter) {
+	mode = pickModeRandom
+	re := regexp.MustCompile(`(?:reroll|veto)_movie_[^_]+_(\d+)(?:_([a-z]+)(?:_(.*))?)?`)
+	if matches := re.FindStringSubmatch(customID); len(matches) > 3 {
+		movieID, _ = strconv.Atoi(matches[1])
+		if matches[2] != "" {
+			mode = matches[2]
+		}
+		filter = decodePickFilter(matches[3])
+	}
+	return movieID, mode, filter
+}
+
+// pickEmbed monta o embed de um filme sorteado; o rodapé fica a cargo de quem chama
+func (h *Handlers) pickEmbed(s Responder, guildID string, movie *database.MovieResult, mode, strategy string, filter database.MovieFilter) *discordgo.MessageEmbed {
 	serverName := "Server"
 	guild, err := s.Guild(guildID)
 	if err == nil {
 		serverName = guild.Name
 	}
 
-	tmdbMovie, _ := h.tmdb.GetMovieByID(movie.TMDBID)
-
 	totalSuggestions, _ := h.db.GetAllSuggestionsCount(guildID)
 	selectedCount, _ := h.db.GetSelectedMoviesCount(guildID)
 	remaining := totalSuggestions - selectedCount
 
+	title := fmt.Sprintf("🎬 Movie Suggestion: %s (%s)", movie.MovieName, movie.ReleaseYear)
+	if movie.IsTV() {
+		title = fmt.Sprintf("📺 TV Series Suggestion: %s (%s)", movie.MovieName, movie.ReleaseYear)
+	}
+
 	embed := &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("🎬 Movie Suggestion: %s (%s)", movie.MovieName, movie.ReleaseYear),
-		Description: fmt.Sprintf("This movie has been randomly selected for %s!\n\nAdmins can reroll or confirm the selection.", serverName),
+		Title:       title,
+		Description: pickDescription(serverName, mode, strategy, mediaNoun(movie.MediaType)),
 		Color:       0xFFD700,
 		Timestamp:   time.Now().Format(time.RFC3339),
 		Fields: []*discordgo.MessageEmbedField{
@@ -58,67 +127,111 @@ func (h *Handlers) HandlePickMovie(s *discordgo.Session, i *discordgo.Interactio
 			{Name: "👤 Suggested by", Value: movie.Username, Inline: false},
 			{Name: "📈 Progress", Value: fmt.Sprintf("%d/%d movies selected (%d remaining)", selectedCount, totalSuggestions, remaining), Inline: false},
 		},
-		Footer: &discordgo.MessageEmbedFooter{
-			Text:    fmt.Sprintf("Picked by %s", i.Member.User.Username),
-			IconURL: i.Member.User.AvatarURL(""),
-		},
 	}
 
-	if tmdbMovie != nil {
-		posterURL := h.tmdb.GetPosterURL(tmdbMovie.PosterPath)
-		if posterURL != "" {
-			embed.Image = &discordgo.MessageEmbedImage{URL: posterURL}
-		}
+	if !filter.IsEmpty() {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "🔎 Filters", Value: h.describePickFilter(h.guildLanguage(guildID), filter), Inline: false})
 	}
 
-	components := []discordgo.MessageComponent{
-		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
-				discordgo.Button{
-					Label:    "Reroll",
-					Style:    discordgo.SecondaryButton,
-					CustomID: fmt.Sprintf("reroll_movie_%s_%d", guildID, movie.ID),
-					Emoji: &discordgo.ComponentEmoji{
-						Name: "🔄",
-					},
-				},
-				discordgo.Button{
-					Label:    "Confirm Selection",
-					Style:    discordgo.SuccessButton,
-					CustomID: fmt.Sprintf("confirm_movie_%s_%d", guildID, movie.ID),
-					Emoji: &discordgo.ComponentEmoji{
-						Name: "✅",
-					},
-				},
+	if posterURL := h.tmdb.GetPosterURL(movie.PosterPath); posterURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: posterURL}
+	}
+
+	return embed
+}
+
+// pickComponents monta os botões do sorteio; o veto só aparece quando o servidor distribui vetos
+func (h *Handlers) pickComponents(guildID string, movieID int, mode string, filter database.MovieFilter) []discordgo.MessageComponent {
+	buttons := []discordgo.MessageComponent{
+		discordgo.Button{
+			Label:    "Reroll",
+			Style:    discordgo.SecondaryButton,
+			CustomID: pickButtonID("reroll", guildID, movieID, mode, filter),
+			Emoji: &discordgo.ComponentEmoji{
+				Name: "🔄",
+			},
+		},
+		discordgo.Button{
+			Label:    "Confirm Selection",
+			Style:    discordgo.SuccessButton,
+			CustomID: fmt.Sprintf("confirm_movie_%s_%d", guildID, movieID),
+			Emoji: &discordgo.ComponentEmoji{
+				Name: "✅",
 			},
 		},
 	}
 
-	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Embeds:     &[]*discordgo.MessageEmbed{embed},
-		Components: &components,
-	})
+	if config, err := h.db.GetGuildConfig(guildID); err == nil && config != nil && config.VetoesPerSeason > 0 {
+		buttons = append(buttons, discordgo.Button{
+			Label:    "Veto",
+			Style:    discordgo.DangerButton,
+			CustomID: pickButtonID("veto", guildID, movieID, mode, filter),
+			Emoji: &discordgo.ComponentEmoji{
+				Name: "🚫",
+			},
+		})
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: buttons},
+	}
 }
 
-func (h *Handlers) HandleRerollMovie(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandlePickMovie(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This command can only be used in a server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
 		return
 	}
 
-	isAdmin := false
-	if i.Member != nil {
-		perms, err := s.UserChannelPermissions(i.Member.User.ID, i.ChannelID)
-		if err == nil {
-			isAdmin = perms&discordgo.PermissionAdministrator != 0
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can pick movies!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	mode := pickModeRandom
+	options := i.ApplicationCommandData().Options
+	genre := ""
+	for _, opt := range options {
+		switch opt.Name {
+		case "mode":
+			mode = opt.StringValue()
+		case "genre":
+			genre = opt.StringValue()
 		}
 	}
+	filter := pickFilterFromOptions(options)
 
-	if !isAdmin {
+	// O gênero precisa vir da lista do autocomplete; um texto qualquer seria ignorado em silêncio
+	if _, ok := parseGenreID(genre); genre != "" && !ok {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "❌ Only administrators can reroll movies!",
+				Content: "❌ Choose a genre from the list shown while typing `genre`!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Um intervalo invertido nunca encontraria filmes; melhor avisar do que dizer que nada combina
+	if filter.MinYear > 0 && filter.MaxYear > 0 && filter.MinYear > filter.MaxYear {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("❌ `min_year` (%d) can't be later than `max_year` (%d)!", filter.MinYear, filter.MaxYear),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -126,78 +239,83 @@ func (h *Handlers) HandleRerollMovie(s *discordgo.Session, i *discordgo.Interact
 	}
 
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 	})
 
-	movie, err := h.db.GetRandomMovie(guildID)
+	strategy := h.pickStrategy(guildID)
+	movie, err := h.drawMovie(guildID, mode, strategy, filter)
 	if err != nil || movie == nil {
+		msg := "❌ No available movies to pick! All suggestions have been selected or there are no suggestions yet."
+		if !filter.IsEmpty() {
+			msg = "❌ No available movies match these filters! Try loosening them."
+		}
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content:    ptrString("❌ No more available movies to pick!"),
-			Embeds:     &[]*discordgo.MessageEmbed{},
-			Components: &[]discordgo.MessageComponent{},
+			Content: ptrString(msg),
 		})
 		return
 	}
 
-	serverName := "Server"
-	guild, err := s.Guild(guildID)
-	if err == nil {
-		serverName = guild.Name
+	embed := h.pickEmbed(s, guildID, movie, mode, strategy, filter)
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text:    fmt.Sprintf("Picked by %s", i.Member.User.Username),
+		IconURL: i.Member.User.AvatarURL(""),
 	}
+	components := h.pickComponents(guildID, movie.ID, mode, filter)
 
-	tmdbMovie, _ := h.tmdb.GetMovieByID(movie.TMDBID)
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	})
+}
 
-	totalSuggestions, _ := h.db.GetAllSuggestionsCount(guildID)
-	selectedCount, _ := h.db.GetSelectedMoviesCount(guildID)
-	remaining := totalSuggestions - selectedCount
+func (h *Handlers) HandleRerollMovie(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		return
+	}
 
-	embed := &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("🎬 Movie Suggestion: %s (%s)", movie.MovieName, movie.ReleaseYear),
-		Description: fmt.Sprintf("This movie has been randomly selected for %s!\n\nAdmins can reroll or confirm the selection.", serverName),
-		Color:       0xFFD700,
-		Timestamp:   time.Now().Format(time.RFC3339),
-		Fields: []*discordgo.MessageEmbedField{
-			{Name: "⭐ Rating", Value: fmt.Sprintf("%.1f/10", movie.Rating), Inline: true},
-			{Name: "🎭 Genres", Value: movie.Genres, Inline: true},
-			{Name: "📅 Year", Value: movie.ReleaseYear, Inline: true},
-			{Name: "👤 Suggested by", Value: movie.Username, Inline: false},
-			{Name: "📈 Progress", Value: fmt.Sprintf("%d/%d movies selected (%d remaining)", selectedCount, totalSuggestions, remaining), Inline: false},
-		},
-		Footer: &discordgo.MessageEmbedFooter{
-			Text:    fmt.Sprintf("Rerolled by %s", i.Member.User.Username),
-			IconURL: i.Member.User.AvatarURL(""),
-		},
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can reroll movies!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
 	}
 
-	if tmdbMovie != nil {
-		posterURL := h.tmdb.GetPosterURL(tmdbMovie.PosterPath)
-		if posterURL != "" {
-			embed.Image = &discordgo.MessageEmbedImage{URL: posterURL}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+
+	_, mode, filter := parsePickButtonID(i.MessageComponentData().CustomID)
+
+	strategy := h.pickStrategy(guildID)
+	movie, err := h.drawMovie(guildID, mode, strategy, filter)
+	if err != nil || movie == nil {
+		msg := "❌ No more available movies to pick!"
+		if !filter.IsEmpty() {
+			msg = "❌ No more available movies match these filters!"
 		}
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    ptrString(msg),
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
+		})
+		return
 	}
 
-	components := []discordgo.MessageComponent{
-		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
-				discordgo.Button{
-					Label:    "Reroll",
-					Style:    discordgo.SecondaryButton,
-					CustomID: fmt.Sprintf("reroll_movie_%s_%d", guildID, movie.ID),
-					Emoji: &discordgo.ComponentEmoji{
-						Name: "🔄",
-					},
-				},
-				discordgo.Button{
-					Label:    "Confirm Selection",
-					Style:    discordgo.SuccessButton,
-					CustomID: fmt.Sprintf("confirm_movie_%s_%d", guildID, movie.ID),
-					Emoji: &discordgo.ComponentEmoji{
-						Name: "✅",
-					},
-				},
-			},
-		},
+	embed := h.pickEmbed(s, guildID, movie, mode, strategy, filter)
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text:    fmt.Sprintf("Rerolled by %s", i.Member.User.Username),
+		IconURL: i.Member.User.AvatarURL(""),
+	}
+	// O histórico de vetos acompanha os rerolls do mesmo sorteio
+	if field := vetoField(i.Message); field != nil {
+		embed.Fields = append(embed.Fields, field)
 	}
+	components := h.pickComponents(guildID, movie.ID, mode, filter)
 
 	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 		Embeds:     &[]*discordgo.MessageEmbed{embed},
@@ -205,25 +323,86 @@ func (h *Handlers) HandleRerollMovie(s *discordgo.Session, i *discordgo.Interact
 	})
 }
 
-func (h *Handlers) HandleConfirmMovie(s *discordgo.Session, i *discordgo.InteractionCreate) {
+// vetoCount escreve a quantidade de vetos; "veto" não segue o plural regular de pluralize
+func vetoCount(count int) string {
+	if count == 1 {
+		return "1 veto"
+	}
+	return fmt.Sprintf("%d vetoes", count)
+}
+
+const vetoFieldName = "🚫 Vetoes"
+
+// vetoField devolve o histórico de vetos do embed do sorteio, se houver
+func vetoField(message *discordgo.Message) *discordgo.MessageEmbedField {
+	if message == nil || len(message.Embeds) == 0 {
+		return nil
+	}
+	for _, field := range message.Embeds[0].Fields {
+		if field.Name == vetoFieldName {
+			return field
+		}
+	}
+	return nil
+}
+
+// HandleVetoMovie gasta um veto de quem clicou e sorteia outro filme no lugar, com o mesmo modo e filtros
+func (h *Handlers) HandleVetoMovie(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
-	if guildID == "" {
+	if guildID == "" || i.Member == nil {
 		return
 	}
 
-	isAdmin := false
-	if i.Member != nil {
-		perms, err := s.UserChannelPermissions(i.Member.User.ID, i.ChannelID)
-		if err == nil {
-			isAdmin = perms&discordgo.PermissionAdministrator != 0
-		}
+	config, err := h.db.GetGuildConfig(guildID)
+	if err != nil || config == nil || config.VetoesPerSeason <= 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Vetoes are not enabled on this server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
 	}
 
-	if !isAdmin {
+	movieID, mode, filter := parsePickButtonID(i.MessageComponentData().CustomID)
+
+	vetoed, err := h.db.GetMovieByID(movieID)
+	if err != nil || vetoed == nil || vetoed.GuildID != guildID {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "❌ Only administrators can confirm movie selections!",
+				Content: "❌ This suggestion no longer exists.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if selected, _ := h.db.GetSelectedMovie(guildID, movieID); selected != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This movie has already been confirmed and can no longer be vetoed.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	remainingVetoes, err := h.db.SaveVeto(guildID, movieID, i.Member.User.ID, i.Member.User.Username, config.BanVetoed, config.VetoesPerSeason, filter)
+	if err != nil {
+		msg := "❌ An error occurred while saving your veto. Please try again."
+		switch {
+		case errors.Is(err, database.ErrNoVetoesLeft):
+			msg = fmt.Sprintf("❌ You have already used all %d of your vetoes this season!", config.VetoesPerSeason)
+		case errors.Is(err, database.ErrNoReplacement):
+			msg = fmt.Sprintf("❌ There are no other movies left to pick instead of **%s**, so your veto was not used.", vetoed.MovieName)
+		}
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: msg,
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -234,34 +413,148 @@ func (h *Handlers) HandleConfirmMovie(s *discordgo.Session, i *discordgo.Interac
 		Type: discordgo.InteractionResponseDeferredMessageUpdate,
 	})
 
+	// Sem banimento o filme vetado continua no sorteio, mas não pode voltar neste mesmo reroll
+	strategy := h.pickStrategy(guildID)
+	drawFilter := filter
+	drawFilter.ExcludeSuggestionID = movieID
+	movie, err := h.drawMovie(guildID, mode, strategy, drawFilter)
+	if err != nil || movie == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    ptrString(fmt.Sprintf("🚫 **%s** was vetoed by %s, and there are no other movies left to pick!", vetoed.MovieName, i.Member.User.Username)),
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
+		})
+		return
+	}
+
+	line := fmt.Sprintf("~~%s (%s)~~ vetoed by %s", vetoed.MovieName, vetoed.ReleaseYear, i.Member.User.Username)
+	if config.BanVetoed {
+		line += " · banned for the season"
+	}
+	line += fmt.Sprintf(" · %s left", vetoCount(remainingVetoes))
+
+	history := line
+	if field := vetoField(i.Message); field != nil {
+		history = field.Value + "\n" + line
+	}
+
+	embed := h.pickEmbed(s, guildID, movie, mode, strategy, filter)
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: vetoFieldName, Value: truncate(history, 1024), Inline: false})
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text:    fmt.Sprintf("Rerolled after a veto by %s", i.Member.User.Username),
+		IconURL: i.Member.User.AvatarURL(""),
+	}
+	components := h.pickComponents(guildID, movie.ID, mode, filter)
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	})
+}
+
+func (h *Handlers) HandleConfirmMovie(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		return
+	}
+
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can confirm movie selections!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
 	customID := i.MessageComponentData().CustomID
 	re := regexp.MustCompile(`confirm_movie_([^_]+)_(\d+)`)
 	matches := re.FindStringSubmatch(customID)
 
-	if len(matches) < 3 {
-		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: ptrString("❌ An error occurred. Please try again."),
+	if len(matches) < 3 || matches[1] != guildID {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This selection is for a different server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
 		})
 		return
 	}
 
-	extractedGuildID := matches[1]
-	movieID, _ := strconv.Atoi(matches[2])
+	guildConfig, _ := h.db.GetGuildConfig(guildID)
 
-	// Verificação de segurança: guild do botão deve corresponder à guild atual
-	if extractedGuildID != guildID {
-		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: ptrString("❌ This selection is for a different server."),
+	// A confirmação abre um formulário pedindo a data da sessão antes de marcar o filme como selecionado
+	s.InteractionRespond(i.Interaction, screeningModal(fmt.Sprintf("confirm_modal_%s_%s", guildID, matches[2]), guildLocation(guildConfig), ""))
+}
+
+func (h *Handlers) HandleConfirmMovieSubmit(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		return
+	}
+
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can confirm movie selections!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
 		})
 		return
 	}
 
+	data := i.ModalSubmitData()
+	re := regexp.MustCompile(`confirm_modal_([^_]+)_(\d+)`)
+	matches := re.FindStringSubmatch(data.CustomID)
+
+	if len(matches) < 3 || matches[1] != guildID {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This selection is for a different server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	movieID, _ := strconv.Atoi(matches[2])
+
+	guildConfig, _ := h.db.GetGuildConfig(guildID)
+	loc := guildLocation(guildConfig)
+
+	// Valida a data antes de tocar na mensagem, para que o admin possa tentar de novo
+	var when *time.Time
+	if input := strings.TrimSpace(modalValue(data, "when")); input != "" {
+		parsed, err := parseScreeningTime(input, loc)
+		if err != nil {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: fmt.Sprintf("❌ Could not read \"%s\": %s (timezone: %s). Press **Confirm Selection** again to retry.", input, err, loc),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+		when = &parsed
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+
 	if err := h.db.MarkMovieSelected(guildID, movieID); err != nil {
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 			Content: ptrString("❌ An error occurred while confirming the movie. Please try again."),
 		})
 		return
 	}
+	h.syncSuggestionPosts(s, guildID, movieID)
 
 	movie, err := h.db.GetMovieByID(movieID)
 	if err != nil || movie == nil {
@@ -281,8 +574,6 @@ func (h *Handlers) HandleConfirmMovie(s *discordgo.Session, i *discordgo.Interac
 	selectedCount, _ := h.db.GetSelectedMoviesCount(guildID)
 	remaining := totalSuggestions - selectedCount
 
-	tmdbMovie, _ := h.tmdb.GetMovieByID(movie.TMDBID)
-
 	embed := &discordgo.MessageEmbed{
 		Title:       fmt.Sprintf("🎉 Selected Movie: %s (%s)", movie.MovieName, movie.ReleaseYear),
 		Description: fmt.Sprintf("This movie has been confirmed for %s!", serverName),
@@ -301,15 +592,32 @@ func (h *Handlers) HandleConfirmMovie(s *discordgo.Session, i *discordgo.Interac
 		},
 	}
 
-	if tmdbMovie != nil {
-		posterURL := h.tmdb.GetPosterURL(tmdbMovie.PosterPath)
-		if posterURL != "" {
-			embed.Image = &discordgo.MessageEmbedImage{URL: posterURL}
+	if when != nil && guildConfig != nil {
+		value := fmt.Sprintf("<t:%d:F> (<t:%d:R>)", when.Unix(), when.Unix())
+		eventID, err := h.scheduleScreening(s, guildConfig, movie, *when)
+		if err != nil {
+			value += "\n⚠️ Could not create the Discord event. Check the bot's permissions and the event channel in `/setup channels`."
+		} else if eventID != "" {
+			value += fmt.Sprintf("\n[View event](https://discord.com/events/%s/%s)", guildID, eventID)
 		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "🗓️ Movie Night", Value: value, Inline: false})
 	}
 
-	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+	if posterURL := h.tmdb.GetPosterURL(movie.PosterPath); posterURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: posterURL}
+	}
+
+	components := rateComponents(guildID, movie.ID)
+	confirmation, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 		Embeds:     &[]*discordgo.MessageEmbed{embed},
-		Components: &[]discordgo.MessageComponent{},
+		Components: &components,
 	})
-}
\ No newline at end of file
+	if err != nil || guildConfig == nil {
+		return
+	}
+
+	// O tópico de discussão é um extra: se falhar, o filme continua confirmado
+	if err := h.openDiscussion(s, guildConfig, movie, confirmation); err != nil {
+		log.Printf("Erro ao abrir o tópico de discussão de %s: %v", movie.MovieName, err)
+	}
+}
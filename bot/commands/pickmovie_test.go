@@ -0,0 +1,464 @@
+package commands
+
+import (
+	"clapper/database"
+	"clapper/tmdb"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPickMovie(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name:  "pickmovie requires the host permission",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "pickmovie")
+			},
+			want: []string{"Only administrators and movie night hosts can pick movies"},
+		},
+		{
+			name:  "pickmovie without suggestions",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie")
+			},
+			want: []string{"No available movies to pick"},
+		},
+		{
+			name: "pickmovie draws an unselected movie",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.selectMovie(movieAlien, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie")
+			},
+			want: []string{"Movie Suggestion: Heat (1995)", "1/2 movies selected (1 remaining)"},
+			check: func(t *testing.T, env *testEnv) {
+				want := []string{
+					fmt.Sprintf("reroll_movie_%s_%s_random", testGuildID, env.ref(movieHeat)),
+					fmt.Sprintf("confirm_movie_%s_%s", testGuildID, env.ref(movieHeat)),
+				}
+				if ids := env.responder.customIDs(); strings.Join(ids, ",") != strings.Join(want, ",") {
+					t.Errorf("custom IDs = %v, want %v", ids, want)
+				}
+			},
+		},
+		{
+			name: "pickmovie words TV series picks as series",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(tmdb.Movie{ID: 87108, MediaType: tmdb.MediaTV, Title: "Chernobyl", ReleaseDate: "2019-05-06"}, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie")
+			},
+			want: []string{"📺 TV Series Suggestion: Chernobyl (2019)", "This TV series has been randomly selected"},
+		},
+		{
+			name: "pickmovie by votes favours the most voted movie",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				id := env.suggest(movieAlien, testMember)
+				env.store.SaveVote(testGuildID, id, testOther.User.ID, 1)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie", stringOption("mode", pickModeVotes))
+			},
+			want: []string{"Movie Suggestion: Alien (1979)", "weighted by community votes"},
+		},
+		{
+			name: "pickmovie round robin favours suggesters who waited the longest",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SavePickStrategy(testGuildID, database.PickStrategyRoundRobin)
+				env.selectMovie(movieAliens, testMember)
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+				env.suggest(movieParasite, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie")
+			},
+			want: []string{"Movie Suggestion: Parasite (2019)", "fair rotation between suggesters"},
+		},
+		{
+			name: "pickmovie weighted by wait favours old suggestions",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SavePickStrategy(testGuildID, database.PickStrategyWeightedByWait)
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+				env.store.findSuggestion("", env.ids["Alien"]).SuggestedAt = time.Now().AddDate(0, -2, 0)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie")
+			},
+			want: []string{"Movie Suggestion: Alien (1979)", "waited the longest"},
+		},
+		{
+			name: "pickmovie by votes ignores the picking strategy",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SavePickStrategy(testGuildID, database.PickStrategyRoundRobin)
+				env.selectMovie(movieAliens, testMember)
+				env.suggest(movieHeat, testOther)
+				id := env.suggest(movieAlien, testMember)
+				env.store.SaveVote(testGuildID, id, testOther.User.ID, 1)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie", stringOption("mode", pickModeVotes))
+			},
+			want: []string{"Movie Suggestion: Alien (1979)", "weighted by community votes"},
+		},
+		{
+			name: "pickmovie applies filters",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie", stringOption("genre", "80"), intOption("max_runtime", 180))
+			},
+			want: []string{"Movie Suggestion: Heat (1995)", "Genre: Crime"},
+			check: func(t *testing.T, env *testEnv) {
+				if ids := env.responder.customIDs(); len(ids) == 0 || !strings.HasSuffix(ids[0], "_random_g80,r180") {
+					t.Errorf("reroll button should carry the filters, got %v", ids)
+				}
+			},
+		},
+		{
+			name: "pickmovie with filters that match nothing",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie", intOption("max_year", 1980))
+			},
+			want: []string{"No available movies match these filters"},
+		},
+		{
+			name: "pickmovie shows the genre filter in the server language",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveLanguage(testGuildID, "pt-BR")
+				env.handlers.tmdb.LoadGenres(context.Background(), "pt-BR")
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie", stringOption("genre", "28"))
+			},
+			want: []string{"Movie Suggestion: Heat (1995)", "Genre: Ação"},
+		},
+		{
+			name: "pickmovie refuses a genre typed instead of chosen",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie", stringOption("genre", "Crime"))
+			},
+			want: []string{"Choose a genre from the list"},
+			check: func(t *testing.T, env *testEnv) {
+				if count, _ := env.store.GetSelectedMoviesCount(testGuildID); count != 0 || len(env.responder.edits) != 0 {
+					t.Error("nothing should be picked with an unknown genre")
+				}
+			},
+		},
+		{
+			name: "pickmovie refuses a min_year after max_year",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie", intOption("min_year", 2000), intOption("max_year", 1990))
+			},
+			want: []string{"`min_year` (2000) can't be later than `max_year` (1990)"},
+			check: func(t *testing.T, env *testEnv) {
+				if resp := env.responder.responses[0]; resp.Data == nil || resp.Data.Flags != discordgo.MessageFlagsEphemeral {
+					t.Errorf("expected an ephemeral refusal, got %+v", resp)
+				}
+			},
+		},
+		{
+			name: "reroll keeps mode and filters",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, fmt.Sprintf("reroll_movie_%s_%s_random_g80", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"Movie Suggestion: Heat (1995)", "Rerolled by host"},
+		},
+		{
+			name: "reroll follows the picking strategy",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SavePickStrategy(testGuildID, database.PickStrategyRoundRobin)
+				env.selectMovie(movieAliens, testMember)
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieParasite, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, fmt.Sprintf("reroll_movie_%s_%s_random", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"Movie Suggestion: Parasite (2019)", "fair rotation between suggesters"},
+		},
+		{
+			name: "pickmovie shows the veto button only when vetoes are enabled",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveVetoSettings(testGuildID, 1, false)
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "pickmovie")
+			},
+			want: []string{"Movie Suggestion: Heat (1995)"},
+			check: func(t *testing.T, env *testEnv) {
+				want := fmt.Sprintf("veto_movie_%s_%s_random", testGuildID, env.ref(movieHeat))
+				if ids := env.responder.customIDs(); !slices.Contains(ids, want) {
+					t.Errorf("expected a %s button, got %v", want, ids)
+				}
+			},
+		},
+		{
+			name: "veto spends a token and rerolls",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveVetoSettings(testGuildID, 1, false)
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("veto_movie_%s_%s_random", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"Movie Suggestion: Alien (1979)", "~~Heat (1995)~~ vetoed by member", "0 vetoes left", "Rerolled after a veto by member"},
+			check: func(t *testing.T, env *testEnv) {
+				if used, _ := env.store.GetUserVetoCount(testGuildID, testMember.User.ID); used != 1 {
+					t.Errorf("expected one veto to be used, got %d", used)
+				}
+				if movies, _ := env.store.GetRandomMovies(testGuildID, database.MovieFilter{}, 10); len(movies) != 2 {
+					t.Errorf("vetoed movie should stay in the pool without bans, got %d movies", len(movies))
+				}
+			},
+		},
+		{
+			name: "veto keeps the history of earlier vetoes",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveVetoSettings(testGuildID, 2, false)
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				i := buttonPress(testOther, fmt.Sprintf("veto_movie_%s_%s_random", testGuildID, env.ref(movieHeat)))
+				i.Message.Embeds = []*discordgo.MessageEmbed{{Fields: []*discordgo.MessageEmbedField{
+					{Name: vetoFieldName, Value: "~~Parasite (2019)~~ vetoed by member · 1 veto left"},
+				}}}
+				return i
+			},
+			want: []string{"~~Parasite (2019)~~ vetoed by member · 1 veto left\n~~Heat (1995)~~ vetoed by other · 1 veto left"},
+		},
+		{
+			name: "veto bans the movie for the season",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveVetoSettings(testGuildID, 1, true)
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("veto_movie_%s_%s_random", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"Movie Suggestion: Alien (1979)", "banned for the season"},
+			check: func(t *testing.T, env *testEnv) {
+				movies, _ := env.store.GetRandomMovies(testGuildID, database.MovieFilter{}, 10)
+				if len(movies) != 1 || movies[0].MovieName != "Alien" {
+					t.Errorf("banned movie should leave the pool, got %+v", movies)
+				}
+			},
+		},
+		{
+			name: "veto without tokens left",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveVetoSettings(testGuildID, 1, false)
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+				env.store.SaveVeto(testGuildID, env.ids["Alien"], testMember.User.ID, "member", false, 1, database.MovieFilter{})
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("veto_movie_%s_%s_random", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"already used all 1 of your vetoes this season"},
+		},
+		{
+			name: "veto with the only movie left",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveVetoSettings(testGuildID, 1, false)
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("veto_movie_%s_%s_random", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"no other movies left to pick instead of **Heat**, so your veto was not used"},
+			check: func(t *testing.T, env *testEnv) {
+				if used, _ := env.store.GetUserVetoCount(testGuildID, testMember.User.ID); used != 0 {
+					t.Errorf("expected the veto not to be spent, got %d used", used)
+				}
+			},
+		},
+		{
+			name: "veto when vetoes are disabled",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("veto_movie_%s_%s_random", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"Vetoes are not enabled"},
+		},
+		{
+			name:  "reroll without movies left",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, fmt.Sprintf("reroll_movie_%s_1_random", testGuildID))
+			},
+			want: []string{"No more available movies to pick"},
+		},
+		{
+			name:  "reroll requires the host permission",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("reroll_movie_%s_1_random", testGuildID))
+			},
+			want: []string{"can reroll movies"},
+		},
+		{
+			name: "confirm opens the scheduling modal",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, fmt.Sprintf("confirm_movie_%s_%s", testGuildID, env.ref(movieHeat)))
+			},
+			check: func(t *testing.T, env *testEnv) {
+				resp := env.responder.responses[0]
+				want := fmt.Sprintf("confirm_modal_%s_%s", testGuildID, env.ref(movieHeat))
+				if resp.Type != discordgo.InteractionResponseModal || resp.Data.CustomID != want {
+					t.Errorf("expected modal %s, got type %v id %q", want, resp.Type, resp.Data.CustomID)
+				}
+			},
+		},
+		{
+			name:  "confirm from another server",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, "confirm_movie_guild2_1")
+			},
+			want: []string{"different server"},
+		},
+		{
+			name: "confirm modal without a date selects the movie",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testHost, fmt.Sprintf("confirm_modal_%s_%s", testGuildID, env.ref(movieHeat)), map[string]string{"when": ""})
+			},
+			want: []string{"Selected Movie: Heat (1995)", "Confirmed by host"},
+			check: func(t *testing.T, env *testEnv) {
+				if movie, _ := env.store.GetSelectedMovie(testGuildID, env.ids["Heat"]); movie == nil {
+					t.Error("movie should be selected")
+				}
+				if len(env.responder.events) != 0 {
+					t.Errorf("no event should be created, got %d", len(env.responder.events))
+				}
+			},
+		},
+		{
+			name: "confirm modal with a date creates the event",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveEventSettings(testGuildID, testEventChannelID, "")
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testHost, fmt.Sprintf("confirm_modal_%s_%s", testGuildID, env.ref(movieHeat)), map[string]string{"when": futureScreening()})
+			},
+			want: []string{"Selected Movie: Heat (1995)", "Movie Night", "View event"},
+			check: func(t *testing.T, env *testEnv) {
+				screening, _ := env.store.GetScreening(testGuildID, env.ids["Heat"])
+				if screening == nil || screening.EventID == "" || screening.ScheduledFor == nil {
+					t.Errorf("unexpected screening: %+v", screening)
+				}
+				if event := env.responder.events[screening.EventID]; event == nil || event.ChannelID != testEventChannelID {
+					t.Errorf("unexpected event: %+v", event)
+				}
+			},
+		},
+		{
+			name: "confirm modal with an invalid date",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testHost, fmt.Sprintf("confirm_modal_%s_%s", testGuildID, env.ref(movieHeat)), map[string]string{"when": "tomorrow"})
+			},
+			want: []string{"Could not read \"tomorrow\""},
+			check: func(t *testing.T, env *testEnv) {
+				if movie, _ := env.store.GetSelectedMovie(testGuildID, env.ids["Heat"]); movie != nil {
+					t.Error("movie should not be selected")
+				}
+			},
+		},
+	})
+}
+
+func TestPickButtonIDFitsCustomIDLimit(t *testing.T) {
+	// Snowflakes com 20 dígitos, o maior ID de gênero e os limites das opções do /pickmovie
+	const snowflake = "18446744073709551615"
+	filter := database.MovieFilter{
+		GenreID:       10770,
+		MinYear:       3000,
+		MaxYear:       3000,
+		MaxRuntime:    1000,
+		MinTMDBRating: 9.87654321,
+		ExcludeUserID: snowflake,
+	}
+
+	for _, action := range []string{"reroll", "veto"} {
+		customID := pickButtonID(action, snowflake, 9999999, pickModeRandom, filter)
+		if len(customID) > maxCustomIDLength {
+			t.Errorf("%s custom ID has %d characters: %s", action, len(customID), customID)
+		}
+		if !strings.HasSuffix(customID, "_g10770,y3000,Y3000,r1000,t9.9,x"+snowflake) {
+			t.Errorf("%s custom ID should carry the whole filter, got %s", action, customID)
+		}
+	}
+
+	if got := decodePickFilter(encodePickFilter(filter)); got.MinTMDBRating != 9.9 || got.ExcludeUserID != snowflake || got.GenreID != 10770 {
+		t.Errorf("unexpected filter after a round trip: %+v", got)
+	}
+}
@@ -3,13 +3,15 @@ package commands
 import (
 	"clapper/database"
 	"fmt"
+	"log"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func (h *Handlers) HandleRateMovie(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleRateMovie(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -29,40 +31,63 @@ func (h *Handlers) HandleRateMovie(s *discordgo.Session, i *discordgo.Interactio
 		},
 	})
 
-	options := i.ApplicationCommandData().Options
-	movieName := options[0].StringValue()
-	ratingStr := options[1].StringValue()
-	
-	var reviewText string
-	if len(options) > 2 {
-		reviewText = options[2].StringValue()
+	var movieValue, ratingStr, reviewText string
+	var spoiler bool
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "movie_name":
+			movieValue = opt.StringValue()
+		case "rating":
+			ratingStr = opt.StringValue()
+		case "review":
+			reviewText = opt.StringValue()
+		case "spoiler":
+			spoiler = opt.BoolValue()
+		}
 	}
+	suggestionID, validID := parseSuggestionID(movieValue)
 
-	// Converter e validar o rating
-	ratingStr = strings.TrimSpace(ratingStr)
-	ratingStr = strings.Replace(ratingStr, ",", ".", -1)
-	rating, err := strconv.ParseFloat(ratingStr, 64)
-	
+	rating, err := parseRating(ratingStr)
 	if err != nil {
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: ptrString("❌ Invalid rating! Please provide a valid number (e.g., 8.4 or 9)."),
+			Content: ptrString(fmt.Sprintf("❌ Invalid rating! Please %s.", err)),
 		})
 		return
 	}
 
-	// Validação da nota
-	if rating < 0 || rating > 10 {
+	if !validID {
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: ptrString("❌ Invalid rating! Please provide a rating between 0 and 10."),
+			Content: ptrString("❌ Please pick a movie from the list of selected movies shown while typing."),
 		})
 		return
 	}
 
+	h.submitReview(s, i, suggestionID, rating, reviewText, spoiler)
+}
+
+// parseRating aceita notas de 0 a 10 com ponto ou vírgula decimal
+func parseRating(input string) (float64, error) {
+	input = strings.Replace(strings.TrimSpace(input), ",", ".", -1)
+	rating, err := strconv.ParseFloat(input, 64)
+	if err != nil {
+		return 0, fmt.Errorf("provide a valid number (e.g., 8.4 or 9)")
+	}
+	if rating < 0 || rating > 10 {
+		return 0, fmt.Errorf("provide a rating between 0 and 10")
+	}
+	return rating, nil
+}
+
+// submitReview grava (ou atualiza) a avaliação do membro e edita a resposta adiada com o resultado;
+// usado tanto pelo /ratemovie quanto pelo formulário do botão "Rate this movie"
+func (h *Handlers) submitReview(s Responder, i *discordgo.InteractionCreate, suggestionID int, rating float64, reviewText string, spoiler bool) {
+	guildID := i.GuildID
+
 	// Buscar o filme selecionado
-	movie, err := h.db.SearchSelectedMovie(guildID, movieName)
+	movie, err := h.db.GetSelectedMovie(guildID, suggestionID)
 	if err != nil || movie == nil {
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: ptrString(fmt.Sprintf("❌ Could not find a selected movie matching \"%s\".\nYou can only rate movies that have already been selected.", movieName)),
+			Content: ptrString("❌ Could not find that selected movie.\nYou can only rate movies that have already been selected."),
 		})
 		return
 	}
@@ -78,6 +103,7 @@ func (h *Handlers) HandleRateMovie(s *discordgo.Session, i *discordgo.Interactio
 		Username:     i.Member.User.Username,
 		Rating:       rating,
 		ReviewText:   reviewText,
+		Spoiler:      spoiler,
 	}
 
 	if err := h.db.SaveMovieReview(review); err != nil {
@@ -86,10 +112,37 @@ func (h *Handlers) HandleRateMovie(s *discordgo.Session, i *discordgo.Interactio
 		})
 		return
 	}
+	h.syncSuggestionPosts(s, guildID, movie.ID)
+
+	// Com a avaliação às cegas, o tópico só fica sabendo que o membro avaliou
+	blind := h.hiddenRatings(guildID, movie.ID)
+
+	// A avaliação também vai para o tópico de discussão do filme, quando existe
+	discussionInfo := ""
+	if screening, err := h.db.GetScreening(guildID, movie.ID); err == nil && screening != nil && screening.ThreadID != "" {
+		if blind != nil {
+			err = postBlindRatingToDiscussion(s, screening.ThreadID, review.UserID, existingReview != nil)
+		} else {
+			err = postReviewToDiscussion(s, screening.ThreadID, review, existingReview != nil)
+		}
+		if err != nil {
+			log.Printf("Erro ao publicar a avaliação no tópico %s: %v", screening.ThreadID, err)
+		} else if blind != nil {
+			discussionInfo = fmt.Sprintf("<#%s> was told you rated, without your score", screening.ThreadID)
+		} else {
+			discussionInfo = fmt.Sprintf("Your review was also posted in <#%s>", screening.ThreadID)
+		}
+	}
 
 	// Buscar média e contagem de avaliações
 	avgRating, reviewCount, _ := h.db.GetAverageMovieRating(guildID, movie.ID)
 
+	// Esta avaliação pode ser a que completa o número combinado para a revelação
+	revealedNow := blind != nil && h.revealEnoughRatings(s, blind, reviewCount)
+	if revealedNow {
+		blind = nil
+	}
+
 	// Criar embed de confirmação
 	action := "added"
 	if existingReview != nil {
@@ -111,13 +164,18 @@ func (h *Handlers) HandleRateMovie(s *discordgo.Session, i *discordgo.Interactio
 		})
 	}
 
-	// Adicionar estatísticas do filme
-	embed.Fields = append(embed.Fields,
-		&discordgo.MessageEmbedField{
+	// Adicionar estatísticas do filme; às cegas, a média dá lugar à lista de quem já avaliou
+	if blind != nil {
+		reviews, _ := h.db.GetMovieReviews(guildID, movie.ID)
+		embed.Fields = append(embed.Fields, blindRatingField(blind, reviews))
+	} else {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 			Name:   "📊 Community Rating",
 			Value:  fmt.Sprintf("%.1f/10 (%d review%s)", avgRating, reviewCount, pluralize(reviewCount)),
 			Inline: true,
-		},
+		})
+	}
+	embed.Fields = append(embed.Fields,
 		&discordgo.MessageEmbedField{
 			Name:   "🎭 TMDB Rating",
 			Value:  fmt.Sprintf("%.1f/10", movie.Rating),
@@ -125,13 +183,25 @@ func (h *Handlers) HandleRateMovie(s *discordgo.Session, i *discordgo.Interactio
 		},
 	)
 
-	// Buscar poster do TMDB
-	tmdbMovie, _ := h.tmdb.GetMovieByID(movie.TMDBID)
-	if tmdbMovie != nil {
-		posterURL := h.tmdb.GetPosterURL(tmdbMovie.PosterPath)
-		if posterURL != "" {
-			embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: posterURL}
-		}
+	// Pôster gravado com a sugestão
+	if posterURL := h.tmdb.GetPosterURL(movie.PosterPath); posterURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: posterURL}
+	}
+
+	if revealedNow {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "🎭 Ratings Revealed",
+			Value:  fmt.Sprintf("Yours was rating #%d, so everyone's scores were revealed and the full breakdown was posted.", reviewCount),
+			Inline: false,
+		})
+	}
+
+	if discussionInfo != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "💬 Discussion",
+			Value:  discussionInfo,
+			Inline: false,
+		})
 	}
 
 	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
@@ -139,6 +209,166 @@ func (h *Handlers) HandleRateMovie(s *discordgo.Session, i *discordgo.Interactio
 	})
 }
 
+// rateComponents é a linha com o botão "Rate this movie" da confirmação e do /moviereviews
+func rateComponents(guildID string, suggestionID int) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Rate this movie",
+					Style:    discordgo.PrimaryButton,
+					CustomID: fmt.Sprintf("rate_movie_%s_%d", guildID, suggestionID),
+					Emoji:    &discordgo.ComponentEmoji{Name: "⭐"},
+				},
+			},
+		},
+	}
+}
+
+// HandleRateButton abre o formulário de avaliação, já preenchido com a avaliação anterior do membro
+func (h *Handlers) HandleRateButton(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" || i.Member == nil {
+		return
+	}
+
+	re := regexp.MustCompile(`rate_movie_([^_]+)_(\d+)`)
+	matches := re.FindStringSubmatch(i.MessageComponentData().CustomID)
+	if len(matches) < 3 || matches[1] != guildID {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This movie is from a different server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	suggestionID, _ := strconv.Atoi(matches[2])
+	movie, err := h.db.GetSelectedMovie(guildID, suggestionID)
+	if err != nil || movie == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Could not find that selected movie.\nYou can only rate movies that have already been selected.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	var ratingValue, reviewValue string
+	if existing, _ := h.db.GetUserReview(guildID, movie.ID, i.Member.User.ID); existing != nil {
+		ratingValue = strconv.FormatFloat(existing.Rating, 'f', -1, 64)
+		reviewValue = existing.ReviewText
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: fmt.Sprintf("rate_modal_%s_%d", guildID, movie.ID),
+			Title:    truncate(fmt.Sprintf("Rate %s", movie.MovieName), 45),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "rating",
+							Label:       "Rating (0 to 10)",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "e.g. 8.5",
+							Value:       ratingValue,
+							Required:    true,
+							MaxLength:   4,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "review",
+							Label:       "Review",
+							Style:       discordgo.TextInputParagraph,
+							Placeholder: "Optional — what did you think?",
+							Value:       reviewValue,
+							Required:    false,
+							MaxLength:   1000,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "spoiler",
+							Label:       "Contains spoilers? (yes/no)",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "no",
+							Required:    false,
+							MaxLength:   3,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func (h *Handlers) HandleRateModalSubmit(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" || i.Member == nil {
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	data := i.ModalSubmitData()
+	re := regexp.MustCompile(`rate_modal_([^_]+)_(\d+)`)
+	matches := re.FindStringSubmatch(data.CustomID)
+	if len(matches) < 3 || matches[1] != guildID {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ This movie is from a different server."),
+		})
+		return
+	}
+
+	rating, err := parseRating(modalValue(data, "rating"))
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString(fmt.Sprintf("❌ Invalid rating! Please %s. Press **Rate this movie** again to retry.", err)),
+		})
+		return
+	}
+
+	spoiler, ok := parseYesNo(modalValue(data, "spoiler"))
+	if !ok {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Please answer the spoiler question with yes or no. Press **Rate this movie** again to retry."),
+		})
+		return
+	}
+
+	suggestionID, _ := strconv.Atoi(matches[2])
+	reviewText := strings.TrimSpace(modalValue(data, "review"))
+	h.submitReview(s, i, suggestionID, rating, reviewText, spoiler)
+}
+
+// parseYesNo interpreta as respostas de sim/não digitadas nos formulários; vazio é não e qualquer
+// outra resposta devolve ok false
+func parseYesNo(input string) (yes, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true, true
+	case "", "n", "no":
+		return false, true
+	}
+	return false, false
+}
+
 func pluralize(count int) string {
 	if count == 1 {
 		return ""
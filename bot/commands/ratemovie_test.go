@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"clapper/database"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRateMovie(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name:  "ratemovie saves a review",
+			setup: func(env *testEnv) { env.selectMovie(movieHeat, testOther) },
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "ratemovie",
+					stringOption("movie_name", env.ref(movieHeat)), stringOption("rating", "8,5"), stringOption("review", "Great heist"))
+			},
+			want: []string{"Review added for Heat", "8.5/10", "Great heist"},
+		},
+		{
+			name:  "ratemovie rejects out of range ratings",
+			setup: func(env *testEnv) { env.selectMovie(movieHeat, testOther) },
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "ratemovie", stringOption("movie_name", env.ref(movieHeat)), stringOption("rating", "11"))
+			},
+			want: []string{"between 0 and 10"},
+		},
+		{
+			name:  "ratemovie only accepts selected movies",
+			setup: func(env *testEnv) { env.suggest(movieHeat, testOther) },
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "ratemovie", stringOption("movie_name", env.ref(movieHeat)), stringOption("rating", "7"))
+			},
+			want: []string{"You can only rate movies that have already been selected"},
+		},
+		{
+			name: "moviereviews lists the reviews",
+			setup: func(env *testEnv) {
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SaveMovieReview(&database.MovieReview{SuggestionID: id, GuildID: testGuildID, UserID: "3", Username: "member", Rating: 9, ReviewText: "Classic"})
+				env.store.SaveMovieReview(&database.MovieReview{SuggestionID: id, GuildID: testGuildID, UserID: "4", Username: "other", Rating: 7, ReviewText: "The twist", Spoiler: true})
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "moviereviews", stringOption("movie_name", env.ref(movieHeat)))
+			},
+			want: []string{"Reviews for Heat (1995)", "*\"Classic\"*", "*\"||The twist||\"*"},
+		},
+		{
+			name: "selectedmovies without selections",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "selectedmovies")
+			},
+			want: []string{"No movies have been selected yet"},
+		},
+		{
+			name:  "selectedmovies lists the selections",
+			setup: func(env *testEnv) { env.selectMovie(movieHeat, testOther) },
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "selectedmovies")
+			},
+			want: []string{"Selected Movies", "Heat"},
+		},
+
+		{
+			name: "confirm offers a rate button",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testHost, fmt.Sprintf("confirm_modal_%s_%s", testGuildID, env.ref(movieHeat)), map[string]string{"when": ""})
+			},
+			want: []string{"Selected Movie: Heat (1995)"},
+			check: func(t *testing.T, env *testEnv) {
+				want := []string{fmt.Sprintf("rate_movie_%s_%s", testGuildID, env.ref(movieHeat))}
+				if ids := env.responder.customIDs(); !slices.Equal(ids, want) {
+					t.Errorf("custom IDs = %v, want %v", ids, want)
+				}
+			},
+		},
+		{
+			name: "rate button opens the form with the previous review",
+			setup: func(env *testEnv) {
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SaveMovieReview(&database.MovieReview{SuggestionID: id, GuildID: testGuildID, UserID: testMember.User.ID, Rating: 7.5, ReviewText: "Solid"})
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("rate_movie_%s_%s", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"Rate Heat"},
+			check: func(t *testing.T, env *testEnv) {
+				resp := env.responder.responses[len(env.responder.responses)-1]
+				if resp.Type != discordgo.InteractionResponseModal || resp.Data.CustomID != fmt.Sprintf("rate_modal_%s_%s", testGuildID, env.ref(movieHeat)) {
+					t.Fatalf("expected the rating form, got %+v", resp)
+				}
+				var values []string
+				for _, row := range resp.Data.Components {
+					values = append(values, row.(discordgo.ActionsRow).Components[0].(discordgo.TextInput).Value)
+				}
+				if want := []string{"7.5", "Solid", ""}; !slices.Equal(values, want) {
+					t.Errorf("prefilled values = %q, want %q", values, want)
+				}
+			},
+		},
+		{
+			name:  "rate button only works for selected movies",
+			setup: func(env *testEnv) { env.suggest(movieHeat, testOther) },
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("rate_movie_%s_%s", testGuildID, env.ref(movieHeat)))
+			},
+			want: []string{"You can only rate movies that have already been selected"},
+		},
+		{
+			name: "rate form updates the existing review",
+			setup: func(env *testEnv) {
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SaveMovieReview(&database.MovieReview{SuggestionID: id, GuildID: testGuildID, UserID: testMember.User.ID, Rating: 7.5})
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testMember, fmt.Sprintf("rate_modal_%s_%s", testGuildID, env.ref(movieHeat)),
+					map[string]string{"rating": "9,5", "review": "Even better the second time", "spoiler": ""})
+			},
+			want: []string{"Review updated for Heat", "9.5/10", "Even better the second time", "9.5/10 (1 review)"},
+		},
+		{
+			name:  "rate form rejects invalid ratings",
+			setup: func(env *testEnv) { env.selectMovie(movieHeat, testOther) },
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testMember, fmt.Sprintf("rate_modal_%s_%s", testGuildID, env.ref(movieHeat)), map[string]string{"rating": "great"})
+			},
+			want: []string{"Please provide a valid number", "Press **Rate this movie** again"},
+			check: func(t *testing.T, env *testEnv) {
+				if reviews, _ := env.store.GetMovieReviews(testGuildID, env.ids["Heat"]); len(reviews) != 0 {
+					t.Errorf("no review should be saved, got %+v", reviews)
+				}
+			},
+		},
+		{
+			name: "rate form hides spoilers in the discussion thread",
+			setup: func(env *testEnv) {
+				id := env.selectMovie(movieHeat, testOther)
+				env.store.SetDiscussionThread(testGuildID, id, "thread-9")
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testMember, fmt.Sprintf("rate_modal_%s_%s", testGuildID, env.ref(movieHeat)),
+					map[string]string{"rating": "8", "review": "The ending!", "spoiler": "Yes"})
+			},
+			want: []string{"Review added for Heat"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.sent) != 1 || !strings.Contains(env.responder.sent[0].data.Content, "||The ending!||") {
+					t.Errorf("expected a spoiler-tagged review in the thread, got %+v", env.responder.sent)
+				}
+			},
+		},
+		{
+			name:  "rate form refuses an unclear spoiler answer",
+			setup: func(env *testEnv) { env.selectMovie(movieHeat, testOther) },
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testMember, fmt.Sprintf("rate_modal_%s_%s", testGuildID, env.ref(movieHeat)),
+					map[string]string{"rating": "8", "review": "", "spoiler": "tru"})
+			},
+			want: []string{"answer the spoiler question with yes or no"},
+			check: func(t *testing.T, env *testEnv) {
+				if reviews, _ := env.store.GetMovieReviews(testGuildID, env.ids["Heat"]); len(reviews) != 0 {
+					t.Errorf("expected no review to be saved, got %+v", reviews)
+				}
+			},
+		},
+		{
+			name:  "moviereviews offers a rate button",
+			setup: func(env *testEnv) { env.selectMovie(movieHeat, testOther) },
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "moviereviews", stringOption("movie_name", env.ref(movieHeat)))
+			},
+			want: []string{"Reviews for Heat (1995)"},
+			check: func(t *testing.T, env *testEnv) {
+				want := []string{fmt.Sprintf("rate_movie_%s_%s", testGuildID, env.ref(movieHeat))}
+				if ids := env.responder.customIDs(); !slices.Equal(ids, want) {
+					t.Errorf("custom IDs = %v, want %v", ids, want)
+				}
+			},
+		},
+	})
+}
@@ -1,13 +1,12 @@
 package commands
 
 import (
-	"clapper/database"
 	"fmt"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func (h *Handlers) HandleRemoveSuggestion(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleRemoveSuggestion(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -28,29 +27,23 @@ func (h *Handlers) HandleRemoveSuggestion(s *discordgo.Session, i *discordgo.Int
 	})
 
 	options := i.ApplicationCommandData().Options
-	movieName := options[0].StringValue()
 
-	isAdmin := false
-	if i.Member != nil {
-		perms, err := s.UserChannelPermissions(i.Member.User.ID, i.ChannelID)
-		if err == nil {
-			isAdmin = perms&discordgo.PermissionAdministrator != 0
-		}
-	}
-
-	var movie *database.Suggestion
-	var err error
+	// Anfitriões moderam as sugestões de todos; os demais só removem as próprias
+	isHost := h.authorize(s, i, permissionHost)
 
-	if isAdmin {
-		movie, err = h.db.SearchAnySuggestion(guildID, movieName)
-	} else {
-		movie, err = h.db.SearchUserSuggestions(guildID, movieName, i.Member.User.ID)
+	suggestionID, ok := parseSuggestionID(options[0].StringValue())
+	if !ok {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Please pick a movie from the list of suggestions shown while typing."),
+		})
+		return
 	}
 
-	if err != nil || movie == nil {
-		msg := fmt.Sprintf("❌ Could not find a movie suggestion matching \"%s\".", movieName)
-		if !isAdmin {
-			msg = fmt.Sprintf("❌ Could not find a movie named \"%s\" in your suggestions.\nYou can only remove movies that you suggested.", movieName)
+	movie, err := h.db.GetSuggestion(guildID, suggestionID)
+	if err != nil || movie == nil || (!isHost && movie.UserID != i.Member.User.ID) {
+		msg := "❌ Could not find that movie suggestion."
+		if !isHost {
+			msg = "❌ Could not find that movie in your suggestions.\nYou can only remove movies that you suggested."
 		}
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: ptrString(msg)})
 		return
@@ -62,9 +55,10 @@ func (h *Handlers) HandleRemoveSuggestion(s *discordgo.Session, i *discordgo.Int
 		})
 		return
 	}
+	h.removeSuggestionPost(s, movie)
 
 	suggesterInfo := ""
-	if isAdmin && movie.UserID != i.Member.User.ID {
+	if isHost && movie.UserID != i.Member.User.ID {
 		suggesterInfo = fmt.Sprintf(" (suggested by %s)", movie.Username)
 	}
 
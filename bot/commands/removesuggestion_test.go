@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRemoveSuggestion(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name:  "removesuggestion removes the member's own suggestion",
+			setup: func(env *testEnv) { env.suggest(movieHeat, testMember) },
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "removesuggestion", stringOption("movie_name", env.ref(movieHeat)))
+			},
+			want: []string{"Successfully removed **Heat** from suggestions"},
+		},
+		{
+			name:  "removesuggestion refuses other members' suggestions",
+			setup: func(env *testEnv) { env.suggest(movieHeat, testOther) },
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "removesuggestion", stringOption("movie_name", env.ref(movieHeat)))
+			},
+			want: []string{"You can only remove movies that you suggested"},
+		},
+		{
+			name: "removesuggestion lets hosts moderate",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "removesuggestion", stringOption("movie_name", env.ref(movieHeat)))
+			},
+			want: []string{"Successfully removed **Heat** (suggested by other)"},
+		},
+		{
+			name: "removesuggestion with free text",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "removesuggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"pick a movie from the list of suggestions"},
+		},
+	})
+}
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func (h *Handlers) HandleSchedule(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This command can only be used in a server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can schedule movie nights!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	guildConfig, err := h.db.GetGuildConfig(guildID)
+	if err != nil || guildConfig == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ This server has not been configured yet! Run `/setup channels` first."),
+		})
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	suggestionID, ok := parseSuggestionID(options[0].StringValue())
+	if !ok {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Please pick a movie from the list of selected movies shown while typing."),
+		})
+		return
+	}
+
+	movie, err := h.db.GetSelectedMovie(guildID, suggestionID)
+	if err != nil || movie == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Could not find that selected movie."),
+		})
+		return
+	}
+
+	loc := guildLocation(guildConfig)
+	when, err := parseScreeningTime(options[1].StringValue(), loc)
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString(fmt.Sprintf("❌ Could not read the date: %s (timezone: %s).", err, loc)),
+		})
+		return
+	}
+
+	eventID, err := h.scheduleScreening(s, guildConfig, movie, when)
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Could not create the Discord event. Check the bot's permissions and the event channel in `/setup channels`."),
+		})
+		return
+	}
+
+	msg := fmt.Sprintf("✅ **%s** is scheduled for <t:%d:F> (<t:%d:R>).", movie.MovieName, when.Unix(), when.Unix())
+	if eventID != "" {
+		msg += fmt.Sprintf("\n[View event](https://discord.com/events/%s/%s)", guildID, eventID)
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: ptrString(msg),
+	})
+}
+
+func (h *Handlers) HandleUnschedule(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This command can only be used in a server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can cancel movie nights!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	options := i.ApplicationCommandData().Options
+	suggestionID, ok := parseSuggestionID(options[0].StringValue())
+	if !ok {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Please pick a movie from the list of selected movies shown while typing."),
+		})
+		return
+	}
+
+	movie, err := h.db.GetSelectedMovie(guildID, suggestionID)
+	if err != nil || movie == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Could not find that selected movie."),
+		})
+		return
+	}
+
+	if err := h.cancelScreening(s, guildID, movie.ID); err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while cancelling the movie night. Please try again."),
+		})
+		return
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: ptrString(fmt.Sprintf("✅ The movie night for **%s** has been cancelled.", movie.MovieName)),
+	})
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPosterDataURI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/poster.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("png"))
+		case "/huge.png":
+			w.Write(make([]byte, maxPosterSize+1))
+		case "/slow.png":
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	if uri := posterDataURI(context.Background(), server.URL+"/poster.png"); uri != "data:image/png;base64,cG5n" {
+		t.Errorf("unexpected data URI %q", uri)
+	}
+	// Um pôster cortado no limite chegaria corrompido, então fica sem capa
+	if uri := posterDataURI(context.Background(), server.URL+"/huge.png"); uri != "" {
+		t.Errorf("expected no cover for an oversized poster, got %d bytes", len(uri))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if uri := posterDataURI(ctx, server.URL+"/slow.png"); uri != "" || time.Since(start) > time.Second {
+		t.Errorf("expected the download to stop with the context, got %q after %v", uri, time.Since(start))
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "schedule creates a Discord event",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveEventSettings(testGuildID, testEventChannelID, "")
+				env.selectMovie(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "schedule", stringOption("movie", env.ref(movieHeat)), stringOption("when", futureScreening()))
+			},
+			want: []string{"**Heat** is scheduled for", "View event"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.events) != 1 {
+					t.Errorf("expected one event, got %d", len(env.responder.events))
+				}
+			},
+		},
+		{
+			name: "schedule rejects dates in the past",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.selectMovie(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "schedule", stringOption("movie", env.ref(movieHeat)), stringOption("when", "2001-01-01 20:00"))
+			},
+			want: []string{"the date must be in the future"},
+		},
+		{
+			name: "schedule requires the host permission",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.selectMovie(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "schedule", stringOption("movie", env.ref(movieHeat)), stringOption("when", futureScreening()))
+			},
+			want: []string{"can schedule movie nights"},
+		},
+		{
+			name: "unschedule cancels the event",
+			setup: func(env *testEnv) {
+				env.configure()
+				id := env.selectMovie(movieHeat, testOther)
+				env.responder.events["event-9"] = &discordgo.GuildScheduledEventParams{}
+				env.store.SetScreening(testGuildID, id, "event-9", time.Now().Add(time.Hour))
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "unschedule", stringOption("movie", env.ref(movieHeat)))
+			},
+			want: []string{"movie night for **Heat** has been cancelled"},
+			check: func(t *testing.T, env *testEnv) {
+				if status := env.responder.events["event-9"].Status; status != discordgo.GuildScheduledEventStatusCanceled {
+					t.Errorf("event status = %v, want canceled", status)
+				}
+				if screening, _ := env.store.GetScreening(testGuildID, env.ids["Heat"]); screening.ScheduledFor != nil {
+					t.Error("screening should be cleared")
+				}
+			},
+		},
+	})
+}
@@ -1,12 +1,16 @@
 package commands
 
 import (
+	"clapper/database"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func (h *Handlers) HandleSetup(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleSetup(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -19,20 +23,7 @@ func (h *Handlers) HandleSetup(s *discordgo.Session, i *discordgo.InteractionCre
 		return
 	}
 
-	// Check if user is admin
-	if i.Member == nil {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "❌ An error occurred. Please try again.",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
-		return
-	}
-
-	perms, err := s.UserChannelPermissions(i.Member.User.ID, i.ChannelID)
-	if err != nil || perms&discordgo.PermissionAdministrator == 0 {
+	if !h.authorize(s, i, permissionConfigure) {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -50,8 +41,50 @@ func (h *Handlers) HandleSetup(s *discordgo.Session, i *discordgo.InteractionCre
 		},
 	})
 
-	options := i.ApplicationCommandData().Options
-	channelID := options[0].ChannelValue(s).ID
+	subcommand := i.ApplicationCommandData().Options[0]
+	switch subcommand.Name {
+	case "channels":
+		h.setupChannels(s, i, subcommand.Options)
+	case "roles":
+		h.setupRoles(s, i, subcommand.Options)
+	case "picking":
+		h.setupPicking(s, i, subcommand.Options)
+	case "vetoes":
+		h.setupVetoes(s, i, subcommand.Options)
+	case "limits":
+		h.setupLimits(s, i, subcommand.Options)
+	case "moderation":
+		h.setupModeration(s, i, subcommand.Options)
+	case "discussions":
+		h.setupDiscussions(s, i, subcommand.Options)
+	case "language":
+		h.setupLanguage(s, i, subcommand.Options)
+	}
+}
+
+func (h *Handlers) setupChannels(s Responder, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guildID := i.GuildID
+
+	var channelID, eventChannelID, timezone string
+	for _, opt := range options {
+		switch opt.Name {
+		case "suggestion_channel":
+			channelID = opt.ChannelValue(nil).ID
+		case "event_channel":
+			eventChannelID = opt.ChannelValue(nil).ID
+		case "timezone":
+			timezone = strings.TrimSpace(opt.StringValue())
+		}
+	}
+
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: ptrString(fmt.Sprintf("❌ Unknown timezone \"%s\". Use a name like `America/Sao_Paulo` or `Europe/Lisbon`.", timezone)),
+			})
+			return
+		}
+	}
 
 	// Verify if channel exists and bot has permissions
 	channel, err := s.Channel(channelID)
@@ -78,6 +111,24 @@ func (h *Handlers) HandleSetup(s *discordgo.Session, i *discordgo.InteractionCre
 		return
 	}
 
+	// Opções omitidas mantêm os valores anteriores
+	previous, _ := h.db.GetGuildConfig(guildID)
+	if previous != nil {
+		if eventChannelID == "" {
+			eventChannelID = previous.EventChannelID
+		}
+		if timezone == "" {
+			timezone = previous.Timezone
+		}
+	}
+
+	if err := h.db.SaveEventSettings(guildID, eventChannelID, timezone); err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while saving the configuration. Please try again."),
+		})
+		return
+	}
+
 	embed := &discordgo.MessageEmbed{
 		Title:       "✅ Bot Configuration Updated",
 		Description: fmt.Sprintf("Movie suggestions will now be posted in <#%s>", channelID),
@@ -94,12 +145,496 @@ func (h *Handlers) HandleSetup(s *discordgo.Session, i *discordgo.InteractionCre
 		},
 	}
 
+	if eventChannelID != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "🗓️ Movie Night Events",
+			Value:  fmt.Sprintf("Confirmed movies can be scheduled as events in <#%s>", eventChannelID),
+			Inline: false,
+		})
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+func (h *Handlers) setupRoles(s Responder, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guildID := i.GuildID
+
+	config, err := h.db.GetGuildConfig(guildID)
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while fetching the configuration."),
+		})
+		return
+	}
+
+	if config == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ This server has not been configured yet! Run `/setup channels` first."),
+		})
+		return
+	}
+
+	hostRoleID := config.HostRoleID
+	suggesterRoleID := config.SuggesterRoleID
+
+	// "clear" vale antes dos cargos informados no mesmo comando, independente da ordem das opções
+	for _, opt := range options {
+		if opt.Name == "clear" && opt.BoolValue() {
+			hostRoleID = ""
+			suggesterRoleID = ""
+		}
+	}
+
+	for _, opt := range options {
+		switch opt.Name {
+		case "host_role":
+			hostRoleID = opt.RoleValue(nil, guildID).ID
+		case "suggester_role":
+			suggesterRoleID = opt.RoleValue(nil, guildID).ID
+		}
+	}
+
+	if err := h.db.SaveRoleSettings(guildID, hostRoleID, suggesterRoleID); err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while saving the configuration. Please try again."),
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "✅ Roles Updated",
+		Description: "Administrators can always do everything. The roles below grant extra permissions to other members.",
+		Color:       0x2ECC71,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "🎬 Host Role",
+				Value:  roleDisplay(hostRoleID, "Administrators only") + "\nCan pick, reroll, confirm, schedule and moderate suggestions.",
+				Inline: false,
+			},
+			{
+				Name:   "💡 Suggester Role",
+				Value:  roleDisplay(suggesterRoleID, "Everyone") + "\nCan suggest movies with `/suggestion`.",
+				Inline: false,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Configured by %s", i.Member.User.Username),
+		},
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+func (h *Handlers) setupPicking(s Responder, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guildID := i.GuildID
+
+	config, err := h.db.GetGuildConfig(guildID)
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while fetching the configuration."),
+		})
+		return
+	}
+
+	if config == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ This server has not been configured yet! Run `/setup channels` first."),
+		})
+		return
+	}
+
+	strategy := database.PickStrategyUniform
+	for _, opt := range options {
+		if opt.Name == "strategy" {
+			strategy = opt.StringValue()
+		}
+	}
+
+	if _, ok := pickStrategyNames[strategy]; !ok {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString(fmt.Sprintf("❌ Unknown picking strategy \"%s\".", strategy)),
+		})
+		return
+	}
+
+	if err := h.db.SavePickStrategy(guildID, strategy); err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while saving the configuration. Please try again."),
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "✅ Picking Strategy Updated",
+		Description: fmt.Sprintf("`/pickmovie` and rerolls in random mode now use: **%s**", pickStrategyNames[strategy]),
+		Color:       0x2ECC71,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "ℹ️ Vote Mode",
+				Value:  "Picks with `mode: Weighted by community votes` keep using the votes and ignore this setting.",
+				Inline: false,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Configured by %s", i.Member.User.Username),
+		},
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+func (h *Handlers) setupVetoes(s Responder, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guildID := i.GuildID
+
+	config, err := h.db.GetGuildConfig(guildID)
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while fetching the configuration."),
+		})
+		return
+	}
+
+	if config == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ This server has not been configured yet! Run `/setup channels` first."),
+		})
+		return
+	}
+
+	// Opções omitidas mantêm os valores anteriores
+	perSeason := config.VetoesPerSeason
+	banVetoed := config.BanVetoed
+	for _, opt := range options {
+		switch opt.Name {
+		case "per_season":
+			perSeason = int(opt.IntValue())
+		case "ban_vetoed":
+			banVetoed = opt.BoolValue()
+		}
+	}
+
+	if err := h.db.SaveVetoSettings(guildID, perSeason, banVetoed); err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while saving the configuration. Please try again."),
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "✅ Vetoes Updated",
+		Description: vetoSettingsDisplay(perSeason, banVetoed),
+		Color:       0x2ECC71,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "ℹ️ How Vetoes Work",
+				Value:  "Members press **Veto** on a `/pickmovie` result to spend a veto and force an automatic reroll. Balances reset with `/resetselections`.",
+				Inline: false,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Configured by %s", i.Member.User.Username),
+		},
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+func (h *Handlers) setupLimits(s Responder, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guildID := i.GuildID
+
+	config, err := h.db.GetGuildConfig(guildID)
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while fetching the configuration."),
+		})
+		return
+	}
+
+	if config == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ This server has not been configured yet! Run `/setup channels` first."),
+		})
+		return
+	}
+
+	// Opções omitidas mantêm os valores anteriores
+	maxOpen := config.MaxOpenSuggestions
+	cooldown := config.SuggestionCooldown
+	for _, opt := range options {
+		switch opt.Name {
+		case "max_open":
+			maxOpen = int(opt.IntValue())
+		case "cooldown":
+			cooldown, err = parseCooldown(opt.StringValue())
+			if err != nil {
+				s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+					Content: ptrString(fmt.Sprintf("❌ Invalid cooldown \"%s\": %s.", opt.StringValue(), err)),
+				})
+				return
+			}
+		}
+	}
+
+	if err := h.db.SaveSuggestionLimits(guildID, maxOpen, cooldown); err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while saving the configuration. Please try again."),
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "✅ Suggestion Limits Updated",
+		Description: suggestionLimitsDisplay(maxOpen, cooldown),
+		Color:       0x2ECC71,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "ℹ️ How Limits Work",
+				Value:  "Open suggestions are the ones not picked yet; removing or picking one frees a slot. Administrators are exempt, and members can check their quota with `/mystats`.",
+				Inline: false,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Configured by %s", i.Member.User.Username),
+		},
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+func (h *Handlers) setupModeration(s Responder, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guildID := i.GuildID
+
+	config, err := h.db.GetGuildConfig(guildID)
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while fetching the configuration."),
+		})
+		return
+	}
+
+	if config == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ This server has not been configured yet! Run `/setup channels` first."),
+		})
+		return
+	}
+
+	// Opções omitidas mantêm os valores anteriores
+	requireApproval := config.RequireApproval
+	modChannelID := config.ModChannelID
+	for _, opt := range options {
+		switch opt.Name {
+		case "require_approval":
+			requireApproval = opt.BoolValue()
+		case "mod_channel":
+			modChannelID = opt.ChannelValue(nil).ID
+		}
+	}
+
+	if requireApproval && modChannelID == "" {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Choose a `mod_channel` where hosts will review the suggestions before turning approval on."),
+		})
+		return
+	}
+
+	if modChannelID != "" && modChannelID != config.ModChannelID {
+		channel, err := s.Channel(modChannelID)
+		if err != nil || channel.Type != discordgo.ChannelTypeGuildText {
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: ptrString("❌ Could not access the moderation channel. Please pick a text channel the bot can view and send messages in."),
+			})
+			return
+		}
+	}
+
+	if err := h.db.SaveModerationSettings(guildID, requireApproval, modChannelID); err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while saving the configuration. Please try again."),
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "✅ Moderation Updated",
+		Description: moderationDisplay(requireApproval, modChannelID),
+		Color:       0x2ECC71,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "ℹ️ How Moderation Works",
+				Value:  "New suggestions wait in the moderation channel until a host presses **Approve** or **Reject**. Only approved suggestions are posted publicly and can be picked. Pending suggestions stay in the queue if approval is turned off.",
+				Inline: false,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Configured by %s", i.Member.User.Username),
+		},
+	}
+
 	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 		Embeds: &[]*discordgo.MessageEmbed{embed},
 	})
 }
 
-func (h *Handlers) HandleConfig(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) setupDiscussions(s Responder, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guildID := i.GuildID
+
+	config, err := h.db.GetGuildConfig(guildID)
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while fetching the configuration."),
+		})
+		return
+	}
+
+	if config == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ This server has not been configured yet! Run `/setup channels` first."),
+		})
+		return
+	}
+
+	// "clear" vale antes do fórum informado no mesmo comando, como em /setup roles
+	forumID := config.DiscussionForumID
+	for _, opt := range options {
+		if opt.Name == "clear" && opt.BoolValue() {
+			forumID = ""
+		}
+	}
+	for _, opt := range options {
+		if opt.Name == "forum" {
+			forumID = opt.ChannelValue(nil).ID
+		}
+	}
+
+	if forumID != "" && forumID != config.DiscussionForumID {
+		channel, err := s.Channel(forumID)
+		if err != nil || channel.Type != discordgo.ChannelTypeGuildForum {
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: ptrString("❌ Could not access the forum. Please pick a forum channel where the bot can create posts."),
+			})
+			return
+		}
+	}
+
+	if err := h.db.SaveDiscussionSettings(guildID, forumID); err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while saving the configuration. Please try again."),
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "✅ Discussions Updated",
+		Description: discussionDisplay(forumID),
+		Color:       0x2ECC71,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "ℹ️ How Discussions Work",
+				Value:  "Each confirmed movie gets its own thread with the overview, runtime and a spoiler warning. Reviews sent with `/ratemovie` are posted there, and `/moviereviews` links to it.",
+				Inline: false,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Configured by %s", i.Member.User.Username),
+		},
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+func (h *Handlers) setupLanguage(s Responder, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guildID := i.GuildID
+
+	config, err := h.db.GetGuildConfig(guildID)
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while fetching the configuration."),
+		})
+		return
+	}
+
+	if config == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ This server has not been configured yet! Run `/setup channels` first."),
+		})
+		return
+	}
+
+	var language string
+	for _, opt := range options {
+		if opt.Name == "language" {
+			language = opt.StringValue()
+		}
+	}
+
+	// Sem a lista do idioma os gêneros caem no idioma padrão; a atualização periódica do bot tenta de novo depois
+	ctx, cancel := tmdbContext()
+	if err := h.tmdb.LoadGenres(ctx, language); err != nil {
+		log.Printf("Erro ao carregar os gêneros do TMDB em %s: %v", language, err)
+	}
+	cancel()
+
+	if err := h.db.SaveLanguage(guildID, language); err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while saving the configuration. Please try again."),
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "✅ Language Updated",
+		Description: fmt.Sprintf("Movie titles, overviews and genres from TMDB are now shown in **%s**.", languageDisplay(language)),
+		Color:       0x2ECC71,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "ℹ️ Existing Suggestions",
+				Value:  "Movies suggested before the change keep their old titles. Run `/refreshmetadata all: True` to translate them too.",
+				Inline: false,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Configured by %s", i.Member.User.Username),
+		},
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+}
+
+// parseCooldown aceita "0", "off" ou "none" para desativar o intervalo, ou uma duração entre 1 minuto e 30 dias
+func parseCooldown(input string) (time.Duration, error) {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "0", "off", "none":
+		return 0, nil
+	}
+
+	cooldown, err := parseDuration(input)
+	if err != nil {
+		return 0, err
+	}
+	if cooldown < time.Minute || cooldown > maxSuggestionCooldown {
+		return 0, fmt.Errorf("the cooldown must be between 1 minute and 30 days")
+	}
+	return cooldown.Round(time.Minute), nil
+}
+
+const maxSuggestionCooldown = 30 * 24 * time.Hour
+
+func (h *Handlers) HandleConfig(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -135,7 +670,7 @@ func (h *Handlers) HandleConfig(s *discordgo.Session, i *discordgo.InteractionCr
 			Fields: []*discordgo.MessageEmbedField{
 				{
 					Name:   "🛠️ Setup Required",
-					Value:  "An administrator needs to run `/setup` to configure the suggestion channel before users can suggest movies.",
+					Value:  "An administrator needs to run `/setup channels` to configure the suggestion channel before users can suggest movies.",
 					Inline: false,
 				},
 			},
@@ -161,6 +696,56 @@ func (h *Handlers) HandleConfig(s *discordgo.Session, i *discordgo.InteractionCr
 				Value:  fmt.Sprintf("<#%s>", config.SuggestionChannelID),
 				Inline: false,
 			},
+			{
+				Name:   "🗓️ Event Channel",
+				Value:  eventChannelDisplay(config.EventChannelID),
+				Inline: true,
+			},
+			{
+				Name:   "🕒 Timezone",
+				Value:  guildLocation(config).String(),
+				Inline: true,
+			},
+			{
+				Name:   "🎬 Host Role",
+				Value:  roleDisplay(config.HostRoleID, "Administrators only"),
+				Inline: true,
+			},
+			{
+				Name:   "💡 Suggester Role",
+				Value:  roleDisplay(config.SuggesterRoleID, "Everyone"),
+				Inline: true,
+			},
+			{
+				Name:   "🎲 Picking Strategy",
+				Value:  pickStrategyNames[h.pickStrategy(guildID)],
+				Inline: false,
+			},
+			{
+				Name:   "🚫 Vetoes",
+				Value:  vetoSettingsDisplay(config.VetoesPerSeason, config.BanVetoed),
+				Inline: false,
+			},
+			{
+				Name:   "📏 Suggestion Limits",
+				Value:  suggestionLimitsDisplay(config.MaxOpenSuggestions, config.SuggestionCooldown),
+				Inline: false,
+			},
+			{
+				Name:   "🛡️ Moderation",
+				Value:  h.moderationConfigDisplay(guildID, config),
+				Inline: false,
+			},
+			{
+				Name:   "💬 Discussions",
+				Value:  discussionDisplay(config.DiscussionForumID),
+				Inline: false,
+			},
+			{
+				Name:   "🌐 Language",
+				Value:  languageDisplay(config.Language),
+				Inline: false,
+			},
 			{
 				Name:   "📅 Configured At",
 				Value:  config.ConfiguredAt.Format("Jan 02, 2006 at 3:04 PM"),
@@ -168,7 +753,7 @@ func (h *Handlers) HandleConfig(s *discordgo.Session, i *discordgo.InteractionCr
 			},
 		},
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Administrators can update this with /setup",
+			Text: "Administrators can update this with /setup channels, roles, picking, vetoes, limits, moderation, discussions and language",
 		},
 	}
 
@@ -176,3 +761,90 @@ func (h *Handlers) HandleConfig(s *discordgo.Session, i *discordgo.InteractionCr
 		Embeds: &[]*discordgo.MessageEmbed{embed},
 	})
 }
+
+func eventChannelDisplay(channelID string) string {
+	if channelID == "" {
+		return "Not configured"
+	}
+	return fmt.Sprintf("<#%s>", channelID)
+}
+
+func vetoSettingsDisplay(perSeason int, banVetoed bool) string {
+	if perSeason <= 0 {
+		return "Disabled"
+	}
+	display := vetoCount(perSeason) + " per member each season"
+	if banVetoed {
+		return display + "; vetoed movies are banned until the season ends"
+	}
+	return display + "; vetoed movies go back into the pool"
+}
+
+func suggestionLimitsDisplay(maxOpen int, cooldown time.Duration) string {
+	var limits []string
+	if maxOpen > 0 {
+		limits = append(limits, fmt.Sprintf("%d open suggestion%s per member", maxOpen, pluralize(maxOpen)))
+	}
+	if cooldown > 0 {
+		limits = append(limits, fmt.Sprintf("1 suggestion every %s", formatDuration(cooldown)))
+	}
+	if len(limits) == 0 {
+		return "Disabled"
+	}
+	return strings.Join(limits, " · ")
+}
+
+func moderationDisplay(requireApproval bool, modChannelID string) string {
+	if !requireApproval {
+		return "Disabled — suggestions are posted right away"
+	}
+	return fmt.Sprintf("Suggestions wait for a host's approval in <#%s>", modChannelID)
+}
+
+// moderationConfigDisplay acrescenta as sugestões ainda na fila, que continuam lá mesmo com a aprovação desligada
+func (h *Handlers) moderationConfigDisplay(guildID string, config *database.GuildConfig) string {
+	display := moderationDisplay(config.RequireApproval, config.ModChannelID)
+	if pending, err := h.db.GetPendingSuggestionsCount(guildID); err == nil && pending > 0 {
+		display += fmt.Sprintf("\n%d suggestion%s awaiting approval", pending, pluralize(pending))
+	}
+	return display
+}
+
+// tmdbLanguages são os idiomas oferecidos em /setup language, no formato de idioma e região do TMDB
+var tmdbLanguages = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "English (US)", Value: "en-US"},
+	{Name: "Português (Brasil)", Value: "pt-BR"},
+	{Name: "Português (Portugal)", Value: "pt-PT"},
+	{Name: "Español (España)", Value: "es-ES"},
+	{Name: "Español (México)", Value: "es-MX"},
+	{Name: "Français", Value: "fr-FR"},
+	{Name: "Deutsch", Value: "de-DE"},
+	{Name: "Italiano", Value: "it-IT"},
+}
+
+// languageDisplay mostra o nome do idioma escolhido em /setup language; vazio segue o idioma padrão do bot
+func languageDisplay(language string) string {
+	if language == "" {
+		return "Bot default"
+	}
+	for _, choice := range tmdbLanguages {
+		if choice.Value == language {
+			return choice.Name
+		}
+	}
+	return language
+}
+
+func discussionDisplay(forumID string) string {
+	if forumID == "" {
+		return "A thread is opened on the confirmation message of each movie"
+	}
+	return fmt.Sprintf("Each confirmed movie gets a post in <#%s>", forumID)
+}
+
+func roleDisplay(roleID, fallback string) string {
+	if roleID == "" {
+		return fallback
+	}
+	return fmt.Sprintf("<@&%s>", roleID)
+}
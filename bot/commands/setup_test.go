@@ -0,0 +1,298 @@
+package commands
+
+import (
+	"clapper/database"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSetup(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "setup channels saves the suggestion channel",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("channels",
+					option("suggestion_channel", discordgo.ApplicationCommandOptionChannel, testChannelID),
+					option("event_channel", discordgo.ApplicationCommandOptionChannel, testEventChannelID),
+					stringOption("timezone", "America/Sao_Paulo"),
+				))
+			},
+			want: []string{"Bot Configuration Updated", "<#" + testChannelID + ">", "<#" + testEventChannelID + ">"},
+			check: func(t *testing.T, env *testEnv) {
+				config, _ := env.store.GetGuildConfig(testGuildID)
+				if config == nil || config.SuggestionChannelID != testChannelID || config.Timezone != "America/Sao_Paulo" {
+					t.Errorf("unexpected config: %+v", config)
+				}
+			},
+		},
+		{
+			name: "setup channels rejects non-administrators",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "setup", subcommand("channels",
+					option("suggestion_channel", discordgo.ApplicationCommandOptionChannel, testChannelID),
+				))
+			},
+			want: []string{"Only administrators can configure the bot"},
+		},
+		{
+			name: "setup channels rejects unknown timezones",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("channels",
+					option("suggestion_channel", discordgo.ApplicationCommandOptionChannel, testChannelID),
+					stringOption("timezone", "Mars/Olympus"),
+				))
+			},
+			want: []string{"Unknown timezone"},
+		},
+		{
+			name: "setup channels rejects inaccessible channels",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("channels",
+					option("suggestion_channel", discordgo.ApplicationCommandOptionChannel, "999"),
+				))
+			},
+			want: []string{"Could not access the specified channel"},
+		},
+		{
+			name:  "setup roles saves host and suggester roles",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("roles",
+					option("host_role", discordgo.ApplicationCommandOptionRole, "301"),
+					option("suggester_role", discordgo.ApplicationCommandOptionRole, testSuggesterRole),
+				))
+			},
+			want: []string{"Roles Updated", "<@&301>", "<@&" + testSuggesterRole + ">"},
+			check: func(t *testing.T, env *testEnv) {
+				config, _ := env.store.GetGuildConfig(testGuildID)
+				if config.HostRoleID != "301" || config.SuggesterRoleID != testSuggesterRole {
+					t.Errorf("unexpected roles: %+v", config)
+				}
+			},
+		},
+		{
+			name:  "setup roles clear removes the roles",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("roles",
+					option("clear", discordgo.ApplicationCommandOptionBoolean, true),
+				))
+			},
+			want: []string{"Administrators only", "Everyone"},
+		},
+		{
+			name: "setup roles requires channels first",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("roles"))
+			},
+			want: []string{"Run `/setup channels` first"},
+		},
+		{
+			name: "config on an unconfigured server",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "config")
+			},
+			want: []string{"has not been configured yet"},
+		},
+		{
+			name:  "config shows the configuration",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "config")
+			},
+			want: []string{"Test Server Configuration", "<#" + testChannelID + ">", "<@&" + testHostRoleID + ">", "Picking Strategy", "Uniform"},
+		},
+		{
+			name:  "setup picking saves the strategy",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("picking",
+					stringOption("strategy", database.PickStrategyRoundRobin),
+				))
+			},
+			want: []string{"Picking Strategy Updated", "Fair rotation between suggesters"},
+			check: func(t *testing.T, env *testEnv) {
+				if config, _ := env.store.GetGuildConfig(testGuildID); config.PickStrategy != database.PickStrategyRoundRobin {
+					t.Errorf("unexpected strategy: %q", config.PickStrategy)
+				}
+			},
+		},
+		{
+			name: "setup picking requires channels first",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("picking",
+					stringOption("strategy", database.PickStrategyWeightedByWait),
+				))
+			},
+			want: []string{"Run `/setup channels` first"},
+		},
+		{
+			name:  "setup vetoes saves the allowance",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("vetoes",
+					intOption("per_season", 2),
+					boolOption("ban_vetoed", true),
+				))
+			},
+			want: []string{"Vetoes Updated", "2 vetoes per member each season", "banned until the season ends"},
+			check: func(t *testing.T, env *testEnv) {
+				if config, _ := env.store.GetGuildConfig(testGuildID); config.VetoesPerSeason != 2 || !config.BanVetoed {
+					t.Errorf("unexpected veto settings: %d, %v", config.VetoesPerSeason, config.BanVetoed)
+				}
+			},
+		},
+		{
+			name: "setup vetoes keeps omitted options",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveVetoSettings(testGuildID, 3, true)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("vetoes", intOption("per_season", 1)))
+			},
+			want: []string{"1 veto per member each season", "banned until the season ends"},
+		},
+		{
+			name:  "setup limits saves the quota and cooldown",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("limits",
+					intOption("max_open", 3),
+					stringOption("cooldown", "1d"),
+				))
+			},
+			want: []string{"Suggestion Limits Updated", "3 open suggestions per member · 1 suggestion every 1d"},
+			check: func(t *testing.T, env *testEnv) {
+				config, _ := env.store.GetGuildConfig(testGuildID)
+				if config.MaxOpenSuggestions != 3 || config.SuggestionCooldown != 24*time.Hour {
+					t.Errorf("unexpected limits: %d, %s", config.MaxOpenSuggestions, config.SuggestionCooldown)
+				}
+			},
+		},
+		{
+			name: "setup limits turns the cooldown off",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveSuggestionLimits(testGuildID, 3, time.Hour)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("limits", stringOption("cooldown", "off")))
+			},
+			want: []string{"3 open suggestions per member"},
+			check: func(t *testing.T, env *testEnv) {
+				if config, _ := env.store.GetGuildConfig(testGuildID); config.SuggestionCooldown != 0 {
+					t.Errorf("cooldown should be disabled, got %s", config.SuggestionCooldown)
+				}
+			},
+		},
+		{
+			name:  "setup limits rejects invalid cooldowns",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("limits", stringOption("cooldown", "90d")))
+			},
+			want: []string{"Invalid cooldown \"90d\": the cooldown must be between 1 minute and 30 days."},
+		},
+
+		{
+			name:  "setup moderation turns the approval queue on",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("moderation",
+					boolOption("require_approval", true),
+					option("mod_channel", discordgo.ApplicationCommandOptionChannel, testModChannelID),
+				))
+			},
+			want: []string{"Moderation Updated", "wait for a host's approval in <#" + testModChannelID + ">"},
+			check: func(t *testing.T, env *testEnv) {
+				if config, _ := env.store.GetGuildConfig(testGuildID); !config.RequireApproval || config.ModChannelID != testModChannelID {
+					t.Errorf("unexpected moderation settings: %v, %q", config.RequireApproval, config.ModChannelID)
+				}
+			},
+		},
+		{
+			name:  "setup moderation requires a mod channel",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("moderation", boolOption("require_approval", true)))
+			},
+			want: []string{"Choose a `mod_channel`"},
+		},
+		{
+			name: "config shows the moderation queue",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.pending(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "config")
+			},
+			want: []string{"Moderation", "<#" + testModChannelID + ">", "1 suggestion awaiting approval"},
+		},
+
+		{
+			name:  "setup discussions uses a forum",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("discussions",
+					option("forum", discordgo.ApplicationCommandOptionChannel, testForumID)))
+			},
+			want: []string{"Discussions Updated", "gets a post in <#" + testForumID + ">"},
+			check: func(t *testing.T, env *testEnv) {
+				if config, _ := env.store.GetGuildConfig(testGuildID); config.DiscussionForumID != testForumID {
+					t.Errorf("forum = %q, want %q", config.DiscussionForumID, testForumID)
+				}
+			},
+		},
+		{
+			name:  "setup discussions rejects channels that are not forums",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("discussions",
+					option("forum", discordgo.ApplicationCommandOptionChannel, testChannelID)))
+			},
+			want: []string{"pick a forum channel"},
+		},
+		{
+			name: "setup discussions clear goes back to threads on the confirmation",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveDiscussionSettings(testGuildID, testForumID)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("discussions", boolOption("clear", true)))
+			},
+			want: []string{"thread is opened on the confirmation message"},
+		},
+		{
+			name:  "setup language saves the language and loads its genres",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "setup", subcommand("language", stringOption("language", "pt-BR")))
+			},
+			want: []string{"Language Updated", "Português (Brasil)", "/refreshmetadata all: True"},
+			check: func(t *testing.T, env *testEnv) {
+				if config, _ := env.store.GetGuildConfig(testGuildID); config.Language != "pt-BR" {
+					t.Errorf("language = %q, want pt-BR", config.Language)
+				}
+				if got := env.handlers.tmdb.FormatGenres("pt-BR", movieAlien.GenreIDs); got != "Terror, Ficção científica" {
+					t.Errorf("expected the pt-BR genres to be loaded, got %q", got)
+				}
+			},
+		},
+		{
+			name: "config shows the language",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveLanguage(testGuildID, "pt-BR")
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "config")
+			},
+			want: []string{"Language", "Português (Brasil)"},
+		},
+	})
+}
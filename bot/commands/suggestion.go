@@ -2,8 +2,12 @@ package commands
 
 import (
 	"clapper/database"
+	"clapper/links"
 	"clapper/tmdb"
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,7 +16,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func (h *Handlers) HandleSuggestion(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleSuggestion(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -25,6 +29,17 @@ func (h *Handlers) HandleSuggestion(s *discordgo.Session, i *discordgo.Interacti
 		return
 	}
 
+	if !h.authorize(s, i, permissionSuggest) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ You don't have the role required to suggest movies in this server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -43,126 +58,487 @@ func (h *Handlers) HandleSuggestion(s *discordgo.Session, i *discordgo.Interacti
 
 	if guildConfig == nil {
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: ptrString("❌ This server has not been configured yet!\n\nAn administrator needs to run `/setup` to configure the suggestion channel before movies can be suggested."),
+			Content: ptrString("❌ This server has not been configured yet!\n\nAn administrator needs to run `/setup channels` to configure the suggestion channel before movies can be suggested."),
 		})
 		return
 	}
 
-	options := i.ApplicationCommandData().Options
-	input := options[0].StringValue()
+	if msg := h.suggestionLimitMessage(s, i, guildConfig); msg != "" {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &msg,
+		})
+		return
+	}
 
-	tmdbID := extractTMDBID(input)
+	var input string
+	mediaType := database.MediaMovie
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "movie_name":
+			input = opt.StringValue()
+		case "type":
+			mediaType = opt.StringValue()
+		}
+	}
 
 	var movie *tmdb.Movie
 
-	if tmdbID > 0 {
-		movie, err = h.tmdb.GetMovieByID(tmdbID)
-		if err != nil || movie == nil {
-			msg := fmt.Sprintf("❌ Could not find a movie with TMDB ID %d. Please check the link and try again.", tmdbID)
+	ctx, cancel := tmdbContext()
+	defer cancel()
+
+	// Links do TMDB, IMDb e Letterboxd viram o ID do título; qualquer outro texto é buscado pelo nome.
+	// O tipo do link vale mais que a opção type: um link de série sempre sugere a série.
+	link, isLink, err := h.resolver.Resolve(ctx, input)
+	if isLink && err != nil {
+		notFound := "❌ Could not find a movie or TV series on TMDB for that link. Please check the link or search by title instead."
+		msg := notFound
+		if !errors.Is(err, links.ErrNotFound) {
+			msg = tmdbErrorMessage(err, notFound)
+		}
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &msg,
+		})
+		return
+	}
+
+	if isLink {
+		mediaType = link.MediaType
+		movie, err = h.getTitle(ctx, mediaType, link.TMDBID, guildConfig.Language)
+		if err != nil {
+			msg := tmdbErrorMessage(err, fmt.Sprintf("❌ Could not find a %s with TMDB ID %d. Please check the link and try again.", mediaNoun(mediaType), link.TMDBID))
 			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 				Content: &msg,
 			})
 			return
 		}
 	} else {
-		movie, err = h.tmdb.SearchMovie(input)
-		if err != nil || movie == nil {
-			msg := fmt.Sprintf("❌ Could not find a movie named \"%s\". Please check the spelling and try again.", input)
+		notFound := fmt.Sprintf("❌ Could not find a %s named \"%s\". Please check the spelling and try again.", mediaNoun(mediaType), input)
+		movies, err := h.searchTitles(ctx, mediaType, input, guildConfig.Language)
+		if err != nil || len(movies) == 0 {
+			msg := notFound
+			if err != nil {
+				msg = tmdbErrorMessage(err, notFound)
+			}
 			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 				Content: &msg,
 			})
 			return
 		}
+
+		// Vários resultados: o usuário escolhe qual título quis dizer antes de postar
+		if len(movies) > 1 {
+			h.showSuggestionChoices(s, i, input, guildConfig.Language, mediaType, movies)
+			return
+		}
+
+		movie = &movies[0]
 	}
 
-	exists, _ := h.db.MovieAlreadySuggested(guildID, movie.ID)
-	if exists {
-		suggester, _ := h.db.GetMovieSuggester(guildID, movie.ID)
-		msg := fmt.Sprintf("⚠️ **%s** has already been suggested by **%s** in this server!", movie.Title, suggester)
+	h.submitSuggestion(s, i, guildConfig, mediaType, movie)
+}
+
+// getTitle busca os detalhes de um filme ou de uma série, conforme mediaType
+func (h *Handlers) getTitle(ctx context.Context, mediaType string, tmdbID int, language string) (*tmdb.Movie, error) {
+	if mediaType == database.MediaTV {
+		return h.tmdb.GetTVByID(ctx, tmdbID, language)
+	}
+	return h.tmdb.GetMovieByID(ctx, tmdbID, language)
+}
+
+// searchTitles busca filmes ou séries pelo nome, conforme mediaType
+func (h *Handlers) searchTitles(ctx context.Context, mediaType, name, language string) ([]tmdb.Movie, error) {
+	if mediaType == database.MediaTV {
+		return h.tmdb.SearchTV(ctx, name, language)
+	}
+	return h.tmdb.SearchMovies(ctx, name, language)
+}
+
+// mediaNoun é o nome do tipo de título usado nas mensagens
+func mediaNoun(mediaType string) string {
+	if mediaType == database.MediaTV {
+		return "TV series"
+	}
+	return "movie"
+}
+
+// HandleSuggestionSelect trata a escolha feita no menu de desambiguação do /suggestion
+func (h *Handlers) HandleSuggestionSelect(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		return
+	}
+
+	if !h.authorize(s, i, permissionSuggest) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ You don't have the role required to suggest movies in this server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+
+	// Menus enviados antes das séries não têm o tipo no fim e são sempre de filmes
+	customID := i.MessageComponentData().CustomID
+	re := regexp.MustCompile(`suggestion_select_([^_]+)(?:_(movie|tv))?$`)
+	matches := re.FindStringSubmatch(customID)
+
+	values := i.MessageComponentData().Values
+	if len(matches) < 2 || len(values) == 0 {
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: &msg,
+			Content:    ptrString("❌ An error occurred. Please try again."),
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
 		})
 		return
 	}
 
-	year := "Unknown"
-	if movie.ReleaseDate != "" {
-		parts := strings.Split(movie.ReleaseDate, "-")
-		if len(parts) > 0 {
-			year = parts[0]
-		}
+	if matches[1] != guildID {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    ptrString("❌ This selection is for a different server."),
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
+		})
+		return
+	}
+
+	guildConfig, err := h.db.GetGuildConfig(guildID)
+	if err != nil || guildConfig == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    ptrString("❌ This server has not been configured yet!\n\nAn administrator needs to run `/setup channels` to configure the suggestion channel before movies can be suggested."),
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
+		})
+		return
 	}
 
-	genres := tmdb.FormatGenres(movie.GenreIDs)
+	// O menu pode ter ficado aberto enquanto o membro sugeria outro filme, então os limites são conferidos de novo
+	if msg := h.suggestionLimitMessage(s, i, guildConfig); msg != "" {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    &msg,
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
+		})
+		return
+	}
 
-	overview := movie.Overview
-	if len(overview) > 300 {
-		overview = overview[:300] + "..."
+	mediaType := database.MediaMovie
+	if matches[2] != "" {
+		mediaType = matches[2]
 	}
 
-	embed := &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("🎬 %s (%s)", movie.Title, year),
-		Description: overview,
-		Color:       0xFFD700,
-		Timestamp:   time.Now().Format(time.RFC3339),
-		Fields: []*discordgo.MessageEmbedField{
-			{Name: "⭐ Rating", Value: fmt.Sprintf("%.1f/10", movie.VoteAverage), Inline: true},
-			{Name: "🎭 Genres", Value: genres, Inline: true},
-			{Name: "📅 Release Year", Value: year, Inline: true},
-		},
-		Footer: &discordgo.MessageEmbedFooter{
-			Text:    fmt.Sprintf("Suggested by %s", i.Member.User.Username),
-			IconURL: i.Member.User.AvatarURL(""),
+	ctx, cancel := tmdbContext()
+	defer cancel()
+
+	tmdbID, _ := strconv.Atoi(values[0])
+	movie, err := h.getTitle(ctx, mediaType, tmdbID, guildConfig.Language)
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    ptrString(tmdbErrorMessage(err, fmt.Sprintf("❌ Could not find a %s with TMDB ID %d. Please try again.", mediaNoun(mediaType), tmdbID))),
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
+		})
+		return
+	}
+
+	h.submitSuggestion(s, i, guildConfig, mediaType, movie)
+}
+
+// suggestionLimitMessage confere o limite de sugestões abertas e o intervalo entre sugestões do membro.
+// Devolve a mensagem explicando quando ele poderá sugerir de novo, ou "" se a sugestão é permitida.
+// Administradores não têm limites.
+func (h *Handlers) suggestionLimitMessage(s Responder, i *discordgo.InteractionCreate, config *database.GuildConfig) string {
+	if config.MaxOpenSuggestions <= 0 && config.SuggestionCooldown <= 0 {
+		return ""
+	}
+	if isAdministrator(s, i) {
+		return ""
+	}
+
+	open, lastSuggestedAt, err := h.db.GetUserSuggestionActivity(i.GuildID, i.Member.User.ID)
+	if err != nil {
+		return "❌ An error occurred while checking your suggestion limits. Please try again."
+	}
+	return limitMessage(config, open, lastSuggestedAt)
+}
+
+// limitMessage explica qual limite a atividade do membro atinge; "" quando nenhum
+func limitMessage(config *database.GuildConfig, open int, lastSuggestedAt time.Time) string {
+	if config.MaxOpenSuggestions > 0 && open >= config.MaxOpenSuggestions {
+		return fmt.Sprintf("⏳ You already have %d open suggestion%s and this server allows %d per member. "+
+			"You can suggest again once one of them is picked, or after removing one with `/removesuggestion`.",
+			open, pluralize(open), config.MaxOpenSuggestions)
+	}
+
+	if next := nextSuggestionAt(config, lastSuggestedAt); time.Now().Before(next) {
+		return fmt.Sprintf("⏳ This server allows one suggestion every %s. You can suggest again <t:%d:R> (<t:%d:f>).",
+			formatDuration(config.SuggestionCooldown), next.Unix(), next.Unix())
+	}
+
+	return ""
+}
+
+// nextSuggestionAt devolve quando termina o intervalo desde a última sugestão; zero se não há intervalo a cumprir
+func nextSuggestionAt(config *database.GuildConfig, lastSuggestedAt time.Time) time.Time {
+	if config.SuggestionCooldown <= 0 || lastSuggestedAt.IsZero() {
+		return time.Time{}
+	}
+	return lastSuggestedAt.Add(config.SuggestionCooldown)
+}
+
+func (h *Handlers) showSuggestionChoices(s Responder, i *discordgo.InteractionCreate, input, language, mediaType string, movies []tmdb.Movie) {
+	maxChoices := 5
+	if len(movies) < maxChoices {
+		maxChoices = len(movies)
+	}
+
+	embeds := []*discordgo.MessageEmbed{}
+	menuOptions := []discordgo.SelectMenuOption{}
+
+	for idx := 0; idx < maxChoices; idx++ {
+		movie := movies[idx]
+		year := releaseYear(movie.ReleaseDate)
+
+		overview := truncate(movie.Overview, 153)
+
+		embed := &discordgo.MessageEmbed{
+			Title:       fmt.Sprintf("%d. %s (%s)", idx+1, movie.Title, year),
+			Description: overview,
+			Color:       0x3498DB,
+		}
+
+		posterURL := h.tmdb.GetPosterURL(movie.PosterPath)
+		if posterURL != "" {
+			embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: posterURL}
+		}
+		embeds = append(embeds, embed)
+
+		menuOptions = append(menuOptions, discordgo.SelectMenuOption{
+			Label:       truncate(fmt.Sprintf("%d. %s (%s)", idx+1, movie.Title, year), 100),
+			Value:       strconv.Itoa(movie.ID),
+			Description: truncate(fmt.Sprintf("⭐ %.1f/10 • %s", movie.VoteAverage, h.tmdb.FormatGenres(language, movie.GenreIDs)), 100),
+		})
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    fmt.Sprintf("suggestion_select_%s_%s", i.GuildID, mediaType),
+					Placeholder: fmt.Sprintf("Choose the %s you meant", mediaNoun(mediaType)),
+					Options:     menuOptions,
+				},
+			},
 		},
 	}
 
-	posterURL := h.tmdb.GetPosterURL(movie.PosterPath)
-	if posterURL != "" {
-		embed.Image = &discordgo.MessageEmbedImage{URL: posterURL}
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    ptrString(fmt.Sprintf("🔎 Found several %s matching \"%s\". Which one do you want to suggest?", mediaPlural(mediaType), input)),
+		Embeds:     &embeds,
+		Components: &components,
+	})
+}
+
+// submitSuggestion posta o filme ou a série no canal configurado e salva a sugestão, editando a resposta original
+func (h *Handlers) submitSuggestion(s Responder, i *discordgo.InteractionCreate, guildConfig *database.GuildConfig, mediaType string, movie *tmdb.Movie) {
+	guildID := i.GuildID
+
+	// Resultados de busca não trazem duração, temporadas nem gêneros completos; os detalhes vêm do cache quando possível
+	ctx, cancel := tmdbContext()
+	details, err := h.getTitle(ctx, mediaType, movie.ID, guildConfig.Language)
+	cancel()
+	if err == nil {
+		movie = details
 	}
 
-	// Post to configured suggestion channel
-	_, err = s.ChannelMessageSendEmbed(guildConfig.SuggestionChannelID, embed)
+	// Filmes e séries têm IDs independentes no TMDB, então o mesmo número pode existir nos dois tipos
+	exists, _ := h.db.MovieAlreadySuggested(guildID, mediaType, movie.ID)
+	if exists {
+		suggester, _ := h.db.GetMovieSuggester(guildID, mediaType, movie.ID)
+		msg := fmt.Sprintf("⚠️ **%s** has already been suggested by **%s** in this server!", movie.Title, suggester)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    &msg,
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
+		})
+		return
+	}
+
+	year := releaseYear(movie.ReleaseDate)
+
+	// Com a fila de moderação ligada a sugestão só fica pública depois de aprovada
+	status := database.SuggestionApproved
+	if guildConfig.RequireApproval {
+		status = database.SuggestionPending
+	}
+
+	suggestion := &database.Suggestion{
+		GuildID:       guildID,
+		MovieName:     movie.Title,
+		UserID:        i.Member.User.ID,
+		Username:      i.Member.User.Username,
+		SuggestedAt:   time.Now(),
+		TMDBID:        movie.ID,
+		Rating:        movie.VoteAverage,
+		Genres:        h.tmdb.FormatGenres(guildConfig.Language, movie.GenreIDs),
+		ReleaseYear:   year,
+		GenreIDs:      movie.GenreIDs,
+		Status:        status,
+		MovieMetadata: movieMetadata(mediaType, movie),
+	}
+
+	// Os limites são conferidos de novo na gravação: outra sugestão do membro pode ter entrado desde a primeira checagem
+	maxOpen, cooldown := guildConfig.MaxOpenSuggestions, guildConfig.SuggestionCooldown
+	if isAdministrator(s, i) {
+		maxOpen, cooldown = 0, 0
+	}
+	suggestionID, err := h.db.SaveSuggestionWithinLimits(suggestion, maxOpen, cooldown)
+	var limitErr *database.SuggestionLimitError
+	if errors.As(err, &limitErr) {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    ptrString(limitMessage(guildConfig, limitErr.Open, limitErr.LastSuggestedAt)),
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
+		})
+		return
+	}
 	if err != nil {
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: ptrString("❌ Could not post to the suggestion channel. The channel may have been deleted or the bot may not have permissions. Please contact an administrator to run `/setup` again."),
+			Content:    ptrString("❌ An error occurred while saving your suggestion. Please try again later."),
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
 		})
 		return
 	}
+	suggestion.ID = int(suggestionID)
 
-	_, err = h.db.SaveSuggestion(&database.Suggestion{
-		GuildID:     guildID,
-		MovieName:   movie.Title,
-		UserID:      i.Member.User.ID,
-		Username:    i.Member.User.Username,
-		TMDBID:      movie.ID,
-		Rating:      movie.VoteAverage,
-		Genres:      genres,
-		ReleaseYear: year,
-	})
+	embed := h.suggestionEmbed(suggestion)
+
+	if status == database.SuggestionPending {
+		embed.Color = 0xE67E22
+		embed.Footer.Text += " · awaiting approval"
+		_, err = s.ChannelMessageSendComplex(guildConfig.ModChannelID, &discordgo.MessageSend{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: moderationComponents(guildID, suggestion.ID),
+		})
+		if err != nil {
+			// Sem a mensagem na fila nenhum moderador consegue aprovar, então desfazemos a sugestão
+			h.db.DiscardSuggestion(suggestion.ID)
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content:    ptrString("❌ Could not post to the moderation channel. Please contact an administrator to run `/setup moderation` again."),
+				Embeds:     &[]*discordgo.MessageEmbed{},
+				Components: &[]discordgo.MessageComponent{},
+			})
+			return
+		}
+
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    ptrString(fmt.Sprintf("📨 **%s** was sent to the moderators for approval. It will be posted in <#%s> once approved.", movie.Title, guildConfig.SuggestionChannelID)),
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
+		})
+		return
+	}
 
+	// Post to configured suggestion channel
+	components := voteComponents(guildID, suggestion.ID, 0, 0)
+	message, err := s.ChannelMessageSendComplex(guildConfig.SuggestionChannelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
 	if err != nil {
+		// Sem a mensagem no canal ninguém consegue votar, então desfazemos a sugestão
+		h.db.DiscardSuggestion(suggestion.ID)
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: ptrString("❌ An error occurred while saving your suggestion. Please try again later."),
+			Content:    ptrString("❌ Could not post to the suggestion channel. The channel may have been deleted or the bot may not have permissions. Please contact an administrator to run `/setup channels` again."),
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
 		})
 		return
 	}
 
+	// A mensagem é editada sempre que o filme é selecionado, avaliado ou removido
+	if err := h.db.SetSuggestionMessage(suggestion.ID, message.ChannelID, message.ID, suggestionPostHash(embed, components)); err != nil {
+		log.Printf("Erro ao gravar a mensagem da sugestão %d: %v", suggestion.ID, err)
+	}
+
 	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Content: ptrString(fmt.Sprintf("✅ Successfully suggested **%s**! Your suggestion has been posted in <#%s>.", movie.Title, guildConfig.SuggestionChannelID)),
+		Content:    ptrString(fmt.Sprintf("✅ Successfully suggested **%s**! Your suggestion has been posted in <#%s>.", movie.Title, guildConfig.SuggestionChannelID)),
+		Embeds:     &[]*discordgo.MessageEmbed{},
+		Components: &[]discordgo.MessageComponent{},
 	})
 }
 
-func extractTMDBID(input string) int {
-	re := regexp.MustCompile(`themoviedb.org/movie/(\d+)`)
-	matches := re.FindStringSubmatch(input)
+// suggestionEmbed monta o cartão da sugestão postado no canal de sugestões e na fila de moderação
+func (h *Handlers) suggestionEmbed(suggestion *database.Suggestion) *discordgo.MessageEmbed {
+	// As sinopses vêm no idioma do servidor; o corte por runas não quebra caracteres acentuados
+	overview := truncate(suggestion.Overview, 303)
+	if suggestion.OriginalTitle != "" && suggestion.OriginalTitle != suggestion.MovieName {
+		overview = fmt.Sprintf("*%s*\n\n%s", suggestion.OriginalTitle, overview)
+	}
 
-	if len(matches) > 1 {
-		id, err := strconv.Atoi(matches[1])
-		if err == nil {
-			return id
-		}
+	icon, yearLabel := "🎬", "📅 Release Year"
+	if suggestion.IsTV() {
+		icon, yearLabel = "📺", "📅 First Aired"
 	}
 
-	return 0
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s %s (%s)", icon, suggestion.MovieName, suggestion.ReleaseYear),
+		Description: overview,
+		Color:       0xFFD700,
+		Timestamp:   suggestion.SuggestedAt.Format(time.RFC3339),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "⭐ Rating", Value: fmt.Sprintf("%.1f/10", suggestion.Rating), Inline: true},
+			{Name: "🎭 Genres", Value: suggestion.Genres, Inline: true},
+			{Name: yearLabel, Value: suggestion.ReleaseYear, Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Suggested by %s", suggestion.Username),
+		},
+	}
+
+	if episodes := formatEpisodes(suggestion.Seasons, suggestion.Episodes); suggestion.IsTV() && episodes != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "📺 Episodes", Value: episodes, Inline: true})
+	}
+
+	if posterURL := h.tmdb.GetPosterURL(suggestion.PosterPath); posterURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: posterURL}
+	}
+	return embed
+}
+
+// mediaPlural é o plural de mediaNoun
+func mediaPlural(mediaType string) string {
+	if mediaType == database.MediaTV {
+		return "TV series"
+	}
+	return "movies"
+}
+
+// formatEpisodes resume o tamanho de uma série, como "3 seasons, 24 episodes"; "" quando o TMDB não informou
+func formatEpisodes(seasons, episodes int) string {
+	var parts []string
+	if seasons > 0 {
+		parts = append(parts, fmt.Sprintf("%d season%s", seasons, pluralize(seasons)))
+	}
+	if episodes > 0 {
+		parts = append(parts, fmt.Sprintf("%d episode%s", episodes, pluralize(episodes)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func releaseYear(releaseDate string) string {
+	year := "Unknown"
+	if releaseDate != "" {
+		parts := strings.Split(releaseDate, "-")
+		if len(parts) > 0 {
+			year = parts[0]
+		}
+	}
+	return year
 }
\ No newline at end of file
@@ -0,0 +1,422 @@
+package commands
+
+import (
+	"clapper/database"
+	"clapper/tmdb"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSuggestion(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "suggestion on an unconfigured server",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"has not been configured yet"},
+		},
+		{
+			name:  "suggestion with a single match posts it with vote buttons",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"Successfully suggested **Heat**"},
+			check: func(t *testing.T, env *testEnv) {
+				if len(env.responder.sent) != 1 || env.responder.sent[0].channelID != testChannelID {
+					t.Fatalf("expected one message in the suggestion channel, got %+v", env.responder.sent)
+				}
+				suggestions, _ := env.store.GetAllSuggestions(testGuildID, "")
+				if len(suggestions) != 1 || suggestions[0].Runtime != movieHeat.Runtime || len(suggestions[0].GenreIDs) != 2 {
+					t.Errorf("unexpected suggestions: %+v", suggestions)
+				}
+			},
+		},
+		{
+			name: "suggestion uses the server language",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveLanguage(testGuildID, "pt-BR")
+				env.handlers.tmdb.LoadGenres(context.Background(), "pt-BR")
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"Successfully suggested **Fogo contra Fogo**"},
+			check: func(t *testing.T, env *testEnv) {
+				suggestions, _ := env.store.GetAllSuggestions(testGuildID, "")
+				if len(suggestions) != 1 || suggestions[0].OriginalTitle != "Heat" || suggestions[0].Genres != "Ação, Crime" {
+					t.Fatalf("expected the title and genres in pt-BR, got %+v", suggestions)
+				}
+				if text := strings.Join(embedText(env.responder.sent[0].data.Embeds), "\n"); !strings.Contains(text, "*Heat*") {
+					t.Errorf("expected the original title in the post, got %q", text)
+				}
+			},
+		},
+		{
+			name:  "suggestion with several matches shows a select menu",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Alien"))
+			},
+			want: []string{"Found several movies", "1. Alien (1979)", "2. Aliens (1986)"},
+			check: func(t *testing.T, env *testEnv) {
+				if ids := env.responder.customIDs(); len(ids) != 1 || ids[0] != "suggestion_select_"+testGuildID+"_movie" {
+					t.Errorf("unexpected components: %v", ids)
+				}
+				if count, _ := env.store.GetAllSuggestionsCount(testGuildID); count != 0 {
+					t.Errorf("nothing should be saved before choosing, got %d suggestions", count)
+				}
+			},
+		},
+		{
+			name:  "suggestion resolves TMDB links by ID",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "https://www.themoviedb.org/movie/496243-parasite"))
+			},
+			want: []string{"Successfully suggested **Parasite**"},
+		},
+		{
+			name:  "suggestion with an IMDb link",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "https://www.imdb.com/title/tt0113277/?ref_=fn_al_tt_1"))
+			},
+			want: []string{"Successfully suggested **Heat**"},
+		},
+		{
+			name:  "suggestion with an IMDb link that TMDB does not know",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "https://www.imdb.com/title/tt0903747/"))
+			},
+			want: []string{"Could not find a movie or TV series on TMDB for that link"},
+			check: func(t *testing.T, env *testEnv) {
+				if suggestions, _ := env.store.GetAllSuggestions(testGuildID, ""); len(suggestions) != 0 {
+					t.Errorf("expected no suggestion, got %+v", suggestions)
+				}
+			},
+		},
+		{
+			name:  "suggestion with an unknown TMDB ID",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "https://www.themoviedb.org/movie/999999"))
+			},
+			want: []string{"Could not find a movie with TMDB ID 999999"},
+		},
+		{
+			name:  "suggestion of a TV series by name",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Chernobyl"), stringOption("type", database.MediaTV))
+			},
+			want: []string{"Successfully suggested **Chernobyl**"},
+			check: func(t *testing.T, env *testEnv) {
+				suggestions, _ := env.store.GetAllSuggestions(testGuildID, "")
+				if len(suggestions) != 1 || suggestions[0].MediaType != database.MediaTV || suggestions[0].Seasons != 1 || suggestions[0].Episodes != 5 {
+					t.Fatalf("expected Chernobyl saved as a TV series, got %+v", suggestions)
+				}
+				text := strings.Join(embedText(env.responder.sent[0].data.Embeds), "\n")
+				for _, want := range []string{"📺 Chernobyl (2019)", "1 season, 5 episodes", "First Aired"} {
+					if !strings.Contains(text, want) {
+						t.Errorf("post does not contain %q:\n%s", want, text)
+					}
+				}
+			},
+		},
+		{
+			name: "suggestion of a TV series with the same TMDB ID as a suggested movie",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "https://www.themoviedb.org/tv/949-heat-wave"))
+			},
+			want: []string{"Successfully suggested **Heat Wave**"},
+			check: func(t *testing.T, env *testEnv) {
+				if count, _ := env.store.GetAllSuggestionsCount(testGuildID); count != 2 {
+					t.Errorf("expected the movie and the series to coexist, got %d suggestions", count)
+				}
+			},
+		},
+		{
+			name: "suggestion of an already suggested TV series",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(tmdb.Movie{ID: 87108, MediaType: tmdb.MediaTV, Title: "Chernobyl", ReleaseDate: "2019-05-06"}, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Chernobyl"), stringOption("type", database.MediaTV))
+			},
+			want: []string{"**Chernobyl** has already been suggested by **other**"},
+		},
+		{
+			name:  "suggestion with an IMDb link to a TV series ignores the type option",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "https://www.imdb.com/title/tt7366338/"), stringOption("type", database.MediaMovie))
+			},
+			want: []string{"Successfully suggested **Chernobyl**"},
+			check: func(t *testing.T, env *testEnv) {
+				if suggestions, _ := env.store.GetAllSuggestions(testGuildID, ""); len(suggestions) != 1 || !suggestions[0].IsTV() {
+					t.Errorf("expected Chernobyl saved as a TV series, got %+v", suggestions)
+				}
+			},
+		},
+		{
+			name:  "suggestion of an unknown TV series",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Alien"), stringOption("type", database.MediaTV))
+			},
+			want: []string{"Could not find a TV series named \"Alien\""},
+		},
+		{
+			name:  "suggestion while TMDB is rate limiting",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", fmt.Sprintf("https://www.themoviedb.org/movie/%d", rateLimitedTMDBID)))
+			},
+			want: []string{"TMDB is receiving too many requests"},
+		},
+		{
+			name:  "suggestion with an unknown title",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Nonexistent"))
+			},
+			want: []string{"Could not find a movie named \"Nonexistent\""},
+		},
+		{
+			name: "suggestion of a movie that was already suggested",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"already been suggested by **other**"},
+		},
+		{
+			name: "suggestion of a movie that was rejected before",
+			setup: func(env *testEnv) {
+				env.configure()
+				id := env.suggest(movieHeat, testOther)
+				env.store.findSuggestion("", id).Status = database.SuggestionRejected
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"Successfully suggested **Heat**"},
+		},
+		{
+			name: "suggestion undone when the channel post fails",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.responder.sendErr = fmt.Errorf("HTTP 403 Forbidden")
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"Could not post to the suggestion channel"},
+			check: func(t *testing.T, env *testEnv) {
+				if count, _ := env.store.GetAllSuggestionsCount(testGuildID); count != 0 {
+					t.Errorf("suggestion should have been removed, got %d", count)
+				}
+			},
+		},
+		{
+			name: "suggestion requires the suggester role when configured",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveRoleSettings(testGuildID, testHostRoleID, testSuggesterRole)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"don't have the role required to suggest"},
+		},
+		{
+			name:  "suggestion select menu submits the chosen movie",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, "suggestion_select_"+testGuildID, fmt.Sprint(movieAliens.ID))
+			},
+			want: []string{"Successfully suggested **Aliens**"},
+		},
+		{
+			name: "suggestion select menu submits the chosen TV series",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, "suggestion_select_"+testGuildID+"_tv", "949")
+			},
+			want: []string{"Successfully suggested **Heat Wave**"},
+		},
+		{
+			name:  "suggestion select menu from another server",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, "suggestion_select_guild2", fmt.Sprint(movieAliens.ID))
+			},
+			want: []string{"different server"},
+		},
+		{
+			name: "suggestion over the open suggestion limit",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveSuggestionLimits(testGuildID, 2, 0)
+				env.suggest(movieAlien, testMember)
+				env.suggest(movieAliens, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"already have 2 open suggestions and this server allows 2 per member"},
+			check: func(t *testing.T, env *testEnv) {
+				if count, _ := env.store.GetAllSuggestionsCount(testGuildID); count != 2 {
+					t.Errorf("no suggestion should be saved, got %d", count)
+				}
+			},
+		},
+		{
+			name: "selected movies free an open suggestion slot",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveSuggestionLimits(testGuildID, 2, 0)
+				env.suggest(movieAlien, testMember)
+				env.selectMovie(movieAliens, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"Successfully suggested **Heat**"},
+		},
+		{
+			name: "suggestion during the cooldown shows when to try again",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveSuggestionLimits(testGuildID, 0, 12*time.Hour)
+				env.suggest(movieAlien, testMember)
+				env.store.findSuggestion("", env.ids["Alien"]).SuggestedAt = time.Now().Add(-2 * time.Hour)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"allows one suggestion every 12h", "You can suggest again <t:"},
+		},
+		{
+			name: "removing a suggestion does not reset the cooldown",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveSuggestionLimits(testGuildID, 0, 12*time.Hour)
+				env.store.RemoveSuggestion(env.suggest(movieAlien, testMember))
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"allows one suggestion every 12h"},
+		},
+		{
+			name: "suggestion after the cooldown",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveSuggestionLimits(testGuildID, 0, 12*time.Hour)
+				env.suggest(movieAlien, testMember)
+				env.store.findSuggestion("", env.ids["Alien"]).SuggestedAt = time.Now().Add(-13 * time.Hour)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"Successfully suggested **Heat**"},
+		},
+		{
+			name: "administrators are exempt from suggestion limits",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveSuggestionLimits(testGuildID, 1, 12*time.Hour)
+				env.suggest(movieAlien, testAdmin)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testAdmin, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"Successfully suggested **Heat**"},
+		},
+		{
+			name: "suggestion select menu checks the limits again",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveSuggestionLimits(testGuildID, 1, 0)
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, "suggestion_select_"+testGuildID, fmt.Sprint(movieAliens.ID))
+			},
+			want: []string{"already have 1 open suggestion and this server allows 1 per member"},
+		},
+		{
+			name: "suggestion select menu refuses members without the suggester role",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveRoleSettings(testGuildID, testHostRoleID, testSuggesterRole)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, "suggestion_select_"+testGuildID, fmt.Sprint(movieAliens.ID))
+			},
+			want: []string{"You don't have the role required to suggest movies"},
+			check: func(t *testing.T, env *testEnv) {
+				if resp := env.responder.responses[0]; resp.Data.Flags != discordgo.MessageFlagsEphemeral {
+					t.Errorf("expected an ephemeral refusal, got %+v", resp)
+				}
+			},
+		},
+	})
+}
+
+func TestSuggestionChoicesFitSelectMenuLimits(t *testing.T) {
+	env := newTestEnv(t)
+
+	var genreIDs []int
+	for _, genre := range tmdb.MovieGenres() {
+		genreIDs = append(genreIDs, genre.ID)
+	}
+	movies := []tmdb.Movie{
+		{ID: 1, Title: "Everything", ReleaseDate: "2022-03-25", VoteAverage: 7.8, GenreIDs: genreIDs},
+		{ID: 2, Title: "Everything Else", ReleaseDate: "2023-01-01", VoteAverage: 6.1, GenreIDs: genreIDs},
+		{ID: 3, Title: "A" + strings.Repeat("É", 120), ReleaseDate: "2024-01-01", VoteAverage: 5.0, GenreIDs: []int{18}},
+	}
+
+	env.handlers.showSuggestionChoices(env.responder, slashCommand(testMember, "suggestion", stringOption("movie_name", "Everything")), "Everything", "", database.MediaMovie, movies)
+
+	if len(env.responder.edits) != 1 || env.responder.edits[0].Components == nil {
+		t.Fatalf("expected the select menu, got %+v", env.responder.edits)
+	}
+	menu := (*env.responder.edits[0].Components)[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	for _, option := range menu.Options {
+		if n := len([]rune(option.Label)); n > 100 || !utf8.ValidString(option.Label) {
+			t.Errorf("label %q has %d characters or is not valid UTF-8", option.Label, n)
+		}
+		if option.Value == "3" {
+			continue
+		}
+		if n := len([]rune(option.Description)); n > 100 {
+			t.Errorf("description of %q has %d characters: %q", option.Label, n, option.Description)
+		}
+		if !strings.HasPrefix(option.Description, "⭐ ") || !strings.HasSuffix(option.Description, "...") {
+			t.Errorf("expected the rating and a shortened genre list, got %q", option.Description)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,214 @@
+package commands
+
+import (
+	"clapper/database"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// suggestionPost monta o cartão do canal de sugestões a partir do estado atual do filme: filmes
+// selecionados ganham a faixa verde e a nota da comunidade, os demais continuam com os botões de voto
+func (h *Handlers) suggestionPost(suggestion *database.Suggestion) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed := h.suggestionEmbed(suggestion)
+
+	if suggestion.SelectedAt == nil {
+		upvotes, downvotes, _ := h.db.GetVoteTotals(suggestion.ID)
+		return embed, voteComponents(suggestion.GuildID, suggestion.ID, upvotes, downvotes)
+	}
+
+	banner := fmt.Sprintf("✅ **Selected on <t:%d:D>**", suggestion.SelectedAt.Unix())
+	if embed.Description != "" {
+		banner += "\n\n" + embed.Description
+	}
+	embed.Description = banner
+	embed.Color = 0x2ECC71
+
+	// A nota da comunidade só aparece depois da revelação das avaliações às cegas
+	if h.hiddenRatings(suggestion.GuildID, suggestion.ID) != nil {
+		return embed, []discordgo.MessageComponent{}
+	}
+
+	if avgRating, reviewCount, err := h.db.GetAverageMovieRating(suggestion.GuildID, suggestion.ID); err == nil && reviewCount > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "📊 Community Rating",
+			Value:  fmt.Sprintf("%.1f/10 (%d review%s)", avgRating, reviewCount, pluralize(reviewCount)),
+			Inline: false,
+		})
+	}
+
+	return embed, []discordgo.MessageComponent{}
+}
+
+// suggestionPostHash resume o conteúdo de uma mensagem do canal de sugestões, para que a sincronização
+// na inicialização só edite as mensagens que mudaram desde a última escrita
+func suggestionPostHash(embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) string {
+	payload, err := json.Marshal(struct {
+		Embed      *discordgo.MessageEmbed      `json:"embed"`
+		Components []discordgo.MessageComponent `json:"components"`
+	}{embed, components})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// syncSuggestionPost reescreve a mensagem da sugestão depois de uma mudança de estado. Sugestões
+// sem mensagem (pendentes, rejeitadas ou anteriores a este recurso) são ignoradas.
+func (h *Handlers) syncSuggestionPost(s Responder, guildID string, suggestionID int) error {
+	suggestion, err := h.db.GetSuggestion(guildID, suggestionID)
+	if err != nil || suggestion == nil || suggestion.MessageID == "" {
+		return err
+	}
+
+	embed, components := h.suggestionPost(suggestion)
+	return h.editSuggestionPost(s, suggestion, embed, components)
+}
+
+func (h *Handlers) editSuggestionPost(s Responder, suggestion *database.Suggestion, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) error {
+	_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         suggestion.MessageID,
+		Channel:    suggestion.ChannelID,
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	})
+	if isUnknownMessage(err) {
+		// A mensagem foi apagada por alguém no Discord; não há mais o que manter em dia
+		return h.db.SetSuggestionMessage(suggestion.ID, "", "", "")
+	}
+	if err != nil {
+		return err
+	}
+	return h.db.SetSuggestionPostHash(suggestion.ID, suggestionPostHash(embed, components))
+}
+
+// syncSuggestionPosts é o syncSuggestionPost dos handlers que já responderam: os erros só vão para o log
+func (h *Handlers) syncSuggestionPosts(s Responder, guildID string, suggestionIDs ...int) {
+	for _, suggestionID := range suggestionIDs {
+		if err := h.syncSuggestionPost(s, guildID, suggestionID); err != nil {
+			log.Printf("Erro ao atualizar a mensagem da sugestão %d: %v", suggestionID, err)
+		}
+	}
+}
+
+// removeSuggestionPost apaga a mensagem de uma sugestão removida. Sem permissão para apagar,
+// o título é riscado e os botões de voto saem, para que ninguém vote num filme que não existe mais.
+func (h *Handlers) removeSuggestionPost(s Responder, suggestion *database.Suggestion) {
+	if suggestion.MessageID == "" {
+		return
+	}
+
+	err := s.ChannelMessageDelete(suggestion.ChannelID, suggestion.MessageID)
+	if err == nil || isUnknownMessage(err) {
+		return
+	}
+
+	embed := h.suggestionEmbed(suggestion)
+	embed.Title = fmt.Sprintf("~~%s~~", embed.Title)
+	embed.Description = "🗑️ This suggestion was removed."
+	embed.Color = 0x95A5A6
+	embed.Image = nil
+	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         suggestion.MessageID,
+		Channel:    suggestion.ChannelID,
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &[]discordgo.MessageComponent{},
+	})
+	if err != nil {
+		log.Printf("Erro ao riscar a mensagem da sugestão removida %d: %v", suggestion.ID, err)
+	}
+}
+
+// isUnknownMessage indica que a mensagem não existe mais no Discord
+func isUnknownMessage(err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) {
+		return false
+	}
+	if restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeUnknownMessage {
+		return true
+	}
+	return restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound
+}
+
+// suggestionPostSyncDelay espaça as edições da sincronização na inicialização para não estourar o limite do Discord
+var suggestionPostSyncDelay = time.Second
+
+// ReconcileSuggestionPosts corrige em segundo plano as mensagens do canal de sugestões que ficaram
+// desatualizadas enquanto o bot estava desligado ou quando uma edição anterior falhou
+func (h *Handlers) ReconcileSuggestionPosts(s Responder) {
+	h.background.Add(1)
+	go func() {
+		defer h.background.Done()
+		if err := h.reconcileSuggestionPosts(s); err != nil {
+			log.Printf("Erro ao sincronizar as mensagens do canal de sugestões: %v", err)
+		}
+	}()
+}
+
+// reconcileSuggestionPosts só edita as mensagens cujo conteúdo atual difere do que foi escrito por último.
+// Um Shutdown interrompe a sincronização entre uma edição e outra.
+func (h *Handlers) reconcileSuggestionPosts(s Responder) error {
+	posted, err := h.db.GetPostedSuggestions()
+	if err != nil {
+		return err
+	}
+
+	edited, failed := 0, 0
+	for _, post := range posted {
+		suggestion, err := h.db.GetSuggestion(post.GuildID, post.ID)
+		if err != nil || suggestion == nil {
+			log.Printf("Erro ao carregar a sugestão %d (%s): %v", post.ID, post.MovieName, err)
+			failed++
+			continue
+		}
+
+		embed, components := h.suggestionPost(suggestion)
+		if suggestionPostHash(embed, components) == post.PostHash {
+			continue
+		}
+
+		if edited+failed > 0 && !h.pause(suggestionPostSyncDelay) {
+			log.Printf("Sincronização das mensagens do canal de sugestões interrompida: %d editada(s), %d com erro", edited, failed)
+			return nil
+		}
+
+		if err := h.editSuggestionPost(s, suggestion, embed, components); err != nil {
+			log.Printf("Erro ao sincronizar a mensagem da sugestão %d (%s): %v", post.ID, post.MovieName, err)
+			failed++
+			continue
+		}
+		edited++
+	}
+	if edited+failed > 0 {
+		log.Printf("%d de %d mensagem(ns) do canal de sugestões estava(m) desatualizada(s): %d editada(s), %d com erro",
+			edited+failed, len(posted), edited, failed)
+	}
+	return nil
+}
+
+// pause espera d; devolve false se o Shutdown começou antes ou durante a espera
+func (h *Handlers) pause(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-h.stopping:
+		return false
+	case <-timer.C:
+	}
+	select {
+	case <-h.stopping:
+		return false
+	default:
+		return true
+	}
+}
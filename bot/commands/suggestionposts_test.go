@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestReconcileSuggestionPosts(t *testing.T) {
+	env := newTestEnv(t)
+	env.configure()
+	env.selectMovie(movieHeat, testMember)
+	heatPost := env.post(movieHeat)
+	env.suggest(movieAlien, testMember)
+	alienPost := env.post(movieAlien)
+	env.responder.missing[alienPost] = true
+	env.suggest(movieParasite, testMember)
+
+	if err := env.handlers.reconcileSuggestionPosts(env.responder); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(env.responder.messageEdits) != 1 {
+		t.Fatalf("expected only the Heat post to be edited, got %+v", env.responder.messageEdits)
+	}
+	if text, _ := env.postEdit(t, heatPost); !strings.Contains(text, "Selected on") {
+		t.Errorf("expected the Heat post to show the selection, got %q", text)
+	}
+
+	posted, _ := env.store.GetPostedSuggestions()
+	if len(posted) != 1 || posted[0].ID != env.ids["Heat"] {
+		t.Errorf("expected the deleted Alien post to be forgotten, got %+v", posted)
+	}
+}
+
+func TestReconcileSuggestionPostsSkipsUnchangedPosts(t *testing.T) {
+	env := newTestEnv(t)
+	env.configure()
+	heatID := env.suggest(movieHeat, testMember)
+	env.post(movieHeat)
+	env.suggest(movieAlien, testMember)
+	alienPost := env.post(movieAlien)
+
+	if err := env.handlers.reconcileSuggestionPosts(env.responder); err != nil {
+		t.Fatal(err)
+	}
+	if len(env.responder.messageEdits) != 2 {
+		t.Fatalf("expected posts without a stored state to be edited once, got %d edits", len(env.responder.messageEdits))
+	}
+
+	// Nada mudou desde a última escrita, então a próxima inicialização não edita nada
+	env.responder.messageEdits = nil
+	if err := env.handlers.reconcileSuggestionPosts(env.responder); err != nil {
+		t.Fatal(err)
+	}
+	if len(env.responder.messageEdits) != 0 {
+		t.Fatalf("expected no edits for unchanged posts, got %+v", env.responder.messageEdits)
+	}
+
+	// Um voto gravado com o bot desligado deixa só a mensagem do Heat desatualizada
+	env.store.SaveVote(testGuildID, heatID, testOther.User.ID, 1)
+	if err := env.handlers.reconcileSuggestionPosts(env.responder); err != nil {
+		t.Fatal(err)
+	}
+	if len(env.responder.messageEdits) != 1 || env.responder.messageEdits[0].ID == alienPost {
+		t.Fatalf("expected only the Heat post to be edited, got %+v", env.responder.messageEdits)
+	}
+}
+
+func TestReconcileSuggestionPostsStopsOnShutdown(t *testing.T) {
+	env := newTestEnv(t)
+	env.configure()
+	env.suggest(movieHeat, testMember)
+	env.post(movieHeat)
+	env.suggest(movieAlien, testMember)
+	env.post(movieAlien)
+
+	env.handlers.Shutdown()
+	if err := env.handlers.reconcileSuggestionPosts(env.responder); err != nil {
+		t.Fatal(err)
+	}
+	if len(env.responder.messageEdits) != 1 {
+		t.Fatalf("expected the sync to stop after the first edit, got %d edits", len(env.responder.messageEdits))
+	}
+}
+
+func TestSuggestionPosts(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name:  "suggestion remembers its channel message",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestion", stringOption("movie_name", "Heat"))
+			},
+			want: []string{"Successfully suggested **Heat**"},
+			check: func(t *testing.T, env *testEnv) {
+				posted, _ := env.store.GetPostedSuggestions()
+				if len(posted) != 1 || posted[0].ChannelID != testChannelID || posted[0].MessageID != "message-1" {
+					t.Errorf("expected the post to be stored, got %+v", posted)
+				}
+			},
+		},
+		{
+			name: "confirm marks the suggestion post as selected",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.post(movieHeat)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return modalSubmit(testHost, fmt.Sprintf("confirm_modal_%s_%s", testGuildID, env.ref(movieHeat)), map[string]string{"when": ""})
+			},
+			want: []string{"Selected Movie: Heat (1995)"},
+			check: func(t *testing.T, env *testEnv) {
+				text, edit := env.postEdit(t, "post-"+env.ref(movieHeat))
+				if !strings.Contains(text, "Selected on <t:") || len(*edit.Components) != 0 {
+					t.Errorf("expected the selected banner without vote buttons, got %q with %d rows", text, len(*edit.Components))
+				}
+			},
+		},
+		{
+			name: "ratemovie shows the community rating on the suggestion post",
+			setup: func(env *testEnv) {
+				env.selectMovie(movieHeat, testOther)
+				env.post(movieHeat)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "ratemovie", stringOption("movie_name", env.ref(movieHeat)), stringOption("rating", "8"))
+			},
+			want: []string{"Review added for Heat"},
+			check: func(t *testing.T, env *testEnv) {
+				if text, _ := env.postEdit(t, "post-"+env.ref(movieHeat)); !strings.Contains(text, "Community Rating\n8.0/10 (1 review)") {
+					t.Errorf("expected the community rating on the post, got %q", text)
+				}
+			},
+		},
+		{
+			name: "unselect brings the vote buttons back",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.selectMovie(movieHeat, testOther)
+				env.post(movieHeat)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "unselect", stringOption("movie", env.ref(movieHeat)), stringOption("reviews", "keep"))
+			},
+			want: []string{"**Heat** is back in the pool"},
+			check: func(t *testing.T, env *testEnv) {
+				text, edit := env.postEdit(t, "post-"+env.ref(movieHeat))
+				if strings.Contains(text, "Selected on") || len(*edit.Components) != 1 {
+					t.Errorf("expected the vote buttons without the banner, got %q", text)
+				}
+			},
+		},
+		{
+			name: "removesuggestion deletes the suggestion post",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.post(movieHeat)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "removesuggestion", stringOption("movie_name", env.ref(movieHeat)))
+			},
+			want: []string{"Successfully removed **Heat**"},
+			check: func(t *testing.T, env *testEnv) {
+				if !slices.Equal(env.responder.deleted, []string{"post-" + env.ref(movieHeat)}) {
+					t.Errorf("expected the post to be deleted, got %v", env.responder.deleted)
+				}
+			},
+		},
+		{
+			name: "removesuggestion strikes the post through when it cannot be deleted",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testMember)
+				env.post(movieHeat)
+				env.responder.deleteErr = fmt.Errorf("HTTP 403 Forbidden")
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "removesuggestion", stringOption("movie_name", env.ref(movieHeat)))
+			},
+			want: []string{"Successfully removed **Heat**"},
+			check: func(t *testing.T, env *testEnv) {
+				text, edit := env.postEdit(t, "post-"+env.ref(movieHeat))
+				if !strings.Contains(text, "~~🎬 Heat (1995)~~") || len(*edit.Components) != 0 {
+					t.Errorf("expected a struck-through post without buttons, got %q", text)
+				}
+			},
+		},
+	})
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func (h *Handlers) HandleSuggestions(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleSuggestions(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -29,35 +29,73 @@ func (h *Handlers) HandleSuggestions(s *discordgo.Session, i *discordgo.Interact
 		},
 	})
 
-	suggestions, err := h.db.GetAllSuggestions(guildID)
+	status := database.SuggestionApproved
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "status" {
+			status = opt.StringValue()
+		}
+	}
+
+	suggestions, err := h.db.GetAllSuggestions(guildID, statusFilter(status))
 	if err != nil || len(suggestions) == 0 {
+		msg := "❌ No movies have been suggested yet in this server!"
+		if status != suggestionStatusAll && status != database.SuggestionApproved {
+			msg = fmt.Sprintf("❌ There are no %s suggestions in this server.", suggestionStatusNames[status])
+		}
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: ptrString("❌ No movies have been suggested yet in this server!"),
+			Content: &msg,
 		})
 		return
 	}
 
-	h.showAllSuggestionsPage(s, i, suggestions, 0)
+	h.showAllSuggestionsPage(s, i, suggestions, 0, status)
+}
+
+// suggestionStatusAll é o valor da opção status do /suggestions que lista sugestões de qualquer status
+const suggestionStatusAll = "all"
+
+var suggestionStatusNames = map[string]string{
+	database.SuggestionApproved: "approved",
+	database.SuggestionPending:  "awaiting approval",
+	database.SuggestionRejected: "rejected",
+}
+
+func statusFilter(status string) string {
+	if status == suggestionStatusAll {
+		return ""
+	}
+	return status
+}
+
+// suggestionStatusDisplay resume o estado da sugestão nas telas paginadas do /suggestions e do /mysuggestions
+func suggestionStatusDisplay(movie database.Suggestion) (emoji, text string, color int) {
+	switch {
+	case movie.Status == database.SuggestionPending:
+		return "🕒", "Awaiting approval", 0xE67E22
+	case movie.Status == database.SuggestionRejected:
+		return "🚫", "Rejected by the moderators", 0xE74C3C
+	case movie.IsSelected:
+		return "✅", "Already selected", 0x2ECC71
+	}
+	return "⏳", "Not selected yet", 0x3498DB
 }
 
-func (h *Handlers) showAllSuggestionsPage(s *discordgo.Session, i *discordgo.InteractionCreate, suggestions []database.Suggestion, currentIndex int) {
+// rejectionField mostra o motivo da rejeição, quando houver, para quem não recebeu a DM
+func rejectionField(movie database.Suggestion) *discordgo.MessageEmbedField {
+	if movie.Status != database.SuggestionRejected || movie.RejectionReason == "" {
+		return nil
+	}
+	return &discordgo.MessageEmbedField{Name: "📝 Rejection Reason", Value: movie.RejectionReason, Inline: false}
+}
+
+func (h *Handlers) showAllSuggestionsPage(s Responder, i *discordgo.InteractionCreate, suggestions []database.Suggestion, currentIndex int, status string) {
 	if currentIndex < 0 || currentIndex >= len(suggestions) {
 		return
 	}
 
 	movie := suggestions[currentIndex]
 
-	tmdbMovie, _ := h.tmdb.GetMovieByID(movie.TMDBID)
-
-	statusEmoji := "⏳"
-	statusText := "Not selected yet"
-	embedColor := 0x3498DB
-
-	if movie.IsSelected {
-		statusEmoji = "✅"
-		statusText = "Already selected"
-		embedColor = 0x2ECC71
-	}
+	statusEmoji, statusText, embedColor := suggestionStatusDisplay(movie)
 
 	embed := &discordgo.MessageEmbed{
 		Title:       fmt.Sprintf("%s %s (%s)", statusEmoji, movie.MovieName, movie.ReleaseYear),
@@ -67,25 +105,27 @@ func (h *Handlers) showAllSuggestionsPage(s *discordgo.Session, i *discordgo.Int
 			{Name: "⭐ Rating", Value: fmt.Sprintf("%.1f/10", movie.Rating), Inline: true},
 			{Name: "🎭 Genres", Value: movie.Genres, Inline: true},
 			{Name: "📅 Year", Value: movie.ReleaseYear, Inline: true},
+			{Name: "🗳️ Votes", Value: formatVotes(movie.Upvotes, movie.Downvotes), Inline: false},
 		},
 		Footer: &discordgo.MessageEmbedFooter{
 			Text: fmt.Sprintf("Page %d of %d", currentIndex+1, len(suggestions)),
 		},
 	}
 
-	if tmdbMovie != nil {
-		posterURL := h.tmdb.GetPosterURL(tmdbMovie.PosterPath)
-		if posterURL != "" {
-			embed.Image = &discordgo.MessageEmbedImage{URL: posterURL}
-		}
+	if field := rejectionField(movie); field != nil {
+		embed.Fields = append(embed.Fields, field)
+	}
+
+	if posterURL := h.tmdb.GetPosterURL(movie.PosterPath); posterURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: posterURL}
+	}
 
-		if tmdbMovie.Overview != "" {
-			overview := tmdbMovie.Overview
-			if len(overview) > 200 {
-				overview = overview[:200] + "..."
-			}
-			embed.Description = fmt.Sprintf("%s\n\n%s", embed.Description, overview)
+	if movie.Overview != "" {
+		overview := movie.Overview
+		if len(overview) > 200 {
+			overview = overview[:200] + "..."
 		}
+		embed.Description = fmt.Sprintf("%s\n\n%s", embed.Description, overview)
 	}
 
 	components := []discordgo.MessageComponent{}
@@ -95,7 +135,7 @@ func (h *Handlers) showAllSuggestionsPage(s *discordgo.Session, i *discordgo.Int
 	prevButton := discordgo.Button{
 		Label:    "Previous",
 		Style:    discordgo.PrimaryButton,
-		CustomID: fmt.Sprintf("suggestions_prev_%s_%d", i.GuildID, currentIndex),
+		CustomID: fmt.Sprintf("suggestions_prev_%s_%d_%s", i.GuildID, currentIndex, status),
 		Emoji: &discordgo.ComponentEmoji{
 			Name: "⬅️",
 		},
@@ -105,7 +145,7 @@ func (h *Handlers) showAllSuggestionsPage(s *discordgo.Session, i *discordgo.Int
 	nextButton := discordgo.Button{
 		Label:    "Next",
 		Style:    discordgo.PrimaryButton,
-		CustomID: fmt.Sprintf("suggestions_next_%s_%d", i.GuildID, currentIndex),
+		CustomID: fmt.Sprintf("suggestions_next_%s_%d_%s", i.GuildID, currentIndex, status),
 		Emoji: &discordgo.ComponentEmoji{
 			Name: "➡️",
 		},
@@ -126,7 +166,7 @@ func (h *Handlers) showAllSuggestionsPage(s *discordgo.Session, i *discordgo.Int
 	})
 }
 
-func (h *Handlers) HandleSuggestionsPrev(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleSuggestionsPrev(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		return
@@ -137,7 +177,7 @@ func (h *Handlers) HandleSuggestionsPrev(s *discordgo.Session, i *discordgo.Inte
 	})
 
 	customID := i.MessageComponentData().CustomID
-	re := regexp.MustCompile(`suggestions_prev_([^_]+)_(\d+)`)
+	re := regexp.MustCompile(`suggestions_prev_([^_]+)_(\d+)(?:_([a-z]+))?`)
 	matches := re.FindStringSubmatch(customID)
 
 	if len(matches) < 3 {
@@ -145,8 +185,13 @@ func (h *Handlers) HandleSuggestionsPrev(s *discordgo.Session, i *discordgo.Inte
 	}
 
 	currentIndex, _ := strconv.Atoi(matches[2])
+	// Botões de mensagens antigas não trazem o status e sempre listaram tudo
+	status := matches[3]
+	if status == "" {
+		status = suggestionStatusAll
+	}
 
-	suggestions, err := h.db.GetAllSuggestions(guildID)
+	suggestions, err := h.db.GetAllSuggestions(guildID, statusFilter(status))
 	if err != nil || len(suggestions) == 0 {
 		return
 	}
@@ -156,10 +201,10 @@ func (h *Handlers) HandleSuggestionsPrev(s *discordgo.Session, i *discordgo.Inte
 		newIndex = 0
 	}
 
-	h.showAllSuggestionsPage(s, i, suggestions, newIndex)
+	h.showAllSuggestionsPage(s, i, suggestions, newIndex, status)
 }
 
-func (h *Handlers) HandleSuggestionsNext(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h *Handlers) HandleSuggestionsNext(s Responder, i *discordgo.InteractionCreate) {
 	guildID := i.GuildID
 	if guildID == "" {
 		return
@@ -170,7 +215,7 @@ func (h *Handlers) HandleSuggestionsNext(s *discordgo.Session, i *discordgo.Inte
 	})
 
 	customID := i.MessageComponentData().CustomID
-	re := regexp.MustCompile(`suggestions_next_([^_]+)_(\d+)`)
+	re := regexp.MustCompile(`suggestions_next_([^_]+)_(\d+)(?:_([a-z]+))?`)
 	matches := re.FindStringSubmatch(customID)
 
 	if len(matches) < 3 {
@@ -178,8 +223,13 @@ func (h *Handlers) HandleSuggestionsNext(s *discordgo.Session, i *discordgo.Inte
 	}
 
 	currentIndex, _ := strconv.Atoi(matches[2])
+	// Botões de mensagens antigas não trazem o status e sempre listaram tudo
+	status := matches[3]
+	if status == "" {
+		status = suggestionStatusAll
+	}
 
-	suggestions, err := h.db.GetAllSuggestions(guildID)
+	suggestions, err := h.db.GetAllSuggestions(guildID, statusFilter(status))
 	if err != nil || len(suggestions) == 0 {
 		return
 	}
@@ -189,5 +239,5 @@ func (h *Handlers) HandleSuggestionsNext(s *discordgo.Session, i *discordgo.Inte
 		newIndex = len(suggestions) - 1
 	}
 
-	h.showAllSuggestionsPage(s, i, suggestions, newIndex)
+	h.showAllSuggestionsPage(s, i, suggestions, newIndex, status)
 }
\ No newline at end of file
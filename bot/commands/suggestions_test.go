@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"clapper/database"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestListings(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "mystats counts the member's suggestions",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+				env.suggest(movieAliens, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "mystats")
+			},
+			want: []string{"member's Statistics", "Total Suggestions\n2"},
+		},
+		{
+			name: "mystats shows the vetoes left this season",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveVetoSettings(testGuildID, 3, false)
+				env.suggest(movieHeat, testOther)
+				env.suggest(movieAlien, testOther)
+				env.store.SaveVeto(testGuildID, env.ids["Heat"], testMember.User.ID, "member", false, 3, database.MovieFilter{})
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "mystats")
+			},
+			want: []string{"Vetoes This Season", "1 used · 2 of 3 left"},
+		},
+		{
+			name: "mystats shows the remaining suggestion quota",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.store.SaveSuggestionLimits(testGuildID, 3, time.Hour)
+				env.suggest(movieHeat, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "mystats")
+			},
+			want: []string{"Suggestion Quota", "2 of 3 open suggestions left", "Next suggestion <t:"},
+		},
+		{
+			name: "mysuggestions without suggestions",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "mysuggestions")
+			},
+			want: []string{"haven't suggested any movies yet"},
+		},
+		{
+			name: "mysuggestions shows the first page",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "mysuggestions")
+			},
+			want: []string{"Alien (1979)", "Page 1 of 2"},
+			check: func(t *testing.T, env *testEnv) {
+				want := []string{"mysuggestions_prev_3_0", "mysuggestions_next_3_0"}
+				if ids := env.responder.customIDs(); strings.Join(ids, ",") != strings.Join(want, ",") {
+					t.Errorf("custom IDs = %v, want %v", ids, want)
+				}
+			},
+		},
+		{
+			name: "mysuggestions next button",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, "mysuggestions_next_3_0")
+			},
+			want: []string{"Heat (1995)", "Page 2 of 2"},
+		},
+		{
+			name: "mysuggestions previous button",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testMember)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, "mysuggestions_prev_3_1")
+			},
+			want: []string{"Alien (1979)", "Page 1 of 2"},
+		},
+		{
+			name: "suggestions without suggestions",
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestions")
+			},
+			want: []string{"No movies have been suggested yet"},
+		},
+		{
+			name: "suggestions lists every member's suggestions",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "suggestions")
+			},
+			want: []string{"Alien (1979)", "Page 1 of 2"},
+		},
+		{
+			name: "suggestions next button",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("suggestions_next_%s_0", testGuildID))
+			},
+			want: []string{"Heat (1995)", "Page 2 of 2"},
+		},
+		{
+			name: "suggestions previous button",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.suggest(movieAlien, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("suggestions_prev_%s_1", testGuildID))
+			},
+			want: []string{"Alien (1979)", "Page 1 of 2"},
+		},
+		{
+			name: "moviestats shows the server progress",
+			setup: func(env *testEnv) {
+				env.suggest(movieHeat, testMember)
+				env.selectMovie(movieAlien, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testMember, "moviestats")
+			},
+			want: []string{"Test Server Movies Statistics", "Total Suggestions\n2", "Movies Selected\n1"},
+		},
+	})
+}
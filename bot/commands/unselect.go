@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func (h *Handlers) HandleUnselect(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This command can only be used in a server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can unselect movies!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	var movieValue, reviewsMode string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "movie":
+			movieValue = opt.StringValue()
+		case "reviews":
+			reviewsMode = opt.StringValue()
+		}
+	}
+
+	suggestionID, ok := parseSuggestionID(movieValue)
+	if !ok {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Please pick a movie from the list of selected movies shown while typing."),
+		})
+		return
+	}
+
+	movie, err := h.db.GetSelectedMovie(guildID, suggestionID)
+	if err != nil || movie == nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ Could not find that selected movie."),
+		})
+		return
+	}
+
+	// Um filme de volta ao sorteio não deve manter uma sessão agendada
+	h.cancelScreening(s, guildID, movie.ID)
+
+	archiveReviews := reviewsMode == "archive"
+	if err := h.db.UnselectMovie(guildID, movie.ID, archiveReviews); err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: ptrString("❌ An error occurred while unselecting the movie. Please try again."),
+		})
+		return
+	}
+	h.syncSuggestionPosts(s, guildID, movie.ID)
+
+	reviewsInfo := "Its reviews were kept and will show up again if it is selected later."
+	if archiveReviews {
+		reviewsInfo = "Its reviews were moved to the archive."
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: ptrString(fmt.Sprintf("✅ **%s** is back in the pool and can be picked again.\n%s", movie.MovieName, reviewsInfo)),
+	})
+}
+
+func (h *Handlers) HandleResetSelections(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This command can only be used in a server.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can reset selections!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	selectedCount, _ := h.db.GetSelectedMoviesCount(guildID)
+	if selectedCount == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ No movies have been selected this season, there is nothing to reset.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	season, _ := h.db.GetCurrentSeason(guildID)
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("⚠️ This will archive the %d selected movie%s and their reviews as **Season %d** and make every suggestion pickable again. Continue?",
+				selectedCount, pluralize(selectedCount), season),
+			Flags: discordgo.MessageFlagsEphemeral,
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.Button{
+							Label:    "Start New Season",
+							Style:    discordgo.DangerButton,
+							CustomID: fmt.Sprintf("reset_selections_%s", guildID),
+							Emoji: &discordgo.ComponentEmoji{
+								Name: "🔁",
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func (h *Handlers) HandleResetSelectionsConfirm(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" {
+		return
+	}
+
+	if !h.authorize(s, i, permissionHost) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Only administrators and movie night hosts can reset selections!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	re := regexp.MustCompile(`reset_selections_([^_]+)`)
+	matches := re.FindStringSubmatch(i.MessageComponentData().CustomID)
+	if len(matches) < 2 || matches[1] != guildID {
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+
+	// As mensagens dos filmes arquivados voltam a receber votos depois do reset
+	selected, _ := h.db.GetAllSelectedMovies(guildID)
+
+	season, archived, err := h.db.ResetSelections(guildID)
+	if err != nil {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    ptrString("❌ An error occurred while resetting selections. Please try again."),
+			Components: &[]discordgo.MessageComponent{},
+		})
+		return
+	}
+	// Outro host pode ter resetado entre o comando e o botão; sem nada arquivado a temporada continua a mesma
+	if archived == 0 {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    ptrString("❌ No movies have been selected this season, there is nothing to reset. The current season continues."),
+			Components: &[]discordgo.MessageComponent{},
+		})
+		return
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    ptrString(fmt.Sprintf("✅ Archived %d movie%s as **Season %d**. A new season has started and every suggestion can be picked again!", archived, pluralize(archived), season)),
+		Components: &[]discordgo.MessageComponent{},
+	})
+
+	for _, movie := range selected {
+		h.syncSuggestionPosts(s, guildID, movie.ID)
+	}
+}
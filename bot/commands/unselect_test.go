@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSeasons(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "unselect returns the movie to the pool",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.selectMovie(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "unselect", stringOption("movie", env.ref(movieHeat)), stringOption("reviews", "archive"))
+			},
+			want: []string{"**Heat** is back in the pool", "moved to the archive"},
+			check: func(t *testing.T, env *testEnv) {
+				if count, _ := env.store.GetSelectedMoviesCount(testGuildID); count != 0 {
+					t.Errorf("selected count = %d, want 0", count)
+				}
+			},
+		},
+		{
+			name:  "resetselections with nothing selected",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "resetselections")
+			},
+			want: []string{"nothing to reset"},
+		},
+		{
+			name: "resetselections asks for confirmation",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.selectMovie(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return slashCommand(testHost, "resetselections")
+			},
+			want: []string{"archive the 1 selected movie", "**Season 1**"},
+			check: func(t *testing.T, env *testEnv) {
+				if ids := env.responder.customIDs(); len(ids) != 1 || ids[0] != "reset_selections_"+testGuildID {
+					t.Errorf("unexpected components: %v", ids)
+				}
+			},
+		},
+		{
+			name: "reset selections button archives the season",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.selectMovie(movieHeat, testOther)
+				env.selectMovie(movieAlien, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, "reset_selections_"+testGuildID)
+			},
+			want: []string{"Archived 2 movies as **Season 1**"},
+			check: func(t *testing.T, env *testEnv) {
+				if count, _ := env.store.GetSelectedMoviesCount(testGuildID); count != 0 {
+					t.Errorf("selected count = %d, want 0", count)
+				}
+			},
+		},
+		{
+			name: "reset selections button with nothing selected keeps the season",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.selectMovie(movieHeat, testOther)
+				env.store.ResetSelections(testGuildID)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testHost, "reset_selections_"+testGuildID)
+			},
+			want: []string{"nothing to reset", "The current season continues"},
+			check: func(t *testing.T, env *testEnv) {
+				if season, _ := env.store.GetCurrentSeason(testGuildID); season != 2 {
+					t.Errorf("current season = %d, want 2", season)
+				}
+			},
+		},
+		{
+			name: "reset selections button refuses members",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.selectMovie(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, "reset_selections_"+testGuildID)
+			},
+			want: []string{"Only administrators and movie night hosts can reset selections"},
+			check: func(t *testing.T, env *testEnv) {
+				if count, _ := env.store.GetSelectedMoviesCount(testGuildID); count != 1 {
+					t.Errorf("selected count = %d, want 1", count)
+				}
+			},
+		},
+	})
+}
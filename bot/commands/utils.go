@@ -1,5 +1,81 @@
 package commands
 
+import (
+	"clapper/tmdb"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tmdbTimeout é o prazo total de uma chamada ao TMDB feita por um handler, contando as novas tentativas;
+// as respostas já foram adiadas, então o limite é a paciência de quem espera e não a janela do Discord
+const tmdbTimeout = 20 * time.Second
+
 func ptrString(s string) *string {
 	return &s
 }
+
+func ptrFloat(f float64) *float64 {
+	return &f
+}
+
+func tmdbContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), tmdbTimeout)
+}
+
+// tmdbErrorMessage explica ao usuário por que a consulta ao TMDB falhou; notFound é usado quando o filme não existe
+func tmdbErrorMessage(err error, notFound string) string {
+	switch {
+	case errors.Is(err, tmdb.ErrNotFound):
+		return notFound
+	case errors.Is(err, tmdb.ErrRateLimited):
+		return "⏳ TMDB is receiving too many requests right now. Please try again in a minute."
+	case errors.Is(err, tmdb.ErrUnauthorized):
+		return "❌ TMDB rejected the bot's API key. Please ask an administrator to check the bot configuration."
+	case errors.Is(err, context.DeadlineExceeded):
+		return "⌛ TMDB took too long to respond. Please try again."
+	}
+	return "❌ Could not reach TMDB right now. Please try again later."
+}
+
+// parseDuration aceita durações do Go ("90m", "1h30m") e dias inteiros ("2d")
+func parseDuration(input string) (time.Duration, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+
+	if days, ok := strings.CutSuffix(input, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration, use something like 30m, 2h or 1d")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(input)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration, use something like 30m, 2h or 1d")
+	}
+	return duration, nil
+}
+
+// formatDuration escreve a duração em dias, horas e minutos, como "1d 6h" ou "45m"
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	return strings.Join(parts, " ")
+}
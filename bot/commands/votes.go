@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func voteComponents(guildID string, suggestionID, upvotes, downvotes int) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    strconv.Itoa(upvotes),
+					Style:    discordgo.SecondaryButton,
+					CustomID: fmt.Sprintf("vote_up_%s_%d", guildID, suggestionID),
+					Emoji: &discordgo.ComponentEmoji{
+						Name: "👍",
+					},
+				},
+				discordgo.Button{
+					Label:    strconv.Itoa(downvotes),
+					Style:    discordgo.SecondaryButton,
+					CustomID: fmt.Sprintf("vote_down_%s_%d", guildID, suggestionID),
+					Emoji: &discordgo.ComponentEmoji{
+						Name: "👎",
+					},
+				},
+			},
+		},
+	}
+}
+
+func formatVotes(upvotes, downvotes int) string {
+	return fmt.Sprintf("👍 %d · 👎 %d (net %+d)", upvotes, downvotes, upvotes-downvotes)
+}
+
+func (h *Handlers) HandleSuggestionVote(s Responder, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	if guildID == "" || i.Member == nil {
+		return
+	}
+
+	customID := i.MessageComponentData().CustomID
+	re := regexp.MustCompile(`vote_(up|down)_([^_]+)_(\d+)`)
+	matches := re.FindStringSubmatch(customID)
+
+	if len(matches) < 4 || matches[2] != guildID {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ An error occurred. Please try again.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	suggestionID, _ := strconv.Atoi(matches[3])
+	vote := 1
+	if matches[1] == "down" {
+		vote = -1
+	}
+
+	suggestion, err := h.db.GetSuggestion(guildID, suggestionID)
+	if err != nil || suggestion == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ This suggestion no longer exists.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if err := h.db.SaveVote(guildID, suggestionID, i.Member.User.ID, vote); err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ An error occurred while saving your vote. Please try again.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	upvotes, downvotes, _ := h.db.GetVoteTotals(suggestionID)
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     i.Message.Embeds,
+			Components: voteComponents(guildID, suggestionID, upvotes, downvotes),
+		},
+	})
+}
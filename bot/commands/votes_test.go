@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestVotes(t *testing.T) {
+	runHandlerTests(t, []handlerTest{
+		{
+			name: "upvote updates the counters",
+			setup: func(env *testEnv) {
+				env.configure()
+				env.suggest(movieHeat, testOther)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("vote_up_%s_%s", testGuildID, env.ref(movieHeat)))
+			},
+			check: func(t *testing.T, env *testEnv) {
+				resp := env.responder.responses[len(env.responder.responses)-1]
+				if resp.Type != discordgo.InteractionResponseUpdateMessage {
+					t.Fatalf("expected a message update, got %v", resp.Type)
+				}
+				row := resp.Data.Components[0].(discordgo.ActionsRow)
+				if label := row.Components[0].(discordgo.Button).Label; label != "1" {
+					t.Errorf("upvote label = %q, want 1", label)
+				}
+			},
+		},
+		{
+			name: "voting the same way twice removes the vote",
+			setup: func(env *testEnv) {
+				env.configure()
+				id := env.suggest(movieHeat, testOther)
+				env.store.SaveVote(testGuildID, id, testMember.User.ID, -1)
+			},
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("vote_down_%s_%s", testGuildID, env.ref(movieHeat)))
+			},
+			check: func(t *testing.T, env *testEnv) {
+				if up, down, _ := env.store.GetVoteTotals(env.ids["Heat"]); up != 0 || down != 0 {
+					t.Errorf("votes = %d/%d, want 0/0", up, down)
+				}
+			},
+		},
+		{
+			name:  "vote on a removed suggestion",
+			setup: (*testEnv).configure,
+			interaction: func(env *testEnv) *discordgo.InteractionCreate {
+				return buttonPress(testMember, fmt.Sprintf("vote_up_%s_42", testGuildID))
+			},
+			want: []string{"no longer exists"},
+		},
+	})
+}
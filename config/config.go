@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -10,6 +12,20 @@ import (
 type Config struct {
 	DiscordToken string
 	TMDBAPIKey   string
+	// Token de leitura da API v4; quando definido, é usado no lugar de TMDBAPIKey
+	TMDBAccessToken string
+	// Endereços da API e dos pôsteres, para usar um mock local ou um proxy com cache; vazio usa o TMDB
+	TMDBBaseURL      string
+	TMDBImageBaseURL string
+	TMDBCacheTTL     time.Duration
+	// Ajustes do cliente do TMDB; zero (ou -1 em TMDBMaxRetries) mantém os padrões do pacote tmdb
+	TMDBTimeout    time.Duration
+	TMDBMaxRetries int
+	TMDBRateLimit  float64
+	// Idioma padrão das respostas do TMDB (ex.: pt-BR) para servidores sem /setup language; vazio usa en-US
+	TMDBLanguage string
+	// Servidor dono dos dados de bancos criados pela versão single-guild
+	LegacyGuildID string
 }
 
 func Load() *Config {
@@ -17,8 +33,28 @@ func Load() *Config {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	// TTL do cache do TMDB, ex.: "12h"; vazio ou inválido usa o padrão do pacote tmdb
+	cacheTTL, _ := time.ParseDuration(os.Getenv("TMDB_CACHE_TTL"))
+
+	// Tempo de cada tentativa (ex.: "5s"), novas tentativas após 429/5xx e requisições por segundo
+	timeout, _ := time.ParseDuration(os.Getenv("TMDB_TIMEOUT"))
+	maxRetries, err := strconv.Atoi(os.Getenv("TMDB_MAX_RETRIES"))
+	if err != nil || maxRetries < 0 {
+		maxRetries = -1
+	}
+	rateLimit, _ := strconv.ParseFloat(os.Getenv("TMDB_RATE_LIMIT"), 64)
+
 	return &Config{
-		DiscordToken: os.Getenv("DISCORD_TOKEN"),
-		TMDBAPIKey:   os.Getenv("TMDB_API_KEY"),
+		DiscordToken:     os.Getenv("DISCORD_TOKEN"),
+		TMDBAPIKey:       os.Getenv("TMDB_API_KEY"),
+		TMDBAccessToken:  os.Getenv("TMDB_ACCESS_TOKEN"),
+		TMDBBaseURL:      os.Getenv("TMDB_BASE_URL"),
+		TMDBImageBaseURL: os.Getenv("TMDB_IMAGE_BASE_URL"),
+		TMDBCacheTTL:     cacheTTL,
+		TMDBTimeout:      timeout,
+		TMDBMaxRetries:   maxRetries,
+		TMDBRateLimit:    rateLimit,
+		TMDBLanguage:     os.Getenv("TMDB_LANGUAGE"),
+		LegacyGuildID:    os.Getenv("LEGACY_GUILD_ID"),
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// BlindRating é o estado da avaliação às cegas de um filme selecionado. Enquanto RevealedAt for nulo,
+// as notas ficam escondidas; RevealAt e RevealAfter são as condições opcionais de revelação automática.
+type BlindRating struct {
+	GuildID      string
+	SuggestionID int
+	RevealAt     *time.Time
+	RevealAfter  int
+	RevealedAt   *time.Time
+}
+
+func (b *BlindRating) IsHidden() bool {
+	return b.RevealedAt == nil
+}
+
+const blindRatingColumns = `guild_id, suggestion_id, reveal_at, COALESCE(reveal_after, 0), revealed_at`
+
+func scanBlindRating(row rowScanner) (*BlindRating, error) {
+	var blind BlindRating
+	var revealAt, revealedAt sql.NullTime
+	if err := row.Scan(&blind.GuildID, &blind.SuggestionID, &revealAt, &blind.RevealAfter, &revealedAt); err != nil {
+		return nil, err
+	}
+	if revealAt.Valid {
+		blind.RevealAt = &revealAt.Time
+	}
+	if revealedAt.Valid {
+		blind.RevealedAt = &revealedAt.Time
+	}
+	return &blind, nil
+}
+
+// SetBlindRatings esconde as notas do filme selecionado até a revelação; revealAt nulo e revealAfter
+// zero deixam a revelação só para o /revealratings
+func (d *Database) SetBlindRatings(guildID string, suggestionID int, revealAt *time.Time, revealAfter int) error {
+	var at, after interface{}
+	if revealAt != nil {
+		at = *revealAt
+	}
+	if revealAfter > 0 {
+		after = revealAfter
+	}
+
+	_, err := d.db.Exec(`
+		UPDATE selected_movies SET blind = 1, reveal_at = ?, reveal_after = ?, revealed_at = NULL
+		WHERE guild_id = ? AND suggestion_id = ?`, at, after, guildID, suggestionID)
+	return err
+}
+
+// GetBlindRating devolve nil quando o filme não está selecionado ou nunca teve avaliação às cegas
+func (d *Database) GetBlindRating(guildID string, suggestionID int) (*BlindRating, error) {
+	blind, err := scanBlindRating(d.db.QueryRow(
+		"SELECT "+blindRatingColumns+" FROM selected_movies WHERE guild_id = ? AND suggestion_id = ? AND blind = 1",
+		guildID, suggestionID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return blind, err
+}
+
+// RevealRatings marca as notas como reveladas. Devolve false se elas já tinham sido reveladas, o que
+// impede que o timer, a contagem de avaliações e o comando publiquem o resultado duas vezes.
+func (d *Database) RevealRatings(guildID string, suggestionID int) (bool, error) {
+	result, err := d.db.Exec(`
+		UPDATE selected_movies SET revealed_at = ?
+		WHERE guild_id = ? AND suggestion_id = ? AND blind = 1 AND revealed_at IS NULL`,
+		time.Now(), guildID, suggestionID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetPendingReveals lista, de todos os servidores, as avaliações às cegas com revelação agendada
+func (d *Database) GetPendingReveals() ([]BlindRating, error) {
+	rows, err := d.db.Query("SELECT " + blindRatingColumns + ` FROM selected_movies
+		WHERE blind = 1 AND revealed_at IS NULL AND reveal_at IS NOT NULL
+		ORDER BY reveal_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reveals []BlindRating
+	for rows.Next() {
+		blind, err := scanBlindRating(rows)
+		if err != nil {
+			return nil, err
+		}
+		reveals = append(reveals, *blind)
+	}
+	return reveals, rows.Err()
+}
@@ -2,14 +2,19 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
+	"math"
+	"math/rand"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type Database struct {
-	db *sql.DB
+	db            *sql.DB
+	legacyGuildID string
 }
 
 type Suggestion struct {
@@ -24,6 +29,47 @@ type Suggestion struct {
 	Genres      string
 	ReleaseYear string
 	IsSelected  bool
+	Upvotes     int
+	Downvotes   int
+	GenreIDs    []int
+	// Status é o estado de moderação (SuggestionPending, SuggestionApproved ou SuggestionRejected)
+	Status          string
+	RejectionReason string
+	// ChannelID e MessageID apontam para o cartão postado no canal de sugestões; vazios antes da aprovação
+	ChannelID string
+	MessageID string
+	// PostHash identifica o conteúdo com que a mensagem foi escrita pela última vez; só GetPostedSuggestions o preenche
+	PostHash string
+	// SelectedAt só é preenchido por GetSuggestion, quando o filme está selecionado na temporada atual
+	SelectedAt *time.Time
+	MovieMetadata
+}
+
+// Tipos de título das sugestões, guardados em suggestions.media_type com os mesmos nomes usados pelo TMDB
+const (
+	MediaMovie = "movie"
+	MediaTV    = "tv"
+)
+
+// MovieMetadata são os detalhes do TMDB gravados junto com a sugestão, para que as telas não precisem consultar a API
+type MovieMetadata struct {
+	// MediaType diz se a sugestão é um filme (MediaMovie) ou uma série (MediaTV); o tmdb_id só é único dentro do tipo
+	MediaType        string
+	PosterPath       string
+	BackdropPath     string
+	Overview         string
+	Runtime          int
+	OriginalTitle    string
+	OriginalLanguage string
+	IMDbID           string
+	// Seasons e Episodes substituem Runtime nas séries
+	Seasons  int
+	Episodes int
+}
+
+// IsTV indica se a sugestão é uma série
+func (m *MovieMetadata) IsTV() bool {
+	return m.MediaType == MediaTV
 }
 
 type MovieResult struct {
@@ -35,6 +81,28 @@ type MovieResult struct {
 	Genres      string
 	ReleaseYear string
 	TMDBID      int
+	MovieMetadata
+}
+
+// movieResultColumns é a lista de colunas lida por scanMovieResult; as consultas usam o alias "s" para suggestions
+const movieResultColumns = `s.id, s.guild_id, s.movie_name, s.username, s.rating, s.genres, s.release_year, s.tmdb_id,
+	COALESCE(s.poster_path, ''), COALESCE(s.backdrop_path, ''), COALESCE(s.overview, ''), COALESCE(s.runtime, 0),
+	COALESCE(s.original_title, ''), COALESCE(s.original_language, ''), COALESCE(s.imdb_id, ''),
+	COALESCE(s.media_type, '`+MediaMovie+`'), COALESCE(s.seasons, 0), COALESCE(s.episodes, 0)`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMovieResult(row rowScanner, extra ...interface{}) (*MovieResult, error) {
+	var m MovieResult
+	dest := []interface{}{&m.ID, &m.GuildID, &m.MovieName, &m.Username, &m.Rating, &m.Genres, &m.ReleaseYear, &m.TMDBID,
+		&m.PosterPath, &m.BackdropPath, &m.Overview, &m.Runtime, &m.OriginalTitle, &m.OriginalLanguage, &m.IMDbID,
+		&m.MediaType, &m.Seasons, &m.Episodes}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &m, nil
 }
 
 type MovieReview struct {
@@ -45,7 +113,9 @@ type MovieReview struct {
 	Username     string
 	Rating       float64
 	ReviewText   string
-	ReviewedAt   time.Time
+	// Spoiler indica que o autor pediu para esconder o texto atrás de spoiler tags
+	Spoiler    bool
+	ReviewedAt time.Time
 }
 
 type SelectedMovieWithReviews struct {
@@ -53,141 +123,168 @@ type SelectedMovieWithReviews struct {
 	Reviews      []MovieReview
 	AverageScore float64
 	ReviewCount  int
+	SelectedAt   time.Time
+	EventID      string
+	ScheduledFor *time.Time
+}
+
+// Screening é a sessão agendada de um filme selecionado
+type Screening struct {
+	EventID      string
+	ScheduledFor *time.Time
+	// ThreadID é o tópico de discussão aberto na confirmação do filme
+	ThreadID string
 }
 
+// Estratégias de sorteio do modo aleatório do /pickmovie, guardadas em guild_configs.pick_strategy
+const (
+	// PickStrategyUniform dá a mesma chance a todas as sugestões
+	PickStrategyUniform = "uniform"
+	// PickStrategyRoundRobin sorteia primeiro quem sugeriu, priorizando quem teve um filme escolhido há mais tempo
+	PickStrategyRoundRobin = "per_user_round_robin"
+	// PickStrategyWeightedByWait aumenta a chance das sugestões mais antigas
+	PickStrategyWeightedByWait = "weighted_by_wait"
+)
+
 type GuildConfig struct {
 	ID                  int
 	GuildID             string
 	SuggestionChannelID string
 	ConfiguredAt        time.Time
+	EventChannelID      string
+	Timezone            string
+	HostRoleID          string
+	SuggesterRoleID     string
+	PickStrategy        string
+	// VetoesPerSeason é quantos vetos cada membro pode gastar por temporada; zero desativa o botão de veto
+	VetoesPerSeason int
+	// BanVetoed tira os filmes vetados do sorteio até o fim da temporada
+	BanVetoed bool
+	// MaxOpenSuggestions limita as sugestões ainda não selecionadas de cada membro; zero desativa o limite
+	MaxOpenSuggestions int
+	// SuggestionCooldown é o intervalo mínimo entre duas sugestões do mesmo membro; zero desativa
+	SuggestionCooldown time.Duration
+	// RequireApproval faz as novas sugestões aguardarem um moderador em ModChannelID antes de ficarem públicas
+	RequireApproval bool
+	ModChannelID    string
+	// DiscussionForumID é o fórum onde nascem os tópicos dos filmes confirmados; vazio usa a própria mensagem de confirmação
+	DiscussionForumID string
+	// Language é o idioma do TMDB (ex.: pt-BR) usado nos títulos, sinopses e gêneros; vazio usa o padrão do bot
+	Language string
 }
 
-func New(dbPath string) (*Database, error) {
+// New abre o banco e aplica as migrações pendentes. legacyGuildID indica a qual servidor pertencem
+// os dados de bancos da versão single-guild; pode ficar vazio se houver apenas um servidor configurado.
+func New(dbPath, legacyGuildID string) (*Database, error) {
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return nil, err
 	}
 
-	d := &Database{db: db}
-	if err := d.initDatabase(); err != nil {
+	d := &Database{db: db, legacyGuildID: legacyGuildID}
+	if err := d.migrate(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return d, nil
 }
 
-func (d *Database) initDatabase() error {
-	// Verificar se precisa migrar
-	var hasGuildID bool
-	err := d.db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('suggestions') WHERE name='guild_id'").Scan(&hasGuildID)
-
-	if err == nil && !hasGuildID {
-		log.Println("Migrando banco de dados para suporte multi-guild...")
-
-		// Backup das tabelas antigas
-		_, _ = d.db.Exec("ALTER TABLE suggestions RENAME TO suggestions_old")
-		_, _ = d.db.Exec("ALTER TABLE selected_movies RENAME TO selected_movies_old")
-		_, _ = d.db.Exec("ALTER TABLE movie_reviews RENAME TO movie_reviews_old")
-	}
-
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS suggestions (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			guild_id TEXT NOT NULL,
-			movie_name TEXT NOT NULL,
-			user_id TEXT NOT NULL,
-			username TEXT NOT NULL,
-			suggested_at TIMESTAMP NOT NULL,
-			tmdb_id INTEGER NOT NULL,
-			rating REAL,
-			genres TEXT,
-			release_year TEXT,
-			UNIQUE(guild_id, tmdb_id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS selected_movies (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			guild_id TEXT NOT NULL,
-			suggestion_id INTEGER NOT NULL,
-			selected_at TIMESTAMP NOT NULL,
-			FOREIGN KEY (suggestion_id) REFERENCES suggestions (id) ON DELETE CASCADE,
-			UNIQUE(guild_id, suggestion_id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS movie_reviews (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			suggestion_id INTEGER NOT NULL,
-			guild_id TEXT NOT NULL,
-			user_id TEXT NOT NULL,
-			username TEXT NOT NULL,
-			rating REAL NOT NULL CHECK(rating >= 0 AND rating <= 10),
-			review_text TEXT,
-			reviewed_at TIMESTAMP NOT NULL,
-			FOREIGN KEY (suggestion_id) REFERENCES suggestions (id) ON DELETE CASCADE,
-			UNIQUE(guild_id, suggestion_id, user_id)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_suggestions_guild_id ON suggestions(guild_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_suggestions_user_id ON suggestions(user_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_suggestions_tmdb_id ON suggestions(tmdb_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_suggestions_guild_tmdb ON suggestions(guild_id, tmdb_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_selected_movies_guild_id ON selected_movies(guild_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_selected_movies_suggestion_id ON selected_movies(suggestion_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_movie_reviews_guild_id ON movie_reviews(guild_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_movie_reviews_suggestion_id ON movie_reviews(suggestion_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_movie_reviews_user_id ON movie_reviews(user_id)`,
-	}
-
-	for _, query := range queries {
-		if _, err := d.db.Exec(query); err != nil {
-			log.Printf("Erro ao executar query: %s\nErro: %v", query, err)
-			return err
-		}
-	}
-
-	// Inicializar tabela de configurações
-	if err := d.initGuildConfig(); err != nil {
-		return err
+// GetCacheEntry e SaveCacheEntry implementam tmdb.CacheStore
+func (d *Database) GetCacheEntry(key string) ([]byte, time.Time, error) {
+	var payload string
+	var fetchedAt time.Time
+	err := d.db.QueryRow("SELECT payload, fetched_at FROM tmdb_cache WHERE cache_key = ?", key).Scan(&payload, &fetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, nil
 	}
-
-	log.Println("Database initialized successfully with multi-guild support")
-	return nil
-}
-
-func (d *Database) initGuildConfig() error {
-	query := `CREATE TABLE IF NOT EXISTS guild_configs (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		guild_id TEXT NOT NULL UNIQUE,
-		suggestion_channel_id TEXT NOT NULL,
-		configured_at TIMESTAMP NOT NULL
-	)`
-
-	_, err := d.db.Exec(query)
 	if err != nil {
-		return err
+		return nil, time.Time{}, err
 	}
+	return []byte(payload), fetchedAt, nil
+}
 
-	_, err = d.db.Exec(`CREATE INDEX IF NOT EXISTS idx_guild_configs_guild_id ON guild_configs(guild_id)`)
+func (d *Database) SaveCacheEntry(key string, payload []byte) error {
+	_, err := d.db.Exec(`
+		INSERT INTO tmdb_cache (cache_key, payload, fetched_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(cache_key)
+		DO UPDATE SET payload = excluded.payload, fetched_at = excluded.fetched_at`,
+		key, string(payload), time.Now())
 	return err
 }
 
-func (d *Database) MovieAlreadySuggested(guildID string, tmdbID int) (bool, error) {
+// MovieAlreadySuggested confere se o título já foi sugerido no servidor; mediaType é MediaMovie ou MediaTV.
+// Sugestões rejeitadas não contam, para que o título possa ser sugerido de novo.
+func (d *Database) MovieAlreadySuggested(guildID, mediaType string, tmdbID int) (bool, error) {
 	var count int
-	err := d.db.QueryRow("SELECT COUNT(*) FROM suggestions WHERE guild_id = ? AND tmdb_id = ?", guildID, tmdbID).Scan(&count)
+	err := d.db.QueryRow("SELECT COUNT(*) FROM suggestions WHERE guild_id = ? AND media_type = ? AND tmdb_id = ? AND status != ?",
+		guildID, mediaType, tmdbID, SuggestionRejected).Scan(&count)
 	return count > 0, err
 }
 
-func (d *Database) GetMovieSuggester(guildID string, tmdbID int) (string, error) {
+func (d *Database) GetMovieSuggester(guildID, mediaType string, tmdbID int) (string, error) {
 	var username string
-	err := d.db.QueryRow("SELECT username FROM suggestions WHERE guild_id = ? AND tmdb_id = ?", guildID, tmdbID).Scan(&username)
+	err := d.db.QueryRow("SELECT username FROM suggestions WHERE guild_id = ? AND media_type = ? AND tmdb_id = ? AND status != ?",
+		guildID, mediaType, tmdbID, SuggestionRejected).Scan(&username)
 	if err == sql.ErrNoRows {
 		return "", nil
 	}
 	return username, err
 }
 
+// SuggestionLimitError indica que o membro já atingiu o limite de sugestões abertas ou ainda está no intervalo
+// entre sugestões; traz a atividade usada na decisão para a mensagem ao membro
+type SuggestionLimitError struct {
+	Open            int
+	LastSuggestedAt time.Time
+}
+
+func (e *SuggestionLimitError) Error() string {
+	return fmt.Sprintf("suggestion limit reached: %d open, last suggested at %s", e.Open, e.LastSuggestedAt.Format(time.RFC3339))
+}
+
 func (d *Database) SaveSuggestion(s *Suggestion) (int64, error) {
-	result, err := d.db.Exec(`
-		INSERT INTO suggestions (guild_id, movie_name, user_id, username, suggested_at, tmdb_id, rating, genres, release_year)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	return d.SaveSuggestionWithinLimits(s, 0, 0)
+}
+
+// SaveSuggestionWithinLimits confere os limites do membro e grava a sugestão na mesma transação, para que duas
+// sugestões simultâneas não passem juntas pela checagem; zero desativa cada limite. Devolve *SuggestionLimitError
+// quando a sugestão não é permitida.
+func (d *Database) SaveSuggestionWithinLimits(s *Suggestion, maxOpen int, cooldown time.Duration) (int64, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if maxOpen > 0 || cooldown > 0 {
+		open, lastSuggestedAt, err := userSuggestionActivity(tx, s.GuildID, s.UserID)
+		if err != nil {
+			return 0, err
+		}
+		if (maxOpen > 0 && open >= maxOpen) || (cooldown > 0 && !lastSuggestedAt.IsZero() && time.Since(lastSuggestedAt) < cooldown) {
+			return 0, &SuggestionLimitError{Open: open, LastSuggestedAt: lastSuggestedAt}
+		}
+	}
+
+	status := s.Status
+	if status == "" {
+		status = SuggestionApproved
+	}
+	mediaType := s.MediaType
+	if mediaType == "" {
+		mediaType = MediaMovie
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO suggestions (guild_id, movie_name, user_id, username, suggested_at, tmdb_id, rating, genres, release_year,
+		                         runtime, poster_path, backdrop_path, overview, original_title, original_language, imdb_id, metadata_updated_at,
+		                         status, media_type, seasons, episodes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		s.GuildID, s.MovieName, s.UserID, s.Username, time.Now(), s.TMDBID, s.Rating, s.Genres, s.ReleaseYear,
+		s.Runtime, s.PosterPath, s.BackdropPath, s.Overview, s.OriginalTitle, s.OriginalLanguage, s.IMDbID, time.Now(),
+		status, mediaType, s.Seasons, s.Episodes,
 	)
 	if err != nil {
 		log.Printf("Erro ao salvar sugestão: %v", err)
@@ -197,10 +294,82 @@ func (d *Database) SaveSuggestion(s *Suggestion) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
+
+	for _, genreID := range s.GenreIDs {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO suggestion_genres (suggestion_id, genre_id) VALUES (?, ?)", id, genreID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
 	log.Printf("Sugestão salva com ID: %d para guild: %s", id, s.GuildID)
 	return id, nil
 }
 
+// GetSuggestionsForMetadataRefresh lista as sugestões do servidor cujos detalhes do TMDB devem ser atualizados.
+// Sem all, apenas as que nunca tiveram os metadados completos gravados.
+func (d *Database) GetSuggestionsForMetadataRefresh(guildID string, all bool) ([]Suggestion, error) {
+	query := `
+		SELECT id, guild_id, movie_name, tmdb_id, COALESCE(media_type, '`+MediaMovie+`')
+		FROM suggestions
+		WHERE guild_id = ?`
+	if !all {
+		query += " AND metadata_updated_at IS NULL"
+	}
+	query += " ORDER BY id"
+
+	rows, err := d.db.Query(query, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []Suggestion
+	for rows.Next() {
+		var s Suggestion
+		if err := rows.Scan(&s.ID, &s.GuildID, &s.MovieName, &s.TMDBID, &s.MediaType); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
+
+	return suggestions, rows.Err()
+}
+
+// UpdateSuggestionMetadata regrava os dados vindos do TMDB de uma sugestão existente, incluindo o título e os gêneros
+func (d *Database) UpdateSuggestionMetadata(s *Suggestion) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE suggestions
+		SET movie_name = ?, rating = ?, genres = ?, release_year = ?, runtime = ?, poster_path = ?, backdrop_path = ?, overview = ?,
+		    original_title = ?, original_language = ?, imdb_id = ?, seasons = ?, episodes = ?, metadata_updated_at = ?
+		WHERE id = ?`,
+		s.MovieName, s.Rating, s.Genres, s.ReleaseYear, s.Runtime, s.PosterPath, s.BackdropPath, s.Overview,
+		s.OriginalTitle, s.OriginalLanguage, s.IMDbID, s.Seasons, s.Episodes, time.Now(), s.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM suggestion_genres WHERE suggestion_id = ?", s.ID); err != nil {
+		return err
+	}
+	for _, genreID := range s.GenreIDs {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO suggestion_genres (suggestion_id, genre_id) VALUES (?, ?)", s.ID, genreID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 func (d *Database) GetUserStats(guildID, userID string) (count int, avgRating float64, err error) {
 	err = d.db.QueryRow(`
 		SELECT COUNT(*), COALESCE(AVG(rating), 0)
@@ -209,13 +378,62 @@ func (d *Database) GetUserStats(guildID, userID string) (count int, avgRating fl
 	return
 }
 
+// GetUserSuggestionActivity devolve quantas sugestões do membro ainda não foram selecionadas nem rejeitadas e quando
+// ele sugeriu pela última vez (zero se nunca sugeriu). Sugestões removidas continuam contando para o intervalo.
+func (d *Database) GetUserSuggestionActivity(guildID, userID string) (open int, lastSuggestedAt time.Time, err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer tx.Rollback()
+	return userSuggestionActivity(tx, guildID, userID)
+}
+
+func userSuggestionActivity(tx *sql.Tx, guildID, userID string) (open int, lastSuggestedAt time.Time, err error) {
+	err = tx.QueryRow("SELECT last_suggested_at FROM suggestion_cooldowns WHERE guild_id = ? AND user_id = ?",
+		guildID, userID).Scan(&lastSuggestedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, time.Time{}, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT s.suggested_at, sm.id IS NULL AND s.status != '`+SuggestionRejected+`'
+		FROM suggestions s
+		LEFT JOIN selected_movies sm ON s.id = sm.suggestion_id AND sm.guild_id = s.guild_id
+		WHERE s.user_id = ? AND s.guild_id = ?`, userID, guildID)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var suggestedAt time.Time
+		var isOpen bool
+		if err := rows.Scan(&suggestedAt, &isOpen); err != nil {
+			return 0, time.Time{}, err
+		}
+		if isOpen {
+			open++
+		}
+		if suggestedAt.After(lastSuggestedAt) {
+			lastSuggestedAt = suggestedAt
+		}
+	}
+	return open, lastSuggestedAt, rows.Err()
+}
+
 func (d *Database) GetUserSuggestions(guildID, userID string) ([]Suggestion, error) {
 	log.Printf("Buscando sugestões para guild_id: %s, user_id: %s", guildID, userID)
 
 	rows, err := d.db.Query(`
 		SELECT s.id, s.guild_id, s.movie_name, s.user_id, s.username, s.suggested_at, 
 		       s.tmdb_id, s.rating, s.genres, s.release_year,
-		       CASE WHEN sm.id IS NOT NULL THEN 1 ELSE 0 END as is_selected
+		       CASE WHEN sm.id IS NOT NULL THEN 1 ELSE 0 END as is_selected,
+		       (SELECT COUNT(*) FROM suggestion_votes v WHERE v.suggestion_id = s.id AND v.vote = 1) as upvotes,
+		       (SELECT COUNT(*) FROM suggestion_votes v WHERE v.suggestion_id = s.id AND v.vote = -1) as downvotes,
+		       COALESCE(s.poster_path, ''), COALESCE(s.overview, ''), COALESCE(s.runtime, 0),
+		       s.status, COALESCE(s.rejection_reason, ''),
+		       COALESCE(s.media_type, '`+MediaMovie+`'), COALESCE(s.seasons, 0), COALESCE(s.episodes, 0)
 		FROM suggestions s
 		LEFT JOIN selected_movies sm ON s.id = sm.suggestion_id AND s.guild_id = sm.guild_id
 		WHERE s.guild_id = ? AND s.user_id = ?
@@ -232,7 +450,9 @@ func (d *Database) GetUserSuggestions(guildID, userID string) ([]Suggestion, err
 		var s Suggestion
 		var isSelectedInt int
 		err := rows.Scan(&s.ID, &s.GuildID, &s.MovieName, &s.UserID, &s.Username, &s.SuggestedAt,
-			&s.TMDBID, &s.Rating, &s.Genres, &s.ReleaseYear, &isSelectedInt)
+			&s.TMDBID, &s.Rating, &s.Genres, &s.ReleaseYear, &isSelectedInt, &s.Upvotes, &s.Downvotes,
+			&s.PosterPath, &s.Overview, &s.Runtime, &s.Status, &s.RejectionReason,
+			&s.MediaType, &s.Seasons, &s.Episodes)
 		if err != nil {
 			log.Printf("Erro ao escanear linha: %v", err)
 			return nil, err
@@ -245,17 +465,23 @@ func (d *Database) GetUserSuggestions(guildID, userID string) ([]Suggestion, err
 	return suggestions, nil
 }
 
-func (d *Database) GetAllSuggestions(guildID string) ([]Suggestion, error) {
+// GetAllSuggestions lista as sugestões do servidor com o status informado; status vazio lista todas
+func (d *Database) GetAllSuggestions(guildID, status string) ([]Suggestion, error) {
 	log.Printf("Buscando todas as sugestões para guild_id: %s", guildID)
 
 	rows, err := d.db.Query(`
 		SELECT s.id, s.guild_id, s.movie_name, s.user_id, s.username, s.suggested_at, 
 		       s.tmdb_id, s.rating, s.genres, s.release_year,
-		       CASE WHEN sm.id IS NOT NULL THEN 1 ELSE 0 END as is_selected
+		       CASE WHEN sm.id IS NOT NULL THEN 1 ELSE 0 END as is_selected,
+		       (SELECT COUNT(*) FROM suggestion_votes v WHERE v.suggestion_id = s.id AND v.vote = 1) as upvotes,
+		       (SELECT COUNT(*) FROM suggestion_votes v WHERE v.suggestion_id = s.id AND v.vote = -1) as downvotes,
+		       COALESCE(s.poster_path, ''), COALESCE(s.overview, ''), COALESCE(s.runtime, 0),
+		       s.status, COALESCE(s.rejection_reason, ''),
+		       COALESCE(s.media_type, '`+MediaMovie+`'), COALESCE(s.seasons, 0), COALESCE(s.episodes, 0)
 		FROM suggestions s
 		LEFT JOIN selected_movies sm ON s.id = sm.suggestion_id AND s.guild_id = sm.guild_id
-		WHERE s.guild_id = ?
-		ORDER BY s.suggested_at DESC`, guildID)
+		WHERE s.guild_id = ? AND (? = '' OR s.status = ?)
+		ORDER BY s.suggested_at DESC`, guildID, status, status)
 
 	if err != nil {
 		log.Printf("Erro na query GetAllSuggestions: %v", err)
@@ -268,7 +494,9 @@ func (d *Database) GetAllSuggestions(guildID string) ([]Suggestion, error) {
 		var s Suggestion
 		var isSelectedInt int
 		err := rows.Scan(&s.ID, &s.GuildID, &s.MovieName, &s.UserID, &s.Username, &s.SuggestedAt,
-			&s.TMDBID, &s.Rating, &s.Genres, &s.ReleaseYear, &isSelectedInt)
+			&s.TMDBID, &s.Rating, &s.Genres, &s.ReleaseYear, &isSelectedInt, &s.Upvotes, &s.Downvotes,
+			&s.PosterPath, &s.Overview, &s.Runtime, &s.Status, &s.RejectionReason,
+			&s.MediaType, &s.Seasons, &s.Episodes)
 		if err != nil {
 			log.Printf("Erro ao escanear linha: %v", err)
 			return nil, err
@@ -281,31 +509,208 @@ func (d *Database) GetAllSuggestions(guildID string) ([]Suggestion, error) {
 	return suggestions, nil
 }
 
-func (d *Database) GetRandomMovie(guildID string) (*MovieResult, error) {
-	var m MovieResult
-	err := d.db.QueryRow(`
-		SELECT s.id, s.guild_id, s.movie_name, s.username, s.rating, s.genres, s.release_year, s.tmdb_id
+// pickableSQL deixa no sorteio apenas as sugestões aprovadas, tirando as vetadas com banimento na temporada em andamento
+const pickableSQL = `
+		AND s.status = '` + SuggestionApproved + `'
+		AND NOT EXISTS (
+			SELECT 1 FROM vetoes v
+			WHERE v.suggestion_id = s.id AND v.banned = 1
+			  AND v.season = (SELECT COALESCE(MAX(season), 0) + 1 FROM selected_movies_archive WHERE guild_id = s.guild_id)
+		)`
+
+func (d *Database) GetRandomMovie(guildID string, filter MovieFilter) (*MovieResult, error) {
+	movies, err := d.GetRandomMovies(guildID, filter, 1)
+	if err != nil || len(movies) == 0 {
+		return nil, err
+	}
+	return &movies[0], nil
+}
+
+// GetRandomMovies sorteia até limit filmes distintos do mesmo conjunto usado pelo /pickmovie
+func (d *Database) GetRandomMovies(guildID string, filter MovieFilter, limit int) ([]MovieResult, error) {
+	filterSQL, filterArgs := filter.whereClause()
+	args := append([]interface{}{guildID}, filterArgs...)
+
+	rows, err := d.db.Query(`
+		SELECT `+movieResultColumns+`
 		FROM suggestions s
 		LEFT JOIN selected_movies sm ON s.id = sm.suggestion_id AND s.guild_id = sm.guild_id
-		WHERE s.guild_id = ? AND sm.id IS NULL
+		WHERE s.guild_id = ? AND sm.id IS NULL`+pickableSQL+filterSQL+`
 		ORDER BY RANDOM()
-		LIMIT 1`, guildID).Scan(&m.ID, &m.GuildID, &m.MovieName, &m.Username, &m.Rating, &m.Genres, &m.ReleaseYear, &m.TMDBID)
+		LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movies []MovieResult
+	for rows.Next() {
+		m, err := scanMovieResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		movies = append(movies, *m)
+	}
+	return movies, rows.Err()
+}
+
+// GetVoteWeightedRandomMovie sorteia um filme não selecionado com chance proporcional aos votos líquidos.
+// Cada voto positivo líquido vale um "bilhete" extra; filmes rejeitados mantêm uma chance pequena.
+func (d *Database) GetVoteWeightedRandomMovie(guildID string, filter MovieFilter) (*MovieResult, error) {
+	filterSQL, filterArgs := filter.whereClause()
+	args := append([]interface{}{guildID}, filterArgs...)
+
+	rows, err := d.db.Query(`
+		SELECT s.id, COALESCE(SUM(v.vote), 0) as net_votes
+		FROM suggestions s
+		LEFT JOIN selected_movies sm ON s.id = sm.suggestion_id AND s.guild_id = sm.guild_id
+		LEFT JOIN suggestion_votes v ON v.suggestion_id = s.id
+		WHERE s.guild_id = ? AND sm.id IS NULL`+pickableSQL+filterSQL+`
+		GROUP BY s.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	var weights []float64
+	var total float64
+	for rows.Next() {
+		var id, net int
+		if err := rows.Scan(&id, &net); err != nil {
+			return nil, err
+		}
+		weight := math.Max(1+float64(net), 0.1)
+		ids = append(ids, id)
+		weights = append(weights, weight)
+		total += weight
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	target := rand.Float64() * total
+	chosen := ids[len(ids)-1]
+	for idx, weight := range weights {
+		target -= weight
+		if target < 0 {
+			chosen = ids[idx]
+			break
+		}
+	}
+
+	return d.GetMovieByID(chosen)
+}
 
+// GetRoundRobinRandomMovie sorteia primeiro quem sugeriu e depois um dos seus filmes. Têm prioridade as pessoas
+// que nunca tiveram um filme escolhido e, depois delas, as que tiveram há mais tempo (incluindo temporadas arquivadas).
+func (d *Database) GetRoundRobinRandomMovie(guildID string, filter MovieFilter) (*MovieResult, error) {
+	filterSQL, filterArgs := filter.whereClause()
+	args := append([]interface{}{guildID, guildID, guildID}, filterArgs...)
+
+	var userID string
+	err := d.db.QueryRow(`
+		SELECT s.user_id
+		FROM suggestions s
+		LEFT JOIN selected_movies sm ON s.id = sm.suggestion_id AND s.guild_id = sm.guild_id
+		LEFT JOIN (
+			SELECT ps.user_id, MAX(p.selected_at) AS last_picked
+			FROM (
+				SELECT suggestion_id, selected_at FROM selected_movies WHERE guild_id = ?
+				UNION ALL
+				SELECT suggestion_id, selected_at FROM selected_movies_archive WHERE guild_id = ?
+			) p
+			JOIN suggestions ps ON ps.id = p.suggestion_id
+			GROUP BY ps.user_id
+		) lp ON lp.user_id = s.user_id
+		WHERE s.guild_id = ? AND sm.id IS NULL`+pickableSQL+filterSQL+`
+		GROUP BY s.user_id
+		ORDER BY MAX(lp.last_picked) IS NOT NULL, MAX(lp.last_picked), RANDOM()
+		LIMIT 1`, args...).Scan(&userID)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	return &m, nil
+
+	args = append([]interface{}{guildID}, filterArgs...)
+	m, err := scanMovieResult(d.db.QueryRow(`
+		SELECT `+movieResultColumns+`
+		FROM suggestions s
+		LEFT JOIN selected_movies sm ON s.id = sm.suggestion_id AND s.guild_id = sm.guild_id
+		WHERE s.guild_id = ? AND sm.id IS NULL`+pickableSQL+filterSQL+` AND s.user_id = ?
+		ORDER BY RANDOM()
+		LIMIT 1`, append(args, userID)...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GetWaitWeightedRandomMovie sorteia um filme não selecionado com chance proporcional ao tempo de espera:
+// cada dia desde a sugestão vale um "bilhete" extra, então sugestões antigas não ficam esquecidas.
+func (d *Database) GetWaitWeightedRandomMovie(guildID string, filter MovieFilter) (*MovieResult, error) {
+	filterSQL, filterArgs := filter.whereClause()
+	args := append([]interface{}{guildID}, filterArgs...)
+
+	rows, err := d.db.Query(`
+		SELECT s.id, s.suggested_at
+		FROM suggestions s
+		LEFT JOIN selected_movies sm ON s.id = sm.suggestion_id AND s.guild_id = sm.guild_id
+		WHERE s.guild_id = ? AND sm.id IS NULL`+pickableSQL+filterSQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	var weights []float64
+	var total float64
+	for rows.Next() {
+		var id int
+		var suggestedAt time.Time
+		if err := rows.Scan(&id, &suggestedAt); err != nil {
+			return nil, err
+		}
+		weight := 1 + math.Max(time.Since(suggestedAt).Hours()/24, 0)
+		ids = append(ids, id)
+		weights = append(weights, weight)
+		total += weight
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	target := rand.Float64() * total
+	chosen := ids[len(ids)-1]
+	for idx, weight := range weights {
+		target -= weight
+		if target < 0 {
+			chosen = ids[idx]
+			break
+		}
+	}
+
+	return d.GetMovieByID(chosen)
 }
 
 func (d *Database) GetMovieByID(suggestionID int) (*MovieResult, error) {
-	var m MovieResult
-	err := d.db.QueryRow(`
-		SELECT id, guild_id, movie_name, username, rating, genres, release_year, tmdb_id
-		FROM suggestions
-		WHERE id = ?`, suggestionID).Scan(&m.ID, &m.GuildID, &m.MovieName, &m.Username, &m.Rating, &m.Genres, &m.ReleaseYear, &m.TMDBID)
+	m, err := scanMovieResult(d.db.QueryRow(`
+		SELECT `+movieResultColumns+`
+		FROM suggestions s
+		WHERE s.id = ?`, suggestionID))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -313,7 +718,7 @@ func (d *Database) GetMovieByID(suggestionID int) (*MovieResult, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &m, nil
+	return m, nil
 }
 
 func (d *Database) MarkMovieSelected(guildID string, suggestionID int) error {
@@ -323,9 +728,43 @@ func (d *Database) MarkMovieSelected(guildID string, suggestionID int) error {
 	return err
 }
 
+func (d *Database) SetScreening(guildID string, suggestionID int, eventID string, scheduledFor time.Time) error {
+	_, err := d.db.Exec(`
+		UPDATE selected_movies SET event_id = ?, scheduled_for = ?
+		WHERE guild_id = ? AND suggestion_id = ?`, eventID, scheduledFor, guildID, suggestionID)
+	return err
+}
+
+func (d *Database) ClearScreening(guildID string, suggestionID int) error {
+	_, err := d.db.Exec(`
+		UPDATE selected_movies SET event_id = NULL, scheduled_for = NULL
+		WHERE guild_id = ? AND suggestion_id = ?`, guildID, suggestionID)
+	return err
+}
+
+func (d *Database) GetScreening(guildID string, suggestionID int) (*Screening, error) {
+	var screening Screening
+	var scheduledFor sql.NullTime
+	err := d.db.QueryRow(`
+		SELECT COALESCE(event_id, ''), scheduled_for, COALESCE(thread_id, '')
+		FROM selected_movies
+		WHERE guild_id = ? AND suggestion_id = ?`, guildID, suggestionID).Scan(&screening.EventID, &scheduledFor, &screening.ThreadID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if scheduledFor.Valid {
+		screening.ScheduledFor = &scheduledFor.Time
+	}
+	return &screening, nil
+}
+
 func (d *Database) GetAllSuggestionsCount(guildID string) (int, error) {
 	var count int
-	err := d.db.QueryRow("SELECT COUNT(*) FROM suggestions WHERE guild_id = ?", guildID).Scan(&count)
+	err := d.db.QueryRow("SELECT COUNT(*) FROM suggestions WHERE guild_id = ? AND status = ?", guildID, SuggestionApproved).Scan(&count)
 	return count, err
 }
 
@@ -335,30 +774,76 @@ func (d *Database) GetSelectedMoviesCount(guildID string) (int, error) {
 	return count, err
 }
 
-func (d *Database) SearchUserSuggestions(guildID, movieName, userID string) (*Suggestion, error) {
-	var s Suggestion
-	err := d.db.QueryRow(`
-		SELECT id, guild_id, movie_name, tmdb_id, user_id, username
+// likePrefix escapa os curingas do LIKE para que o texto digitado seja tratado literalmente
+func likePrefix(prefix string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return replacer.Replace(prefix) + "%"
+}
+
+func (d *Database) SearchUserSuggestionsByPrefix(guildID, userID, prefix string, limit int) ([]Suggestion, error) {
+	rows, err := d.db.Query(`
+		SELECT id, guild_id, movie_name, tmdb_id, user_id, username, release_year
 		FROM suggestions
-		WHERE guild_id = ? AND LOWER(movie_name) LIKE LOWER(?) AND user_id = ?
-		LIMIT 1`, guildID, "%"+movieName+"%", userID).Scan(&s.ID, &s.GuildID, &s.MovieName, &s.TMDBID, &s.UserID, &s.Username)
+		WHERE guild_id = ? AND user_id = ? AND LOWER(movie_name) LIKE LOWER(?) ESCAPE '\'
+		ORDER BY movie_name COLLATE NOCASE
+		LIMIT ?`, guildID, userID, likePrefix(prefix), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	if err == sql.ErrNoRows {
-		return nil, nil
+	var suggestions []Suggestion
+	for rows.Next() {
+		var s Suggestion
+		if err := rows.Scan(&s.ID, &s.GuildID, &s.MovieName, &s.TMDBID, &s.UserID, &s.Username, &s.ReleaseYear); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
 	}
+
+	return suggestions, rows.Err()
+}
+
+// SearchSuggestionsByPrefix busca entre as sugestões aprovadas; as pendentes e rejeitadas ficam com a fila de moderação
+func (d *Database) SearchSuggestionsByPrefix(guildID, prefix string, limit int) ([]Suggestion, error) {
+	rows, err := d.db.Query(`
+		SELECT id, guild_id, movie_name, tmdb_id, user_id, username, release_year
+		FROM suggestions
+		WHERE guild_id = ? AND status = ? AND LOWER(movie_name) LIKE LOWER(?) ESCAPE '\'
+		ORDER BY movie_name COLLATE NOCASE
+		LIMIT ?`, guildID, SuggestionApproved, likePrefix(prefix), limit)
 	if err != nil {
 		return nil, err
 	}
-	return &s, nil
+	defer rows.Close()
+
+	var suggestions []Suggestion
+	for rows.Next() {
+		var s Suggestion
+		if err := rows.Scan(&s.ID, &s.GuildID, &s.MovieName, &s.TMDBID, &s.UserID, &s.Username, &s.ReleaseYear); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
+
+	return suggestions, rows.Err()
 }
 
-func (d *Database) SearchAnySuggestion(guildID, movieName string) (*Suggestion, error) {
+func (d *Database) GetSuggestion(guildID string, suggestionID int) (*Suggestion, error) {
 	var s Suggestion
+	var selectedAt sql.NullTime
 	err := d.db.QueryRow(`
-		SELECT id, guild_id, movie_name, tmdb_id, user_id, username
-		FROM suggestions
-		WHERE guild_id = ? AND LOWER(movie_name) LIKE LOWER(?)
-		LIMIT 1`, guildID, "%"+movieName+"%").Scan(&s.ID, &s.GuildID, &s.MovieName, &s.TMDBID, &s.UserID, &s.Username)
+		SELECT s.id, s.guild_id, s.movie_name, s.tmdb_id, s.user_id, s.username, s.suggested_at,
+		       s.rating, s.genres, s.release_year, s.status, COALESCE(s.rejection_reason, ''),
+		       COALESCE(s.channel_id, ''), COALESCE(s.message_id, ''), sm.selected_at,
+		       COALESCE(s.poster_path, ''), COALESCE(s.overview, ''), COALESCE(s.runtime, 0), COALESCE(s.original_title, ''),
+		       COALESCE(s.media_type, '`+MediaMovie+`'), COALESCE(s.seasons, 0), COALESCE(s.episodes, 0)
+		FROM suggestions s
+		LEFT JOIN selected_movies sm ON sm.guild_id = s.guild_id AND sm.suggestion_id = s.id
+		WHERE s.guild_id = ? AND s.id = ?`, guildID, suggestionID).Scan(&s.ID, &s.GuildID, &s.MovieName, &s.TMDBID, &s.UserID, &s.Username,
+		&s.SuggestedAt, &s.Rating, &s.Genres, &s.ReleaseYear, &s.Status, &s.RejectionReason,
+		&s.ChannelID, &s.MessageID, &selectedAt,
+		&s.PosterPath, &s.Overview, &s.Runtime, &s.OriginalTitle, &s.MediaType, &s.Seasons, &s.Episodes)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -366,22 +851,105 @@ func (d *Database) SearchAnySuggestion(guildID, movieName string) (*Suggestion,
 	if err != nil {
 		return nil, err
 	}
+	if selectedAt.Valid {
+		s.SelectedAt = &selectedAt.Time
+	}
 	return &s, nil
 }
 
+// SetSuggestionMessage grava a mensagem do canal de sugestões que mostra a sugestão e o hash do conteúdo
+// com que ela foi escrita; vazio esquece a mensagem
+func (d *Database) SetSuggestionMessage(suggestionID int, channelID, messageID, postHash string) error {
+	_, err := d.db.Exec("UPDATE suggestions SET channel_id = NULLIF(?, ''), message_id = NULLIF(?, ''), post_hash = NULLIF(?, '') WHERE id = ?",
+		channelID, messageID, postHash, suggestionID)
+	return err
+}
+
+// SetSuggestionPostHash grava o hash do conteúdo depois de uma edição da mensagem da sugestão
+func (d *Database) SetSuggestionPostHash(suggestionID int, postHash string) error {
+	_, err := d.db.Exec("UPDATE suggestions SET post_hash = NULLIF(?, '') WHERE id = ?", postHash, suggestionID)
+	return err
+}
+
+// GetPostedSuggestions devolve as sugestões de todos os servidores que têm uma mensagem no canal de sugestões
+func (d *Database) GetPostedSuggestions() ([]Suggestion, error) {
+	rows, err := d.db.Query(`
+		SELECT id, guild_id, movie_name, channel_id, message_id, COALESCE(post_hash, '')
+		FROM suggestions
+		WHERE message_id IS NOT NULL
+		ORDER BY guild_id, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []Suggestion
+	for rows.Next() {
+		var s Suggestion
+		if err := rows.Scan(&s.ID, &s.GuildID, &s.MovieName, &s.ChannelID, &s.MessageID, &s.PostHash); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
+
+	return suggestions, rows.Err()
+}
+
+// RemoveSuggestion apaga a sugestão e tudo que aponta para ela. A data da sugestão fica guardada em
+// suggestion_cooldowns, para que remover uma sugestão não zere o intervalo entre sugestões do membro.
 func (d *Database) RemoveSuggestion(suggestionID int) error {
+	return d.removeSuggestion(suggestionID, true)
+}
+
+// DiscardSuggestion desfaz uma sugestão que não chegou a ser publicada; ela não conta para o intervalo
+func (d *Database) DiscardSuggestion(suggestionID int) error {
+	return d.removeSuggestion(suggestionID, false)
+}
+
+func (d *Database) removeSuggestion(suggestionID int, keepCooldown bool) error {
 	tx, err := d.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	if keepCooldown {
+		if _, err := tx.Exec(`
+			INSERT INTO suggestion_cooldowns (guild_id, user_id, last_suggested_at)
+			SELECT guild_id, user_id, suggested_at FROM suggestions WHERE id = ?
+			ON CONFLICT(guild_id, user_id)
+			DO UPDATE SET last_suggested_at = MAX(last_suggested_at, excluded.last_suggested_at)`, suggestionID); err != nil {
+			return err
+		}
+	}
+
 	if _, err := tx.Exec("DELETE FROM movie_reviews WHERE suggestion_id = ?", suggestionID); err != nil {
 		return err
 	}
+	if _, err := tx.Exec("DELETE FROM suggestion_votes WHERE suggestion_id = ?", suggestionID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM suggestion_genres WHERE suggestion_id = ?", suggestionID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM movie_reviews_archive WHERE suggestion_id = ?", suggestionID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM selected_movies_archive WHERE suggestion_id = ?", suggestionID); err != nil {
+		return err
+	}
 	if _, err := tx.Exec("DELETE FROM selected_movies WHERE suggestion_id = ?", suggestionID); err != nil {
 		return err
 	}
+	if _, err := tx.Exec("DELETE FROM vetoes WHERE suggestion_id = ?", suggestionID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM movie_poll_ballots WHERE suggestion_id = ?", suggestionID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM movie_poll_candidates WHERE suggestion_id = ?", suggestionID); err != nil {
+		return err
+	}
 	if _, err := tx.Exec("DELETE FROM suggestions WHERE id = ?", suggestionID); err != nil {
 		return err
 	}
@@ -389,25 +957,168 @@ func (d *Database) RemoveSuggestion(suggestionID int) error {
 	return tx.Commit()
 }
 
+// GetCurrentSeason devolve a temporada em andamento; cada /resetselections arquiva a temporada atual
+func (d *Database) GetCurrentSeason(guildID string) (int, error) {
+	var season int
+	err := d.db.QueryRow("SELECT COALESCE(MAX(season), 0) + 1 FROM selected_movies_archive WHERE guild_id = ?", guildID).Scan(&season)
+	return season, err
+}
+
+// UnselectMovie devolve um filme ao sorteio. Com archiveReviews as avaliações vão para o arquivo da
+// temporada atual; caso contrário continuam associadas ao filme e reaparecem se ele for selecionado de novo.
+func (d *Database) UnselectMovie(guildID string, suggestionID int, archiveReviews bool) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if archiveReviews {
+		var season int
+		err := tx.QueryRow("SELECT COALESCE(MAX(season), 0) + 1 FROM selected_movies_archive WHERE guild_id = ?", guildID).Scan(&season)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO movie_reviews_archive (suggestion_id, guild_id, user_id, username, rating, review_text, spoiler, reviewed_at, season, archived_at)
+			SELECT suggestion_id, guild_id, user_id, username, rating, review_text, spoiler, reviewed_at, ?, ?
+			FROM movie_reviews
+			WHERE guild_id = ? AND suggestion_id = ?`, season, time.Now(), guildID, suggestionID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("DELETE FROM movie_reviews WHERE guild_id = ? AND suggestion_id = ?", guildID, suggestionID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM selected_movies WHERE guild_id = ? AND suggestion_id = ?", guildID, suggestionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResetSelections arquiva todas as seleções e avaliações da temporada atual e torna todos os filmes sorteáveis de novo.
+// Sem filmes selecionados nada muda e archived volta 0: a temporada só avança quando há algo para arquivar,
+// já que o número dela (e o saldo de vetos) vem do arquivo.
+func (d *Database) ResetSelections(guildID string) (season int, archived int, err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow("SELECT COALESCE(MAX(season), 0) + 1 FROM selected_movies_archive WHERE guild_id = ?", guildID).Scan(&season)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	now := time.Now()
+
+	result, err := tx.Exec(`
+		INSERT INTO selected_movies_archive (guild_id, suggestion_id, season, selected_at, scheduled_for, archived_at)
+		SELECT guild_id, suggestion_id, ?, selected_at, scheduled_for, ?
+		FROM selected_movies
+		WHERE guild_id = ?`, season, now, guildID)
+	if err != nil {
+		return 0, 0, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+	if count == 0 {
+		return 0, 0, nil
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO movie_reviews_archive (suggestion_id, guild_id, user_id, username, rating, review_text, spoiler, reviewed_at, season, archived_at)
+		SELECT suggestion_id, guild_id, user_id, username, rating, review_text, spoiler, reviewed_at, ?, ?
+		FROM movie_reviews
+		WHERE guild_id = ?`, season, now, guildID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM movie_reviews WHERE guild_id = ?", guildID); err != nil {
+		return 0, 0, err
+	}
+	if _, err := tx.Exec("DELETE FROM selected_movies WHERE guild_id = ?", guildID); err != nil {
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	return season, int(count), nil
+}
+
 func (d *Database) IsMovieSelected(guildID string, suggestionID int) (bool, error) {
 	var count int
 	err := d.db.QueryRow("SELECT COUNT(*) FROM selected_movies WHERE guild_id = ? AND suggestion_id = ?", guildID, suggestionID).Scan(&count)
 	return count > 0, err
 }
 
+// SaveVote registra o voto do usuário; votar de novo no mesmo sentido remove o voto
+func (d *Database) SaveVote(guildID string, suggestionID int, userID string, vote int) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current int
+	err = tx.QueryRow("SELECT vote FROM suggestion_votes WHERE suggestion_id = ? AND user_id = ?", suggestionID, userID).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if err == nil && current == vote {
+		if _, err := tx.Exec("DELETE FROM suggestion_votes WHERE suggestion_id = ? AND user_id = ?", suggestionID, userID); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO suggestion_votes (guild_id, suggestion_id, user_id, vote, voted_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(suggestion_id, user_id)
+		DO UPDATE SET vote = excluded.vote, voted_at = excluded.voted_at`,
+		guildID, suggestionID, userID, vote, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (d *Database) GetVoteTotals(suggestionID int) (upvotes, downvotes int, err error) {
+	err = d.db.QueryRow(`
+		SELECT COALESCE(SUM(CASE WHEN vote = 1 THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN vote = -1 THEN 1 ELSE 0 END), 0)
+		FROM suggestion_votes
+		WHERE suggestion_id = ?`, suggestionID).Scan(&upvotes, &downvotes)
+	return
+}
+
 func (d *Database) SaveMovieReview(review *MovieReview) error {
 	_, err := d.db.Exec(`
-		INSERT INTO movie_reviews (suggestion_id, guild_id, user_id, username, rating, review_text, reviewed_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO movie_reviews (suggestion_id, guild_id, user_id, username, rating, review_text, spoiler, reviewed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(guild_id, suggestion_id, user_id) 
-		DO UPDATE SET rating = excluded.rating, review_text = excluded.review_text, reviewed_at = excluded.reviewed_at`,
-		review.SuggestionID, review.GuildID, review.UserID, review.Username, review.Rating, review.ReviewText, time.Now())
+		DO UPDATE SET rating = excluded.rating, review_text = excluded.review_text, spoiler = excluded.spoiler, reviewed_at = excluded.reviewed_at`,
+		review.SuggestionID, review.GuildID, review.UserID, review.Username, review.Rating, review.ReviewText, review.Spoiler, time.Now())
 	return err
 }
 
 func (d *Database) GetMovieReviews(guildID string, suggestionID int) ([]MovieReview, error) {
 	rows, err := d.db.Query(`
-		SELECT id, suggestion_id, guild_id, user_id, username, rating, COALESCE(review_text, ''), reviewed_at
+		SELECT id, suggestion_id, guild_id, user_id, username, rating, COALESCE(review_text, ''), COALESCE(spoiler, 0), reviewed_at
 		FROM movie_reviews
 		WHERE guild_id = ? AND suggestion_id = ?
 		ORDER BY reviewed_at DESC`, guildID, suggestionID)
@@ -420,7 +1131,7 @@ func (d *Database) GetMovieReviews(guildID string, suggestionID int) ([]MovieRev
 	var reviews []MovieReview
 	for rows.Next() {
 		var r MovieReview
-		err := rows.Scan(&r.ID, &r.SuggestionID, &r.GuildID, &r.UserID, &r.Username, &r.Rating, &r.ReviewText, &r.ReviewedAt)
+		err := rows.Scan(&r.ID, &r.SuggestionID, &r.GuildID, &r.UserID, &r.Username, &r.Rating, &r.ReviewText, &r.Spoiler, &r.ReviewedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -433,10 +1144,10 @@ func (d *Database) GetMovieReviews(guildID string, suggestionID int) ([]MovieRev
 func (d *Database) GetUserReview(guildID string, suggestionID int, userID string) (*MovieReview, error) {
 	var r MovieReview
 	err := d.db.QueryRow(`
-		SELECT id, suggestion_id, guild_id, user_id, username, rating, COALESCE(review_text, ''), reviewed_at
+		SELECT id, suggestion_id, guild_id, user_id, username, rating, COALESCE(review_text, ''), COALESCE(spoiler, 0), reviewed_at
 		FROM movie_reviews
 		WHERE guild_id = ? AND suggestion_id = ? AND user_id = ?`, guildID, suggestionID, userID).Scan(
-		&r.ID, &r.SuggestionID, &r.GuildID, &r.UserID, &r.Username, &r.Rating, &r.ReviewText, &r.ReviewedAt)
+		&r.ID, &r.SuggestionID, &r.GuildID, &r.UserID, &r.Username, &r.Rating, &r.ReviewText, &r.Spoiler, &r.ReviewedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -468,11 +1179,12 @@ func (d *Database) GetAverageMovieRating(guildID string, suggestionID int) (floa
 
 func (d *Database) GetAllSelectedMovies(guildID string) ([]SelectedMovieWithReviews, error) {
 	rows, err := d.db.Query(`
-		SELECT s.id, s.guild_id, s.movie_name, s.username, s.rating, s.genres, s.release_year, s.tmdb_id
+		SELECT `+movieResultColumns+`,
+		       sm.selected_at, COALESCE(sm.event_id, ''), sm.scheduled_for
 		FROM suggestions s
 		INNER JOIN selected_movies sm ON s.id = sm.suggestion_id AND s.guild_id = sm.guild_id
 		WHERE s.guild_id = ?
-		ORDER BY sm.selected_at DESC`, guildID)
+		ORDER BY COALESCE(sm.scheduled_for, sm.selected_at) DESC`, guildID)
 
 	if err != nil {
 		return nil, err
@@ -482,10 +1194,15 @@ func (d *Database) GetAllSelectedMovies(guildID string) ([]SelectedMovieWithRevi
 	var movies []SelectedMovieWithReviews
 	for rows.Next() {
 		var m SelectedMovieWithReviews
-		err := rows.Scan(&m.ID, &m.GuildID, &m.MovieName, &m.Username, &m.Rating, &m.Genres, &m.ReleaseYear, &m.TMDBID)
+		var scheduledFor sql.NullTime
+		result, err := scanMovieResult(rows, &m.SelectedAt, &m.EventID, &scheduledFor)
 		if err != nil {
 			return nil, err
 		}
+		m.MovieResult = *result
+		if scheduledFor.Valid {
+			m.ScheduledFor = &scheduledFor.Time
+		}
 
 		reviews, _ := d.GetMovieReviews(guildID, m.ID)
 		m.Reviews = reviews
@@ -500,14 +1217,37 @@ func (d *Database) GetAllSelectedMovies(guildID string) ([]SelectedMovieWithRevi
 	return movies, nil
 }
 
-func (d *Database) SearchSelectedMovie(guildID, movieName string) (*MovieResult, error) {
-	var m MovieResult
-	err := d.db.QueryRow(`
-		SELECT s.id, s.guild_id, s.movie_name, s.username, s.rating, s.genres, s.release_year, s.tmdb_id
+func (d *Database) SearchSelectedMoviesByPrefix(guildID, prefix string, limit int) ([]MovieResult, error) {
+	rows, err := d.db.Query(`
+		SELECT `+movieResultColumns+`
 		FROM suggestions s
 		INNER JOIN selected_movies sm ON s.id = sm.suggestion_id AND s.guild_id = sm.guild_id
-		WHERE s.guild_id = ? AND LOWER(s.movie_name) LIKE LOWER(?)
-		LIMIT 1`, guildID, "%"+movieName+"%").Scan(&m.ID, &m.GuildID, &m.MovieName, &m.Username, &m.Rating, &m.Genres, &m.ReleaseYear, &m.TMDBID)
+		WHERE s.guild_id = ? AND LOWER(s.movie_name) LIKE LOWER(?) ESCAPE '\'
+		ORDER BY sm.selected_at DESC
+		LIMIT ?`, guildID, likePrefix(prefix), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movies []MovieResult
+	for rows.Next() {
+		m, err := scanMovieResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		movies = append(movies, *m)
+	}
+
+	return movies, rows.Err()
+}
+
+func (d *Database) GetSelectedMovie(guildID string, suggestionID int) (*MovieResult, error) {
+	m, err := scanMovieResult(d.db.QueryRow(`
+		SELECT `+movieResultColumns+`
+		FROM suggestions s
+		INNER JOIN selected_movies sm ON s.id = sm.suggestion_id AND s.guild_id = sm.guild_id
+		WHERE s.guild_id = ? AND s.id = ?`, guildID, suggestionID))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -515,7 +1255,7 @@ func (d *Database) SearchSelectedMovie(guildID, movieName string) (*MovieResult,
 	if err != nil {
 		return nil, err
 	}
-	return &m, nil
+	return m, nil
 }
 
 func (d *Database) SaveGuildConfig(guildID, channelID string) error {
@@ -529,13 +1269,59 @@ func (d *Database) SaveGuildConfig(guildID, channelID string) error {
 	return err
 }
 
+// SaveEventSettings grava o canal de voz/palco dos eventos e o fuso horário usado para interpretar datas
+func (d *Database) SaveEventSettings(guildID, eventChannelID, timezone string) error {
+	_, err := d.db.Exec(`
+		UPDATE guild_configs SET event_channel_id = ?, timezone = ?
+		WHERE guild_id = ?`, eventChannelID, timezone, guildID)
+	return err
+}
+
+// SaveRoleSettings grava o cargo de anfitrião e o cargo exigido para sugerir; string vazia remove a restrição
+func (d *Database) SaveRoleSettings(guildID, hostRoleID, suggesterRoleID string) error {
+	_, err := d.db.Exec(`
+		UPDATE guild_configs SET host_role_id = ?, suggester_role_id = ?
+		WHERE guild_id = ?`, hostRoleID, suggesterRoleID, guildID)
+	return err
+}
+
+func (d *Database) SavePickStrategy(guildID, strategy string) error {
+	_, err := d.db.Exec("UPDATE guild_configs SET pick_strategy = ? WHERE guild_id = ?", strategy, guildID)
+	return err
+}
+
+func (d *Database) SaveVetoSettings(guildID string, vetoesPerSeason int, banVetoed bool) error {
+	_, err := d.db.Exec("UPDATE guild_configs SET vetoes_per_season = ?, ban_vetoed = ? WHERE guild_id = ?",
+		vetoesPerSeason, banVetoed, guildID)
+	return err
+}
+
+// SaveSuggestionLimits grava o limite de sugestões abertas e o intervalo entre sugestões; zero desativa cada um
+func (d *Database) SaveSuggestionLimits(guildID string, maxOpen int, cooldown time.Duration) error {
+	_, err := d.db.Exec("UPDATE guild_configs SET max_open_suggestions = ?, suggestion_cooldown_minutes = ? WHERE guild_id = ?",
+		maxOpen, int(cooldown/time.Minute), guildID)
+	return err
+}
+
 func (d *Database) GetGuildConfig(guildID string) (*GuildConfig, error) {
 	var config GuildConfig
+	var cooldownMinutes int
 	err := d.db.QueryRow(`
-		SELECT id, guild_id, suggestion_channel_id, configured_at
+		SELECT id, guild_id, suggestion_channel_id, configured_at,
+		       COALESCE(event_channel_id, ''), COALESCE(timezone, ''),
+		       COALESCE(host_role_id, ''), COALESCE(suggester_role_id, ''),
+		       COALESCE(pick_strategy, '`+PickStrategyUniform+`'),
+		       COALESCE(vetoes_per_season, 0), COALESCE(ban_vetoed, 0),
+		       COALESCE(max_open_suggestions, 0), COALESCE(suggestion_cooldown_minutes, 0),
+		       COALESCE(require_approval, 0), COALESCE(mod_channel_id, ''),
+		       COALESCE(discussion_forum_id, ''), COALESCE(language, '')
 		FROM guild_configs
 		WHERE guild_id = ?`, guildID).Scan(
-		&config.ID, &config.GuildID, &config.SuggestionChannelID, &config.ConfiguredAt)
+		&config.ID, &config.GuildID, &config.SuggestionChannelID, &config.ConfiguredAt,
+		&config.EventChannelID, &config.Timezone, &config.HostRoleID, &config.SuggesterRoleID,
+		&config.PickStrategy, &config.VetoesPerSeason, &config.BanVetoed,
+		&config.MaxOpenSuggestions, &cooldownMinutes, &config.RequireApproval, &config.ModChannelID,
+		&config.DiscussionForumID, &config.Language)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -543,6 +1329,7 @@ func (d *Database) GetGuildConfig(guildID string) (*GuildConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	config.SuggestionCooldown = time.Duration(cooldownMinutes) * time.Minute
 	return &config, nil
 }
 
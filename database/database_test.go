@@ -0,0 +1,335 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testGuildID = "guild1"
+
+// newTestDB abre um banco SQLite novo em um arquivo temporário, já com todas as migrações aplicadas
+func newTestDB(t *testing.T) *Database {
+	t.Helper()
+
+	d, err := New(filepath.Join(t.TempDir(), "clapper.db"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// suggest grava uma sugestão aprovada do membro e devolve o ID dela
+func suggest(t *testing.T, d *Database, userID string, tmdbID int, year string, genreIDs ...int) int {
+	t.Helper()
+
+	id, err := d.SaveSuggestion(&Suggestion{
+		GuildID:     testGuildID,
+		MovieName:   year + " movie",
+		UserID:      userID,
+		Username:    "user" + userID,
+		TMDBID:      tmdbID,
+		Rating:      7,
+		ReleaseYear: year,
+		GenreIDs:    genreIDs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int(id)
+}
+
+// pickableIDs devolve, em ordem, os IDs que o sorteio do /pickmovie pode escolher com o filtro
+func pickableIDs(t *testing.T, d *Database, filter MovieFilter) []int {
+	t.Helper()
+
+	movies, err := d.GetRandomMovies(testGuildID, filter, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int, len(movies))
+	for i, movie := range movies {
+		ids[i] = movie.ID
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPickableSuggestions(t *testing.T) {
+	d := newTestDB(t)
+
+	approved := suggest(t, d, "1", 1, "1979")
+	pending := suggest(t, d, "1", 2, "1986")
+	rejected := suggest(t, d, "2", 3, "1995")
+	selected := suggest(t, d, "2", 4, "2019")
+	banned := suggest(t, d, "3", 5, "2001")
+	vetoed := suggest(t, d, "3", 6, "2002")
+	suggest(t, d, "3", 7, "2003")
+	d.db.Exec("UPDATE suggestions SET guild_id = 'guild2' WHERE tmdb_id = 7")
+
+	d.SetSuggestionStatus(testGuildID, pending, SuggestionApproved, SuggestionPending, "", "")
+	d.SetSuggestionStatus(testGuildID, rejected, SuggestionApproved, SuggestionRejected, "mod", "no")
+	if err := d.MarkMovieSelected(testGuildID, selected); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.SaveVeto(testGuildID, banned, "1", "user1", true, 2, MovieFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.SaveVeto(testGuildID, vetoed, "1", "user1", false, 2, MovieFilter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Só ficam as aprovadas, não selecionadas, sem banimento e do próprio servidor
+	if got, want := pickableIDs(t, d, MovieFilter{}), []int{approved, vetoed}; !equalIDs(got, want) {
+		t.Errorf("pickable = %v, want %v", got, want)
+	}
+
+	// O banimento vale só para a temporada do veto
+	if _, _, err := d.ResetSelections(testGuildID); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pickableIDs(t, d, MovieFilter{}), []int{approved, selected, banned, vetoed}; !equalIDs(got, want) {
+		t.Errorf("pickable after the season reset = %v, want %v", got, want)
+	}
+}
+
+func TestResetSelectionsWithNothingSelected(t *testing.T) {
+	d := newTestDB(t)
+
+	vetoed := suggest(t, d, "1", 1, "1979")
+	suggest(t, d, "1", 2, "1986")
+	if _, err := d.SaveVeto(testGuildID, vetoed, "1", "user1", true, 1, MovieFilter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Um reset vazio não abre temporada nova, então o veto e o banimento continuam valendo
+	season, archived, err := d.ResetSelections(testGuildID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if season != 0 || archived != 0 {
+		t.Errorf("ResetSelections = (%d, %d), want (0, 0)", season, archived)
+	}
+	if current, _ := d.GetCurrentSeason(testGuildID); current != 1 {
+		t.Errorf("current season = %d, want 1", current)
+	}
+	if got := pickableIDs(t, d, MovieFilter{}); len(got) != 1 || got[0] == vetoed {
+		t.Errorf("the vetoed movie should stay banned, pickable = %v", got)
+	}
+}
+
+func TestMovieFilter(t *testing.T) {
+	d := newTestDB(t)
+
+	alien := suggest(t, d, "1", 348, "1979", 27, 878)
+	heat := suggest(t, d, "2", 949, "1995", 28, 80)
+	parasite := suggest(t, d, "2", 496243, "2019", 35, 18)
+	unknownYear := suggest(t, d, "3", 1, "Unknown", 27)
+
+	tests := []struct {
+		name   string
+		filter MovieFilter
+		want   []int
+	}{
+		{name: "no filter", want: []int{alien, heat, parasite, unknownYear}},
+		{name: "genre", filter: MovieFilter{GenreID: 27}, want: []int{alien, unknownYear}},
+		{name: "year range skips unknown years", filter: MovieFilter{MinYear: 1980, MaxYear: 2000}, want: []int{heat}},
+		{name: "min year", filter: MovieFilter{MinYear: 1990}, want: []int{heat, parasite}},
+		{name: "exclude suggester", filter: MovieFilter{ExcludeUserID: "2"}, want: []int{alien, unknownYear}},
+		{name: "exclude suggestion", filter: MovieFilter{ExcludeSuggestionID: heat}, want: []int{alien, parasite, unknownYear}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickableIDs(t, d, tt.filter); !equalIDs(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetUserSuggestionActivity(t *testing.T) {
+	d := newTestDB(t)
+
+	open, last, err := d.GetUserSuggestionActivity(testGuildID, "1")
+	if err != nil || open != 0 || !last.IsZero() {
+		t.Fatalf("expected no activity, got %d, %v, %v", open, last, err)
+	}
+
+	before := time.Now().Add(-time.Second)
+	suggest(t, d, "1", 1, "1979")
+	selected := suggest(t, d, "1", 2, "1986")
+	rejected := suggest(t, d, "1", 3, "1995")
+	suggest(t, d, "2", 4, "2019")
+
+	d.MarkMovieSelected(testGuildID, selected)
+	d.SetSuggestionStatus(testGuildID, rejected, SuggestionApproved, SuggestionRejected, "mod", "no")
+
+	open, last, err = d.GetUserSuggestionActivity(testGuildID, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if open != 1 {
+		t.Errorf("expected only the unselected, unrejected suggestion to be open, got %d", open)
+	}
+	if last.Before(before) {
+		t.Errorf("expected the last suggestion time, got %v", last)
+	}
+
+	if open, _, _ := d.GetUserSuggestionActivity("guild2", "1"); open != 0 {
+		t.Errorf("suggestions from another server should not count, got %d", open)
+	}
+}
+
+func TestRemovedSuggestionsKeepTheCooldown(t *testing.T) {
+	d := newTestDB(t)
+	before := time.Now().Add(-time.Second)
+
+	if err := d.RemoveSuggestion(suggest(t, d, "1", 1, "1979")); err != nil {
+		t.Fatal(err)
+	}
+	open, last, err := d.GetUserSuggestionActivity(testGuildID, "1")
+	if err != nil || open != 0 || last.Before(before) {
+		t.Errorf("expected the removed suggestion to keep the cooldown, got %d, %v, %v", open, last, err)
+	}
+	if _, err := d.SaveSuggestionWithinLimits(&Suggestion{GuildID: testGuildID, UserID: "1", TMDBID: 2}, 0, time.Hour); err == nil {
+		t.Error("expected the cooldown to refuse a new suggestion")
+	}
+
+	// Uma sugestão desfeita porque não foi publicada não conta
+	if err := d.DiscardSuggestion(suggest(t, d, "2", 3, "1986")); err != nil {
+		t.Fatal(err)
+	}
+	if _, last, _ := d.GetUserSuggestionActivity(testGuildID, "2"); !last.IsZero() {
+		t.Errorf("expected a discarded suggestion not to count, got %v", last)
+	}
+}
+
+func TestSaveSuggestionWithinLimits(t *testing.T) {
+	d := newTestDB(t)
+	suggest(t, d, "1", 1, "1979")
+
+	_, err := d.SaveSuggestionWithinLimits(&Suggestion{GuildID: testGuildID, UserID: "1", TMDBID: 2}, 1, 0)
+	var limitErr *SuggestionLimitError
+	if !errors.As(err, &limitErr) || limitErr.Open != 1 || limitErr.LastSuggestedAt.IsZero() {
+		t.Fatalf("expected a limit error with the activity, got %v", err)
+	}
+
+	// A contagem e a gravação ficam na mesma transação, então sugestões simultâneas não passam juntas do limite
+	var wg sync.WaitGroup
+	for tmdbID := 10; tmdbID < 20; tmdbID++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.SaveSuggestionWithinLimits(&Suggestion{GuildID: testGuildID, UserID: "2", TMDBID: tmdbID}, 1, 0)
+		}()
+	}
+	wg.Wait()
+	if open, _, err := d.GetUserSuggestionActivity(testGuildID, "2"); err != nil || open != 1 {
+		t.Errorf("expected exactly one concurrent suggestion to be saved, got %d, %v", open, err)
+	}
+}
+
+func TestRejectedSuggestions(t *testing.T) {
+	d := newTestDB(t)
+	rejected := suggest(t, d, "1", 949, "1995")
+	approved := suggest(t, d, "2", 348, "1979")
+	if ok, err := d.SetSuggestionStatus(testGuildID, rejected, SuggestionApproved, SuggestionRejected, "mod", "Seen it"); err != nil || !ok {
+		t.Fatalf("could not reject the suggestion: %v, %v", ok, err)
+	}
+
+	if exists, err := d.MovieAlreadySuggested(testGuildID, MediaMovie, 949); err != nil || exists {
+		t.Errorf("a rejected title should not count as suggested, got %v, %v", exists, err)
+	}
+	if suggester, err := d.GetMovieSuggester(testGuildID, MediaMovie, 949); err != nil || suggester != "" {
+		t.Errorf("expected no suggester for a rejected title, got %q, %v", suggester, err)
+	}
+
+	// O índice único ignora as rejeitadas, mas continua valendo para as demais
+	again := suggest(t, d, "2", 949, "1995")
+	if _, err := d.SaveSuggestion(&Suggestion{GuildID: testGuildID, MovieName: "Heat", UserID: "3", TMDBID: 949}); err == nil {
+		t.Error("expected the second active suggestion of the same title to be refused")
+	}
+
+	suggestions, err := d.SearchSuggestionsByPrefix(testGuildID, "19", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, s := range suggestions {
+		ids = append(ids, s.ID)
+	}
+	sort.Ints(ids)
+	if !equalIDs(ids, []int{approved, again}) {
+		t.Errorf("expected only the approved suggestions %v, got %v", []int{approved, again}, ids)
+	}
+}
+
+func TestSuggestionPostState(t *testing.T) {
+	d := newTestDB(t)
+	id := suggest(t, d, "1", 949, "1995")
+	suggest(t, d, "2", 348, "1979")
+
+	if err := d.SetSuggestionMessage(id, "channel1", "message1", "hash1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetSuggestionPostHash(id, "hash2"); err != nil {
+		t.Fatal(err)
+	}
+	posted, err := d.GetPostedSuggestions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posted) != 1 || posted[0].ID != id || posted[0].MessageID != "message1" || posted[0].PostHash != "hash2" {
+		t.Fatalf("unexpected posted suggestions %+v", posted)
+	}
+
+	// Esquecer a mensagem também esquece o conteúdo que ela tinha
+	if err := d.SetSuggestionMessage(id, "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if posted, _ := d.GetPostedSuggestions(); len(posted) != 0 {
+		t.Errorf("expected the post to be forgotten, got %+v", posted)
+	}
+}
+
+func TestReviewSpoilers(t *testing.T) {
+	d := newTestDB(t)
+	id := suggest(t, d, "1", 949, "1995")
+	if err := d.MarkMovieSelected(testGuildID, id); err != nil {
+		t.Fatal(err)
+	}
+
+	review := &MovieReview{SuggestionID: id, GuildID: testGuildID, UserID: "1", Username: "user1", Rating: 9, ReviewText: "The ending!", Spoiler: true}
+	if err := d.SaveMovieReview(review); err != nil {
+		t.Fatal(err)
+	}
+	if saved, _ := d.GetUserReview(testGuildID, id, "1"); saved == nil || !saved.Spoiler {
+		t.Fatalf("expected the spoiler flag to be saved, got %+v", saved)
+	}
+
+	// Atualizar a avaliação também atualiza a resposta sobre spoilers
+	review.Spoiler = false
+	if err := d.SaveMovieReview(review); err != nil {
+		t.Fatal(err)
+	}
+	if reviews, _ := d.GetMovieReviews(testGuildID, id); len(reviews) != 1 || reviews[0].Spoiler {
+		t.Errorf("expected the spoiler flag to be cleared, got %+v", reviews)
+	}
+}
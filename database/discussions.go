@@ -0,0 +1,14 @@
+package database
+
+// SaveDiscussionSettings grava o fórum dos tópicos de discussão; vazio volta a abrir o tópico na mensagem de confirmação
+func (d *Database) SaveDiscussionSettings(guildID, forumID string) error {
+	_, err := d.db.Exec("UPDATE guild_configs SET discussion_forum_id = NULLIF(?, '') WHERE guild_id = ?", forumID, guildID)
+	return err
+}
+
+// SetDiscussionThread guarda o tópico de discussão do filme selecionado
+func (d *Database) SetDiscussionThread(guildID string, suggestionID int, threadID string) error {
+	_, err := d.db.Exec("UPDATE selected_movies SET thread_id = ? WHERE guild_id = ? AND suggestion_id = ?",
+		threadID, guildID, suggestionID)
+	return err
+}
@@ -0,0 +1,63 @@
+package database
+
+import "strings"
+
+// MovieFilter restringe o sorteio do /pickmovie; campos zerados não filtram nada
+type MovieFilter struct {
+	GenreID       int
+	MinYear       int
+	MaxYear       int
+	MaxRuntime    int
+	MinTMDBRating float64
+	ExcludeUserID string
+	// ExcludeSuggestionID evita que um reroll devolva o filme que acabou de ser recusado; não vai para o botão
+	ExcludeSuggestionID int
+}
+
+func (f MovieFilter) IsEmpty() bool {
+	return f == MovieFilter{}
+}
+
+// whereClause devolve condições extras (prefixadas com AND) sobre o alias "s" de suggestions
+func (f MovieFilter) whereClause() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if f.GenreID > 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM suggestion_genres sg WHERE sg.suggestion_id = s.id AND sg.genre_id = ?)")
+		args = append(args, f.GenreID)
+	}
+	if f.MinYear > 0 || f.MaxYear > 0 {
+		conditions = append(conditions, "s.release_year GLOB '[0-9][0-9][0-9][0-9]'")
+	}
+	if f.MinYear > 0 {
+		conditions = append(conditions, "CAST(s.release_year AS INTEGER) >= ?")
+		args = append(args, f.MinYear)
+	}
+	if f.MaxYear > 0 {
+		conditions = append(conditions, "CAST(s.release_year AS INTEGER) <= ?")
+		args = append(args, f.MaxYear)
+	}
+	if f.MaxRuntime > 0 {
+		conditions = append(conditions, "s.runtime > 0 AND s.runtime <= ?")
+		args = append(args, f.MaxRuntime)
+	}
+	if f.MinTMDBRating > 0 {
+		conditions = append(conditions, "s.rating >= ?")
+		args = append(args, f.MinTMDBRating)
+	}
+	if f.ExcludeUserID != "" {
+		conditions = append(conditions, "s.user_id != ?")
+		args = append(args, f.ExcludeUserID)
+	}
+	if f.ExcludeSuggestionID > 0 {
+		conditions = append(conditions, "s.id != ?")
+		args = append(args, f.ExcludeSuggestionID)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return " AND " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,26 @@
+package database
+
+// SaveLanguage grava o idioma do TMDB do servidor; vazio volta ao idioma padrão do bot
+func (d *Database) SaveLanguage(guildID, language string) error {
+	_, err := d.db.Exec("UPDATE guild_configs SET language = NULLIF(?, '') WHERE guild_id = ?", language, guildID)
+	return err
+}
+
+// GetGuildLanguages lista os idiomas configurados nos servidores, sem repetições
+func (d *Database) GetGuildLanguages() ([]string, error) {
+	rows, err := d.db.Query("SELECT DISTINCT language FROM guild_configs WHERE language IS NOT NULL AND language != '' ORDER BY language")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var languages []string
+	for rows.Next() {
+		var language string
+		if err := rows.Scan(&language); err != nil {
+			return nil, err
+		}
+		languages = append(languages, language)
+	}
+	return languages, rows.Err()
+}
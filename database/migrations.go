@@ -0,0 +1,799 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// migration é um passo versionado do schema. Cada passo roda na sua própria transação e
+// precisa ser idempotente, porque bancos criados antes do controle de versões já têm parte das tabelas.
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx, d *Database) error
+}
+
+// errMigrationPending faz um passo não ser registrado como aplicado, para que ele rode de novo na próxima
+// inicialização; serve aos passos que dependem de uma configuração que ainda falta
+var errMigrationPending = errors.New("migration left pending")
+
+// migrations deve permanecer em ordem crescente de versão; novos passos entram sempre no final
+var migrations = []migration{
+	{1, "multi-guild base schema", migrateBaseSchema},
+	{2, "recover single-guild data from *_old tables", migrateRecoverLegacyData},
+	{3, "tmdb response cache", migrateTMDBCache},
+	{4, "suggestion votes", migrateSuggestionVotes},
+	{5, "suggestion runtime and genres", migrateSuggestionRuntimeAndGenres},
+	{6, "scheduled screenings and event settings", migrateScreenings},
+	{7, "season archives", migrateSeasonArchives},
+	{8, "host and suggester roles", migrateRoles},
+	{9, "full TMDB metadata on suggestions", migrateSuggestionMetadata},
+	{10, "ranked-choice movie polls", migrateMoviePolls},
+	{11, "per-guild picking strategy", migratePickStrategy},
+	{12, "veto tokens", migrateVetoes},
+	{13, "per-user suggestion limits", migrateSuggestionLimits},
+	{14, "suggestion moderation queue", migrateSuggestionModeration},
+	{15, "suggestion channel messages", migrateSuggestionMessages},
+	{16, "discussion threads", migrateDiscussionThreads},
+	{17, "blind ratings", migrateBlindRatings},
+	{18, "guild language", migrateGuildLanguage},
+	{19, "tv series suggestions", migrateMediaTypes},
+	{20, "re-suggest rejected titles", migrateRejectedResuggestions},
+	{21, "suggestion post hashes", migrateSuggestionPostHashes},
+	{22, "suggestion cooldowns of removed suggestions", migrateSuggestionCooldowns},
+	{23, "review spoiler flags", migrateReviewSpoilers},
+}
+
+func latestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+func (d *Database) migrate() error {
+	_, err := d.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	applied, err := d.appliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	current := 0
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	if current > latestSchemaVersion() {
+		return fmt.Errorf("database schema version %d is newer than this binary supports (%d); upgrade the bot before using this database", current, latestSchemaVersion())
+	}
+
+	// Passos deixados pendentes em inicializações anteriores rodam de novo, mesmo depois de versões mais novas
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		log.Printf("Aplicando migração %d: %s", m.version, m.description)
+		if err := d.applyMigration(m); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+	}
+
+	log.Printf("Database schema is at version %d", latestSchemaVersion())
+	return nil
+}
+
+func (d *Database) appliedMigrations() (map[int]bool, error) {
+	rows, err := d.db.Query("SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+func (d *Database) applyMigration(m migration) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = m.up(tx, d)
+	if errors.Is(err, errMigrationPending) {
+		log.Printf("Migração %d adiada para a próxima inicialização", m.version)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)",
+		m.version, m.description, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func execAll(tx *sql.Tx, queries ...string) error {
+	for _, query := range queries {
+		if _, err := tx.Exec(query); err != nil {
+			log.Printf("Erro ao executar query: %s\nErro: %v", query, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func tableExists(tx *sql.Tx, table string) (bool, error) {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count)
+	return count > 0, err
+}
+
+func tableColumns(tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
+
+func hasColumn(columns []string, column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// addColumn adiciona a coluna apenas se ela ainda não existir
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	columns, err := tableColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	if hasColumn(columns, column) {
+		return nil
+	}
+
+	_, err = tx.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	return err
+}
+
+func migrateBaseSchema(tx *sql.Tx, d *Database) error {
+	// Bancos da versão single-guild têm suggestions sem guild_id: as tabelas são preservadas como *_old
+	// e os dados são recuperados pela migração seguinte
+	exists, err := tableExists(tx, "suggestions")
+	if err != nil {
+		return err
+	}
+	if exists {
+		columns, err := tableColumns(tx, "suggestions")
+		if err != nil {
+			return err
+		}
+		if !hasColumn(columns, "guild_id") {
+			log.Println("Migrando banco de dados para suporte multi-guild...")
+			for _, table := range []string{"suggestions", "selected_movies", "movie_reviews"} {
+				legacy, err := tableExists(tx, table)
+				if err != nil {
+					return err
+				}
+				if !legacy {
+					continue
+				}
+				if _, err := tx.Exec("ALTER TABLE " + table + " RENAME TO " + table + "_old"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return execAll(tx,
+		`CREATE TABLE IF NOT EXISTS suggestions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guild_id TEXT NOT NULL,
+			movie_name TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			username TEXT NOT NULL,
+			suggested_at TIMESTAMP NOT NULL,
+			tmdb_id INTEGER NOT NULL,
+			rating REAL,
+			genres TEXT,
+			release_year TEXT,
+			UNIQUE(guild_id, tmdb_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS selected_movies (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guild_id TEXT NOT NULL,
+			suggestion_id INTEGER NOT NULL,
+			selected_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (suggestion_id) REFERENCES suggestions (id) ON DELETE CASCADE,
+			UNIQUE(guild_id, suggestion_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS movie_reviews (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			suggestion_id INTEGER NOT NULL,
+			guild_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			username TEXT NOT NULL,
+			rating REAL NOT NULL CHECK(rating >= 0 AND rating <= 10),
+			review_text TEXT,
+			reviewed_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (suggestion_id) REFERENCES suggestions (id) ON DELETE CASCADE,
+			UNIQUE(guild_id, suggestion_id, user_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS guild_configs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guild_id TEXT NOT NULL UNIQUE,
+			suggestion_channel_id TEXT NOT NULL,
+			configured_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_suggestions_guild_id ON suggestions(guild_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_suggestions_user_id ON suggestions(user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_suggestions_tmdb_id ON suggestions(tmdb_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_suggestions_guild_tmdb ON suggestions(guild_id, tmdb_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_selected_movies_guild_id ON selected_movies(guild_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_selected_movies_suggestion_id ON selected_movies(suggestion_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_movie_reviews_guild_id ON movie_reviews(guild_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_movie_reviews_suggestion_id ON movie_reviews(suggestion_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_movie_reviews_user_id ON movie_reviews(user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_guild_configs_guild_id ON guild_configs(guild_id)`,
+	)
+}
+
+// legacyGuildID descobre a qual servidor pertencem os dados da versão single-guild:
+// o ID configurado explicitamente ou, na falta dele, o único servidor configurado no banco
+func legacyGuildID(tx *sql.Tx, d *Database) (string, error) {
+	if d.legacyGuildID != "" {
+		return d.legacyGuildID, nil
+	}
+
+	rows, err := tx.Query("SELECT guild_id FROM guild_configs")
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var guilds []string
+	for rows.Next() {
+		var guildID string
+		if err := rows.Scan(&guildID); err != nil {
+			return "", err
+		}
+		guilds = append(guilds, guildID)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	if len(guilds) == 1 {
+		return guilds[0], nil
+	}
+	return "", nil
+}
+
+// copyLegacyColumns monta a lista de colunas em comum entre a tabela antiga e a nova, sem id e guild_id
+func copyLegacyColumns(tx *sql.Tx, oldTable, newTable string, skip ...string) ([]string, error) {
+	oldColumns, err := tableColumns(tx, oldTable)
+	if err != nil {
+		return nil, err
+	}
+	newColumns, err := tableColumns(tx, newTable)
+	if err != nil {
+		return nil, err
+	}
+
+	skip = append(skip, "id", "guild_id")
+	var common []string
+	for _, column := range oldColumns {
+		if hasColumn(skip, column) || !hasColumn(newColumns, column) {
+			continue
+		}
+		common = append(common, column)
+	}
+	return common, nil
+}
+
+func migrateRecoverLegacyData(tx *sql.Tx, d *Database) error {
+	exists, err := tableExists(tx, "suggestions_old")
+	if err != nil || !exists {
+		return err
+	}
+
+	var legacyCount int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM suggestions_old").Scan(&legacyCount); err != nil {
+		return err
+	}
+	if legacyCount == 0 {
+		return nil
+	}
+
+	oldColumns, err := tableColumns(tx, "suggestions_old")
+	if err != nil {
+		return err
+	}
+	if !hasColumn(oldColumns, "tmdb_id") || !hasColumn(oldColumns, "id") {
+		log.Printf("suggestions_old não tem tmdb_id; %d sugestões antigas não podem ser recuperadas automaticamente", legacyCount)
+		return nil
+	}
+
+	guildID, err := legacyGuildID(tx, d)
+	if err != nil {
+		return err
+	}
+	if guildID == "" {
+		// Sem saber o servidor os dados ficam nas tabelas *_old e a recuperação é tentada de novo a cada inicialização
+		log.Printf("AVISO: %d sugestões da versão single-guild não foram recuperadas porque não há como saber a qual servidor pertencem. "+
+			"Defina LEGACY_GUILD_ID com o ID desse servidor e reinicie o bot; até lá elas ficam em suggestions_old", legacyCount)
+		return errMigrationPending
+	}
+
+	log.Printf("Recuperando %d sugestões antigas para a guild %s", legacyCount, guildID)
+
+	columns, err := copyLegacyColumns(tx, "suggestions_old", "suggestions")
+	if err != nil {
+		return err
+	}
+	columnList := strings.Join(columns, ", ")
+
+	// Filmes que já foram sugeridos de novo depois da migração quebrada mantêm a linha nova
+	result, err := tx.Exec(`INSERT OR IGNORE INTO suggestions (guild_id, `+columnList+`)
+		SELECT ?, `+columnList+` FROM suggestions_old`, guildID)
+	if err != nil {
+		return err
+	}
+	reportSkippedLegacyRows("suggestions", legacyCount, result)
+
+	// Os IDs antigos não são reaproveitados, então as tabelas dependentes são religadas via tmdb_id
+	err = execAll(tx,
+		`CREATE TEMP TABLE legacy_suggestion_ids (old_id INTEGER PRIMARY KEY, new_id INTEGER NOT NULL)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer tx.Exec("DROP TABLE IF EXISTS temp.legacy_suggestion_ids")
+
+	// Quando a recuperação roda depois da migração das séries, só os filmes correspondem às sugestões antigas
+	newColumns, err := tableColumns(tx, "suggestions")
+	if err != nil {
+		return err
+	}
+	onlyMovies := ""
+	if hasColumn(newColumns, "media_type") {
+		onlyMovies = " AND s.media_type = '" + MediaMovie + "'"
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO legacy_suggestion_ids (old_id, new_id)
+		SELECT o.id, s.id
+		FROM suggestions_old o
+		INNER JOIN suggestions s ON s.guild_id = ? AND s.tmdb_id = o.tmdb_id`+onlyMovies, guildID)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"selected_movies", "movie_reviews"} {
+		oldTable := table + "_old"
+		exists, err := tableExists(tx, oldTable)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		columns, err := copyLegacyColumns(tx, oldTable, table, "suggestion_id")
+		if err != nil {
+			return err
+		}
+
+		var selectList []string
+		for _, column := range columns {
+			selectList = append(selectList, "o."+column)
+		}
+
+		insertColumns := "guild_id, suggestion_id"
+		selectColumns := "?, m.new_id"
+		if len(columns) > 0 {
+			insertColumns += ", " + strings.Join(columns, ", ")
+			selectColumns += ", " + strings.Join(selectList, ", ")
+		}
+
+		var oldCount int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM " + oldTable).Scan(&oldCount); err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(`INSERT OR IGNORE INTO `+table+` (`+insertColumns+`)
+			SELECT `+selectColumns+`
+			FROM `+oldTable+` o
+			INNER JOIN legacy_suggestion_ids m ON m.old_id = o.suggestion_id`, guildID)
+		if err != nil {
+			return err
+		}
+		reportSkippedLegacyRows(table, oldCount, result)
+	}
+
+	log.Println("Dados antigos recuperados; as tabelas *_old foram mantidas como backup")
+	return nil
+}
+
+// reportSkippedLegacyRows registra quantas linhas antigas não foram copiadas por já existirem na tabela nova
+// ou por apontarem para uma sugestão que não pôde ser recuperada; elas continuam na tabela *_old
+func reportSkippedLegacyRows(table string, total int, result sql.Result) {
+	copied, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if skipped := total - int(copied); skipped > 0 {
+		log.Printf("AVISO: %d de %d linhas de %s_old não foram recuperadas (duplicadas ou sem sugestão correspondente); elas continuam em %s_old",
+			skipped, total, table, table)
+	}
+}
+
+func migrateTMDBCache(tx *sql.Tx, d *Database) error {
+	return execAll(tx,
+		`CREATE TABLE IF NOT EXISTS tmdb_cache (
+			cache_key TEXT PRIMARY KEY,
+			payload TEXT NOT NULL,
+			fetched_at TIMESTAMP NOT NULL
+		)`,
+	)
+}
+
+func migrateSuggestionVotes(tx *sql.Tx, d *Database) error {
+	return execAll(tx,
+		`CREATE TABLE IF NOT EXISTS suggestion_votes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guild_id TEXT NOT NULL,
+			suggestion_id INTEGER NOT NULL,
+			user_id TEXT NOT NULL,
+			vote INTEGER NOT NULL CHECK(vote IN (-1, 1)),
+			voted_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (suggestion_id) REFERENCES suggestions (id) ON DELETE CASCADE,
+			UNIQUE(suggestion_id, user_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_suggestion_votes_suggestion_id ON suggestion_votes(suggestion_id)`,
+	)
+}
+
+func migrateSuggestionRuntimeAndGenres(tx *sql.Tx, d *Database) error {
+	if err := addColumn(tx, "suggestions", "runtime", "INTEGER"); err != nil {
+		return err
+	}
+
+	return execAll(tx,
+		`CREATE TABLE IF NOT EXISTS suggestion_genres (
+			suggestion_id INTEGER NOT NULL,
+			genre_id INTEGER NOT NULL,
+			FOREIGN KEY (suggestion_id) REFERENCES suggestions (id) ON DELETE CASCADE,
+			PRIMARY KEY (suggestion_id, genre_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_suggestion_genres_genre_id ON suggestion_genres(genre_id)`,
+	)
+}
+
+func migrateScreenings(tx *sql.Tx, d *Database) error {
+	if err := addColumn(tx, "selected_movies", "event_id", "TEXT"); err != nil {
+		return err
+	}
+	if err := addColumn(tx, "selected_movies", "scheduled_for", "TIMESTAMP"); err != nil {
+		return err
+	}
+	if err := addColumn(tx, "guild_configs", "event_channel_id", "TEXT"); err != nil {
+		return err
+	}
+	return addColumn(tx, "guild_configs", "timezone", "TEXT")
+}
+
+func migrateSeasonArchives(tx *sql.Tx, d *Database) error {
+	return execAll(tx,
+		`CREATE TABLE IF NOT EXISTS selected_movies_archive (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guild_id TEXT NOT NULL,
+			suggestion_id INTEGER NOT NULL,
+			season INTEGER NOT NULL,
+			selected_at TIMESTAMP NOT NULL,
+			scheduled_for TIMESTAMP,
+			archived_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (suggestion_id) REFERENCES suggestions (id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS movie_reviews_archive (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			suggestion_id INTEGER NOT NULL,
+			guild_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			username TEXT NOT NULL,
+			rating REAL NOT NULL,
+			review_text TEXT,
+			reviewed_at TIMESTAMP NOT NULL,
+			season INTEGER NOT NULL,
+			archived_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (suggestion_id) REFERENCES suggestions (id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_selected_movies_archive_guild_id ON selected_movies_archive(guild_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_movie_reviews_archive_guild_id ON movie_reviews_archive(guild_id)`,
+	)
+}
+
+func migrateRoles(tx *sql.Tx, d *Database) error {
+	if err := addColumn(tx, "guild_configs", "host_role_id", "TEXT"); err != nil {
+		return err
+	}
+	return addColumn(tx, "guild_configs", "suggester_role_id", "TEXT")
+}
+
+func migrateSuggestionMetadata(tx *sql.Tx, d *Database) error {
+	columns := []struct{ name, definition string }{
+		{"poster_path", "TEXT"},
+		{"backdrop_path", "TEXT"},
+		{"overview", "TEXT"},
+		{"original_title", "TEXT"},
+		{"original_language", "TEXT"},
+		{"imdb_id", "TEXT"},
+		// NULL indica que a linha ainda precisa ser completada pelo /refreshmetadata
+		{"metadata_updated_at", "TIMESTAMP"},
+	}
+	for _, column := range columns {
+		if err := addColumn(tx, "suggestions", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateMoviePolls(tx *sql.Tx, d *Database) error {
+	return execAll(tx,
+		`CREATE TABLE IF NOT EXISTS movie_polls (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guild_id TEXT NOT NULL,
+			channel_id TEXT NOT NULL,
+			message_id TEXT,
+			created_by TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			closes_at TIMESTAMP NOT NULL,
+			closed_at TIMESTAMP,
+			winner_suggestion_id INTEGER
+		)`,
+		`CREATE TABLE IF NOT EXISTS movie_poll_candidates (
+			poll_id INTEGER NOT NULL,
+			suggestion_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			FOREIGN KEY (poll_id) REFERENCES movie_polls (id) ON DELETE CASCADE,
+			FOREIGN KEY (suggestion_id) REFERENCES suggestions (id) ON DELETE CASCADE,
+			PRIMARY KEY (poll_id, suggestion_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS movie_poll_ballots (
+			poll_id INTEGER NOT NULL,
+			user_id TEXT NOT NULL,
+			rank INTEGER NOT NULL,
+			suggestion_id INTEGER NOT NULL,
+			voted_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (poll_id) REFERENCES movie_polls (id) ON DELETE CASCADE,
+			PRIMARY KEY (poll_id, user_id, rank),
+			UNIQUE (poll_id, user_id, suggestion_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_movie_polls_open ON movie_polls(closed_at)`,
+	)
+}
+
+func migratePickStrategy(tx *sql.Tx, d *Database) error {
+	return addColumn(tx, "guild_configs", "pick_strategy", "TEXT")
+}
+
+func migrateVetoes(tx *sql.Tx, d *Database) error {
+	if err := addColumn(tx, "guild_configs", "vetoes_per_season", "INTEGER"); err != nil {
+		return err
+	}
+	if err := addColumn(tx, "guild_configs", "ban_vetoed", "BOOLEAN"); err != nil {
+		return err
+	}
+	return execAll(tx,
+		`CREATE TABLE IF NOT EXISTS vetoes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guild_id TEXT NOT NULL,
+			suggestion_id INTEGER NOT NULL,
+			user_id TEXT NOT NULL,
+			username TEXT NOT NULL,
+			season INTEGER NOT NULL,
+			banned BOOLEAN NOT NULL DEFAULT 0,
+			vetoed_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (suggestion_id) REFERENCES suggestions (id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_vetoes_guild_user_season ON vetoes(guild_id, user_id, season)`,
+		`CREATE INDEX IF NOT EXISTS idx_vetoes_suggestion_id ON vetoes(suggestion_id)`,
+	)
+}
+
+func migrateSuggestionLimits(tx *sql.Tx, d *Database) error {
+	if err := addColumn(tx, "guild_configs", "max_open_suggestions", "INTEGER"); err != nil {
+		return err
+	}
+	return addColumn(tx, "guild_configs", "suggestion_cooldown_minutes", "INTEGER")
+}
+
+// migrateSuggestionModeration adiciona o status de moderação; sugestões existentes já estavam públicas e ficam aprovadas
+func migrateSuggestionModeration(tx *sql.Tx, d *Database) error {
+	if err := addColumn(tx, "guild_configs", "require_approval", "BOOLEAN"); err != nil {
+		return err
+	}
+	if err := addColumn(tx, "guild_configs", "mod_channel_id", "TEXT"); err != nil {
+		return err
+	}
+	if err := addColumn(tx, "suggestions", "status", "TEXT NOT NULL DEFAULT '"+SuggestionApproved+"'"); err != nil {
+		return err
+	}
+	for _, column := range []string{"moderated_by", "rejection_reason"} {
+		if err := addColumn(tx, "suggestions", column, "TEXT"); err != nil {
+			return err
+		}
+	}
+	if err := addColumn(tx, "suggestions", "moderated_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+	return execAll(tx, `CREATE INDEX IF NOT EXISTS idx_suggestions_guild_status ON suggestions(guild_id, status)`)
+}
+
+// migrateSuggestionMessages guarda onde a sugestão foi postada, para que a mensagem acompanhe o estado do filme
+func migrateSuggestionMessages(tx *sql.Tx, d *Database) error {
+	for _, column := range []string{"channel_id", "message_id"} {
+		if err := addColumn(tx, "suggestions", column, "TEXT"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateDiscussionThreads(tx *sql.Tx, d *Database) error {
+	if err := addColumn(tx, "guild_configs", "discussion_forum_id", "TEXT"); err != nil {
+		return err
+	}
+	return addColumn(tx, "selected_movies", "thread_id", "TEXT")
+}
+
+func migrateBlindRatings(tx *sql.Tx, d *Database) error {
+	if err := addColumn(tx, "selected_movies", "blind", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	for _, column := range []string{"reveal_at", "revealed_at"} {
+		if err := addColumn(tx, "selected_movies", column, "TIMESTAMP"); err != nil {
+			return err
+		}
+	}
+	return addColumn(tx, "selected_movies", "reveal_after", "INTEGER")
+}
+
+func migrateGuildLanguage(tx *sql.Tx, d *Database) error {
+	return addColumn(tx, "guild_configs", "language", "TEXT")
+}
+
+// suggestionsColumns são as colunas de suggestions em ordem, depois de todas as migrações até a 19
+var suggestionsColumns = []string{
+	"id", "guild_id", "movie_name", "user_id", "username", "suggested_at", "tmdb_id", "rating", "genres", "release_year",
+	"runtime", "poster_path", "backdrop_path", "overview", "original_title", "original_language", "imdb_id", "metadata_updated_at",
+	"status", "moderated_by", "rejection_reason", "moderated_at", "channel_id", "message_id", "media_type", "seasons", "episodes",
+}
+
+// migrateMediaTypes permite sugerir séries. A restrição UNIQUE(guild_id, tmdb_id) faz parte da definição de
+// suggestions e o SQLite não a altera, então a tabela é recriada sem ela. A unicidade passa a ser por
+// servidor, tipo e título, já que filmes e séries têm numerações independentes no TMDB, e fica em um índice
+// nomeado que migrações futuras podem trocar sem recriar a tabela de novo.
+func migrateMediaTypes(tx *sql.Tx, d *Database) error {
+	if err := addColumn(tx, "suggestions", "media_type", "TEXT NOT NULL DEFAULT '"+MediaMovie+"'"); err != nil {
+		return err
+	}
+	for _, column := range []string{"seasons", "episodes"} {
+		if err := addColumn(tx, "suggestions", column, "INTEGER"); err != nil {
+			return err
+		}
+	}
+
+	columnList := strings.Join(suggestionsColumns, ", ")
+	return execAll(tx,
+		`CREATE TABLE suggestions_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guild_id TEXT NOT NULL,
+			movie_name TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			username TEXT NOT NULL,
+			suggested_at TIMESTAMP NOT NULL,
+			tmdb_id INTEGER NOT NULL,
+			rating REAL,
+			genres TEXT,
+			release_year TEXT,
+			runtime INTEGER,
+			poster_path TEXT,
+			backdrop_path TEXT,
+			overview TEXT,
+			original_title TEXT,
+			original_language TEXT,
+			imdb_id TEXT,
+			metadata_updated_at TIMESTAMP,
+			status TEXT NOT NULL DEFAULT '`+SuggestionApproved+`',
+			moderated_by TEXT,
+			rejection_reason TEXT,
+			moderated_at TIMESTAMP,
+			channel_id TEXT,
+			message_id TEXT,
+			media_type TEXT NOT NULL DEFAULT '`+MediaMovie+`',
+			seasons INTEGER,
+			episodes INTEGER
+		)`,
+		`INSERT INTO suggestions_new (`+columnList+`) SELECT `+columnList+` FROM suggestions`,
+		// Sem foreign_keys ativado, o DROP não apaga as linhas das tabelas que apontam para suggestions
+		`DROP TABLE suggestions`,
+		`ALTER TABLE suggestions_new RENAME TO suggestions`,
+		`CREATE INDEX idx_suggestions_guild_id ON suggestions(guild_id)`,
+		`CREATE INDEX idx_suggestions_user_id ON suggestions(user_id)`,
+		`CREATE INDEX idx_suggestions_tmdb_id ON suggestions(tmdb_id)`,
+		`CREATE INDEX idx_suggestions_guild_tmdb ON suggestions(guild_id, tmdb_id)`,
+		`CREATE INDEX idx_suggestions_guild_status ON suggestions(guild_id, status)`,
+		`CREATE UNIQUE INDEX idx_suggestions_unique_title ON suggestions(guild_id, media_type, tmdb_id)`,
+	)
+}
+
+// migrateRejectedResuggestions tira as sugestões rejeitadas do índice único, para que o título possa ser sugerido de novo
+func migrateRejectedResuggestions(tx *sql.Tx, d *Database) error {
+	return execAll(tx,
+		`DROP INDEX IF EXISTS idx_suggestions_unique_title`,
+		`CREATE UNIQUE INDEX idx_suggestions_unique_title ON suggestions(guild_id, media_type, tmdb_id) WHERE status != 'rejected'`,
+	)
+}
+
+func migrateSuggestionPostHashes(tx *sql.Tx, d *Database) error {
+	return addColumn(tx, "suggestions", "post_hash", "TEXT")
+}
+
+func migrateSuggestionCooldowns(tx *sql.Tx, d *Database) error {
+	return execAll(tx, `
+		CREATE TABLE IF NOT EXISTS suggestion_cooldowns (
+			guild_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			last_suggested_at DATETIME NOT NULL,
+			PRIMARY KEY (guild_id, user_id)
+		)`)
+}
+
+// migrateReviewSpoilers guarda a resposta do /ratemovie sobre spoilers; as avaliações antigas ficam sem a marca
+func migrateReviewSpoilers(tx *sql.Tx, d *Database) error {
+	for _, table := range []string{"movie_reviews", "movie_reviews_archive"} {
+		if err := addColumn(tx, table, "spoiler", "BOOLEAN"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,243 @@
+package database
+
+import (
+	"bytes"
+	"database/sql"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrationsOnAFreshDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clapper.db")
+	d, err := New(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var version, applied int
+	d.db.QueryRow("SELECT MAX(version), COUNT(*) FROM schema_migrations").Scan(&version, &applied)
+	if version != latestSchemaVersion() || applied != len(migrations) {
+		t.Errorf("expected every migration up to %d to be applied, got version %d with %d applied", latestSchemaVersion(), version, applied)
+	}
+	d.Close()
+
+	// Reabrir o banco não reaplica nada
+	d, err = New(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	d.db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&applied)
+	if applied != len(migrations) {
+		t.Errorf("expected %d migrations after reopening, got %d", len(migrations), applied)
+	}
+}
+
+// newLegacyDB cria um banco no formato da versão single-guild, com duas sugestões, uma seleção e uma avaliação
+func newLegacyDB(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE suggestions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT, movie_name TEXT NOT NULL, user_id TEXT NOT NULL, username TEXT NOT NULL,
+			suggested_at TIMESTAMP NOT NULL, tmdb_id INTEGER NOT NULL UNIQUE, rating REAL, genres TEXT, release_year TEXT);
+		CREATE TABLE selected_movies (id INTEGER PRIMARY KEY AUTOINCREMENT, suggestion_id INTEGER NOT NULL, selected_at TIMESTAMP NOT NULL);
+		CREATE TABLE movie_reviews (
+			id INTEGER PRIMARY KEY AUTOINCREMENT, suggestion_id INTEGER NOT NULL, user_id TEXT NOT NULL, username TEXT NOT NULL,
+			rating REAL NOT NULL, review_text TEXT, reviewed_at TIMESTAMP NOT NULL);
+		INSERT INTO suggestions VALUES (1, 'Alien', '1', 'user1', '2023-01-01 00:00:00', 348, 8.2, 'Horror', '1979');
+		INSERT INTO suggestions VALUES (2, 'Heat', '2', 'user2', '2023-01-02 00:00:00', 949, 7.9, 'Crime', '1995');
+		INSERT INTO selected_movies VALUES (1, 2, '2023-02-01 00:00:00');
+		INSERT INTO movie_reviews VALUES (1, 2, '1', 'user1', 9, 'Great', '2023-02-02 00:00:00');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLegacyDataRecovery(t *testing.T) {
+	d, err := New(newLegacyDB(t), testGuildID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	suggestions, err := d.GetAllSuggestions(testGuildID, "")
+	if err != nil || len(suggestions) != 2 {
+		t.Fatalf("expected both legacy suggestions in %s, got %+v, %v", testGuildID, suggestions, err)
+	}
+
+	var heat *Suggestion
+	for idx := range suggestions {
+		if suggestions[idx].TMDBID == 949 {
+			heat = &suggestions[idx]
+		}
+	}
+	if heat == nil || heat.MediaType != MediaMovie {
+		t.Fatalf("expected Heat to be recovered as a movie, got %+v", suggestions)
+	}
+	if selected, _ := d.IsMovieSelected(testGuildID, heat.ID); !selected {
+		t.Error("expected the legacy selection to point at the recovered suggestion")
+	}
+	if reviews, _ := d.GetMovieReviews(testGuildID, heat.ID); len(reviews) != 1 || reviews[0].Rating != 9 {
+		t.Errorf("expected the legacy review to be recovered, got %+v", reviews)
+	}
+}
+
+func TestLegacyDataRecoveryWaitsForTheGuild(t *testing.T) {
+	path := newLegacyDB(t)
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	// Sem LEGACY_GUILD_ID e sem servidor configurado o bot sobe, mas a recuperação fica pendente
+	d, err := New(path, "")
+	if err != nil {
+		t.Fatalf("expected the bot to start without LEGACY_GUILD_ID, got %v", err)
+	}
+	if count, _ := d.GetAllSuggestionsCount(testGuildID); count != 0 {
+		t.Errorf("nothing should be recovered without a guild, got %d suggestions", count)
+	}
+	if !strings.Contains(logs.String(), "LEGACY_GUILD_ID") {
+		t.Errorf("expected a warning about LEGACY_GUILD_ID, got:\n%s", logs.String())
+	}
+
+	// Enquanto isso Alien é sugerido de novo, e a linha nova prevalece sobre a antiga
+	d.SaveGuildConfig(testGuildID, "100")
+	d.SaveSuggestion(&Suggestion{GuildID: testGuildID, MovieName: "Alien (new)", UserID: "3", Username: "user3", TMDBID: 348})
+	d.Close()
+
+	// Com um único servidor configurado a recuperação roda na inicialização seguinte
+	logs.Reset()
+	d, err = New(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	suggestions, _ := d.GetAllSuggestions(testGuildID, "")
+	if len(suggestions) != 2 {
+		t.Fatalf("expected Heat to be recovered next to the new Alien, got %+v", suggestions)
+	}
+	for _, s := range suggestions {
+		if s.TMDBID == 348 && s.MovieName != "Alien (new)" {
+			t.Errorf("expected the new Alien suggestion to be kept, got %+v", s)
+		}
+	}
+	if !strings.Contains(logs.String(), "1 de 2 linhas de suggestions_old não foram recuperadas") {
+		t.Errorf("expected the skipped legacy row to be reported, got:\n%s", logs.String())
+	}
+
+	var applied int
+	d.db.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = 2").Scan(&applied)
+	if applied != 1 {
+		t.Error("expected the recovery to be recorded once it ran")
+	}
+}
+
+func TestNewerSchemaIsRefused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clapper.db")
+	d, err := New(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.db.Exec("INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, 'from the future', CURRENT_TIMESTAMP)",
+		latestSchemaVersion()+1)
+	d.Close()
+
+	if d, err := New(path, ""); err == nil || !strings.Contains(err.Error(), "newer than this binary supports") {
+		if d != nil {
+			d.Close()
+		}
+		t.Fatalf("expected a newer schema to be refused, got %v", err)
+	}
+}
+
+// openAtVersion abre o banco aplicando apenas as migrações até version, como um binário mais antigo faria
+func openAtVersion(t *testing.T, path string, version int) *Database {
+	t.Helper()
+
+	all := migrations
+	migrations = all[:version]
+	defer func() { migrations = all }()
+
+	d, err := New(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestMediaTypesMigrationKeepsData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clapper.db")
+	d := openAtVersion(t, path, 18)
+	_, err := d.db.Exec(`
+		INSERT INTO suggestions (id, guild_id, movie_name, user_id, username, suggested_at, tmdb_id, rating, genres, release_year,
+		                         runtime, overview, status, rejection_reason, channel_id, message_id)
+		VALUES (7, 'guild1', 'Heat', '1', 'user1', '2024-01-01 00:00:00', 949, 7.9, 'Crime', '1995', 170, 'Heists.', 'approved', NULL, '100', 'msg1'),
+		       (9, 'guild1', 'Alien', '2', 'user2', '2024-01-02 00:00:00', 348, 8.2, 'Horror', '1979', 117, NULL, 'rejected', 'Seen it', NULL, NULL);
+		INSERT INTO suggestion_genres (suggestion_id, genre_id) VALUES (7, 80);
+		INSERT INTO selected_movies (guild_id, suggestion_id, selected_at) VALUES ('guild1', 7, '2024-02-01 00:00:00');
+		INSERT INTO movie_reviews (suggestion_id, guild_id, user_id, username, rating, review_text, reviewed_at)
+		VALUES (7, 'guild1', '2', 'user2', 9, 'Great', '2024-02-02 00:00:00');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Close()
+
+	d, err = New(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	heat, err := d.GetSuggestion(testGuildID, 7)
+	if err != nil || heat == nil {
+		t.Fatalf("expected Heat to keep its ID, got %+v, %v", heat, err)
+	}
+	if heat.MovieName != "Heat" || heat.MediaType != MediaMovie || heat.Runtime != 170 || heat.Overview != "Heists." || heat.MessageID != "msg1" {
+		t.Errorf("unexpected Heat after the migration: %+v", heat)
+	}
+	if alien, _ := d.GetSuggestion(testGuildID, 9); alien == nil || alien.Status != SuggestionRejected || alien.RejectionReason != "Seen it" {
+		t.Errorf("expected the moderation state to survive, got %+v", alien)
+	}
+	if selected, _ := d.IsMovieSelected(testGuildID, 7); !selected {
+		t.Error("expected selected_movies to survive the rebuild")
+	}
+	if reviews, _ := d.GetMovieReviews(testGuildID, 7); len(reviews) != 1 || reviews[0].ReviewText != "Great" {
+		t.Errorf("expected movie_reviews to survive the rebuild, got %+v", reviews)
+	}
+	if movies := pickableIDs(t, d, MovieFilter{GenreID: 80}); len(movies) != 0 {
+		t.Errorf("Heat is selected and should not be pickable, got %v", movies)
+	}
+
+	// Filmes e séries com o mesmo ID convivem; o mesmo filme não entra duas vezes
+	show, err := d.SaveSuggestion(&Suggestion{GuildID: testGuildID, MovieName: "Heat Wave", UserID: "3", Username: "user3", TMDBID: 949,
+		MovieMetadata: MovieMetadata{MediaType: MediaTV, Seasons: 2}})
+	if err != nil {
+		t.Fatalf("expected a show with Heat's ID to be accepted, got %v", err)
+	}
+	if show <= 9 {
+		t.Errorf("expected AUTOINCREMENT to keep counting after the old IDs, got %d", show)
+	}
+	if _, err := d.SaveSuggestion(&Suggestion{GuildID: testGuildID, MovieName: "Heat", UserID: "3", Username: "user3", TMDBID: 949}); err == nil {
+		t.Error("expected a second Heat movie to be refused")
+	}
+
+	var indexes int
+	d.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND tbl_name = 'suggestions' AND name LIKE 'idx_%'").Scan(&indexes)
+	if indexes != 6 {
+		t.Errorf("expected the 6 suggestion indexes to be recreated, got %d", indexes)
+	}
+}
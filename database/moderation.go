@@ -0,0 +1,47 @@
+package database
+
+import "time"
+
+// Status de moderação das sugestões; só as aprovadas aparecem no canal público e entram no sorteio
+const (
+	SuggestionPending  = "pending"
+	SuggestionApproved = "approved"
+	SuggestionRejected = "rejected"
+)
+
+// SaveModerationSettings liga ou desliga a fila de aprovação e grava o canal onde os moderadores revisam as sugestões
+func (d *Database) SaveModerationSettings(guildID string, requireApproval bool, modChannelID string) error {
+	_, err := d.db.Exec("UPDATE guild_configs SET require_approval = ?, mod_channel_id = ? WHERE guild_id = ?",
+		requireApproval, modChannelID, guildID)
+	return err
+}
+
+// SetSuggestionStatus muda o status da sugestão somente se ela ainda estiver em from. Devolve false quando
+// outro moderador já decidiu antes, o que impede que dois cliques publiquem a mesma sugestão duas vezes.
+func (d *Database) SetSuggestionStatus(guildID string, suggestionID int, from, to, moderatorID, reason string) (bool, error) {
+	var moderatedBy, moderatedAt, rejectionReason interface{}
+	if to != SuggestionPending {
+		moderatedBy = moderatorID
+		moderatedAt = time.Now()
+	}
+	if reason != "" {
+		rejectionReason = reason
+	}
+
+	result, err := d.db.Exec(`
+		UPDATE suggestions SET status = ?, moderated_by = ?, moderated_at = ?, rejection_reason = ?
+		WHERE guild_id = ? AND id = ? AND status = ?`,
+		to, moderatedBy, moderatedAt, rejectionReason, guildID, suggestionID, from)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetPendingSuggestionsCount conta as sugestões que aguardam um moderador
+func (d *Database) GetPendingSuggestionsCount(guildID string) (int, error) {
+	var count int
+	err := d.db.QueryRow("SELECT COUNT(*) FROM suggestions WHERE guild_id = ? AND status = ?", guildID, SuggestionPending).Scan(&count)
+	return count, err
+}
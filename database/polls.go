@@ -0,0 +1,238 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// MoviePoll é uma votação por ranking entre filmes sorteados; ClosedAt nulo indica votação aberta
+type MoviePoll struct {
+	ID         int
+	GuildID    string
+	ChannelID  string
+	MessageID  string
+	CreatedBy  string
+	CreatedAt  time.Time
+	ClosesAt   time.Time
+	ClosedAt   *time.Time
+	WinnerID   int
+	Candidates []MovieResult
+}
+
+func (p *MoviePoll) IsOpen() bool {
+	return p.ClosedAt == nil
+}
+
+// CreatePoll grava a votação e seus candidatos na ordem em que foram sorteados
+func (d *Database) CreatePoll(poll *MoviePoll) (int64, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO movie_polls (guild_id, channel_id, message_id, created_by, created_at, closes_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		poll.GuildID, poll.ChannelID, poll.MessageID, poll.CreatedBy, time.Now(), poll.ClosesAt)
+	if err != nil {
+		return 0, err
+	}
+	pollID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for position, candidate := range poll.Candidates {
+		_, err := tx.Exec("INSERT INTO movie_poll_candidates (poll_id, suggestion_id, position) VALUES (?, ?, ?)",
+			pollID, candidate.ID, position)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return pollID, tx.Commit()
+}
+
+func (d *Database) SetPollMessage(pollID int, messageID string) error {
+	_, err := d.db.Exec("UPDATE movie_polls SET message_id = ? WHERE id = ?", messageID, pollID)
+	return err
+}
+
+const pollColumns = `id, guild_id, channel_id, COALESCE(message_id, ''), created_by, created_at, closes_at, closed_at, COALESCE(winner_suggestion_id, 0)`
+
+func scanPoll(row rowScanner) (*MoviePoll, error) {
+	var poll MoviePoll
+	var closedAt sql.NullTime
+	err := row.Scan(&poll.ID, &poll.GuildID, &poll.ChannelID, &poll.MessageID, &poll.CreatedBy,
+		&poll.CreatedAt, &poll.ClosesAt, &closedAt, &poll.WinnerID)
+	if err != nil {
+		return nil, err
+	}
+	if closedAt.Valid {
+		poll.ClosedAt = &closedAt.Time
+	}
+	return &poll, nil
+}
+
+// pollCandidates carrega os candidatos ainda existentes; sugestões removidas somem da votação
+func (d *Database) pollCandidates(pollID int) ([]MovieResult, error) {
+	rows, err := d.db.Query(`
+		SELECT `+movieResultColumns+`
+		FROM movie_poll_candidates pc
+		JOIN suggestions s ON s.id = pc.suggestion_id
+		WHERE pc.poll_id = ?
+		ORDER BY pc.position`, pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []MovieResult
+	for rows.Next() {
+		m, err := scanMovieResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, *m)
+	}
+	return candidates, rows.Err()
+}
+
+func (d *Database) GetPoll(guildID string, pollID int) (*MoviePoll, error) {
+	poll, err := scanPoll(d.db.QueryRow("SELECT "+pollColumns+" FROM movie_polls WHERE guild_id = ? AND id = ?", guildID, pollID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	poll.Candidates, err = d.pollCandidates(poll.ID)
+	if err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+// GetOpenPolls devolve as votações abertas de todos os servidores, usadas para retomar os timers na inicialização
+func (d *Database) GetOpenPolls() ([]MoviePoll, error) {
+	rows, err := d.db.Query("SELECT " + pollColumns + " FROM movie_polls WHERE closed_at IS NULL ORDER BY closes_at")
+	if err != nil {
+		return nil, err
+	}
+
+	var polls []MoviePoll
+	for rows.Next() {
+		poll, err := scanPoll(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		polls = append(polls, *poll)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for idx := range polls {
+		polls[idx].Candidates, err = d.pollCandidates(polls[idx].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return polls, nil
+}
+
+// SaveBallotChoice coloca o filme na posição rank da cédula do usuário. Um filme ocupa uma única
+// posição, então escolhê-lo de novo em outra posição o remove da anterior.
+func (d *Database) SaveBallotChoice(pollID int, userID string, rank, suggestionID int) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM movie_poll_ballots WHERE poll_id = ? AND user_id = ? AND (rank = ? OR suggestion_id = ?)",
+		pollID, userID, rank, suggestionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO movie_poll_ballots (poll_id, user_id, rank, suggestion_id, voted_at)
+		VALUES (?, ?, ?, ?, ?)`, pollID, userID, rank, suggestionID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (d *Database) ClearBallot(pollID int, userID string) error {
+	_, err := d.db.Exec("DELETE FROM movie_poll_ballots WHERE poll_id = ? AND user_id = ?", pollID, userID)
+	return err
+}
+
+// GetBallot devolve as escolhas do usuário em ordem de preferência
+func (d *Database) GetBallot(pollID int, userID string) ([]int, error) {
+	rows, err := d.db.Query("SELECT suggestion_id FROM movie_poll_ballots WHERE poll_id = ? AND user_id = ? ORDER BY rank",
+		pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ballot []int
+	for rows.Next() {
+		var suggestionID int
+		if err := rows.Scan(&suggestionID); err != nil {
+			return nil, err
+		}
+		ballot = append(ballot, suggestionID)
+	}
+	return ballot, rows.Err()
+}
+
+// GetPollBallots devolve todas as cédulas da votação, cada uma em ordem de preferência
+func (d *Database) GetPollBallots(pollID int) ([][]int, error) {
+	rows, err := d.db.Query("SELECT user_id, suggestion_id FROM movie_poll_ballots WHERE poll_id = ? ORDER BY user_id, rank", pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ballots [][]int
+	lastUser := ""
+	for rows.Next() {
+		var userID string
+		var suggestionID int
+		if err := rows.Scan(&userID, &suggestionID); err != nil {
+			return nil, err
+		}
+		if len(ballots) == 0 || userID != lastUser {
+			ballots = append(ballots, nil)
+			lastUser = userID
+		}
+		ballots[len(ballots)-1] = append(ballots[len(ballots)-1], suggestionID)
+	}
+	return ballots, rows.Err()
+}
+
+// ClosePoll encerra a votação e registra o vencedor (0 quando ninguém votou). Devolve false se ela
+// já estava encerrada, o que impede que o timer e um encerramento manual apurem a mesma votação.
+func (d *Database) ClosePoll(pollID, winnerID int) (bool, error) {
+	var winner interface{}
+	if winnerID > 0 {
+		winner = winnerID
+	}
+
+	result, err := d.db.Exec("UPDATE movie_polls SET closed_at = ?, winner_suggestion_id = ? WHERE id = ? AND closed_at IS NULL",
+		time.Now(), winner, pollID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
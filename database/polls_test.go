@@ -0,0 +1,76 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPollStorage(t *testing.T) {
+	d := newTestDB(t)
+	alien := suggest(t, d, "1", 348, "1979")
+	heat := suggest(t, d, "1", 949, "1995")
+	parasite := suggest(t, d, "2", 496243, "2019")
+
+	closesAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	id, err := d.CreatePoll(&MoviePoll{
+		GuildID:    testGuildID,
+		ChannelID:  "100",
+		CreatedBy:  "1",
+		ClosesAt:   closesAt,
+		Candidates: []MovieResult{{ID: parasite}, {ID: alien}, {ID: heat}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pollID := int(id)
+	d.SetPollMessage(pollID, "msg1")
+
+	poll, err := d.GetPoll(testGuildID, pollID)
+	if err != nil || poll == nil {
+		t.Fatalf("expected the poll, got %v, %v", poll, err)
+	}
+	var candidates []int
+	for _, candidate := range poll.Candidates {
+		candidates = append(candidates, candidate.ID)
+	}
+	if !reflect.DeepEqual(candidates, []int{parasite, alien, heat}) || poll.MessageID != "msg1" || !poll.IsOpen() || !poll.ClosesAt.Equal(closesAt) {
+		t.Errorf("unexpected poll %+v with candidates %v", poll, candidates)
+	}
+	if other, _ := d.GetPoll("guild2", pollID); other != nil {
+		t.Errorf("polls from another server should not be visible, got %+v", other)
+	}
+
+	// Escolher o mesmo filme em outra posição o tira da anterior
+	d.SaveBallotChoice(pollID, "1", 1, alien)
+	d.SaveBallotChoice(pollID, "1", 2, heat)
+	d.SaveBallotChoice(pollID, "1", 3, alien)
+	if ballot, _ := d.GetBallot(pollID, "1"); !reflect.DeepEqual(ballot, []int{heat, alien}) {
+		t.Errorf("unexpected ballot %v", ballot)
+	}
+
+	d.SaveBallotChoice(pollID, "2", 1, parasite)
+	d.SaveBallotChoice(pollID, "3", 1, heat)
+	d.ClearBallot(pollID, "3")
+	if ballots, _ := d.GetPollBallots(pollID); !reflect.DeepEqual(ballots, [][]int{{heat, alien}, {parasite}}) {
+		t.Errorf("unexpected ballots %v", ballots)
+	}
+
+	if open, _ := d.GetOpenPolls(); len(open) != 1 || open[0].ID != pollID || len(open[0].Candidates) != 3 {
+		t.Errorf("expected the open poll to be resumable, got %+v", open)
+	}
+
+	// Só o primeiro encerramento apura a votação
+	if closed, err := d.ClosePoll(pollID, heat); !closed || err != nil {
+		t.Fatalf("expected the poll to close, got %v, %v", closed, err)
+	}
+	if closed, _ := d.ClosePoll(pollID, alien); closed {
+		t.Error("a closed poll should not close again")
+	}
+	if poll, _ := d.GetPoll(testGuildID, pollID); poll.IsOpen() || poll.WinnerID != heat {
+		t.Errorf("expected heat to win, got %+v", poll)
+	}
+	if open, _ := d.GetOpenPolls(); len(open) != 0 {
+		t.Errorf("expected no open polls, got %+v", open)
+	}
+}
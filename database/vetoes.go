@@ -0,0 +1,77 @@
+package database
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoVetoesLeft indica que o membro já gastou todos os vetos da temporada
+var ErrNoVetoesLeft = errors.New("no vetoes left this season")
+
+// ErrNoReplacement indica que nenhum outro filme do sorteio poderia substituir o vetado
+var ErrNoReplacement = errors.New("no other movie left to pick")
+
+// SaveVeto gasta um veto do membro na temporada atual e devolve quantos ainda restam. O saldo e a existência
+// de outro filme no sorteio com o filtro de quem sorteou são conferidos na mesma transação, então cliques
+// simultâneos não ultrapassam o limite e nenhum veto é gasto sem um filme para ocupar o lugar.
+func (d *Database) SaveVeto(guildID string, suggestionID int, userID, username string, banned bool, allowance int, filter MovieFilter) (int, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var season int
+	err = tx.QueryRow("SELECT COALESCE(MAX(season), 0) + 1 FROM selected_movies_archive WHERE guild_id = ?", guildID).Scan(&season)
+	if err != nil {
+		return 0, err
+	}
+
+	var used int
+	err = tx.QueryRow("SELECT COUNT(*) FROM vetoes WHERE guild_id = ? AND user_id = ? AND season = ?", guildID, userID, season).Scan(&used)
+	if err != nil {
+		return 0, err
+	}
+	if used >= allowance {
+		return 0, ErrNoVetoesLeft
+	}
+
+	filter.ExcludeSuggestionID = suggestionID
+	filterSQL, filterArgs := filter.whereClause()
+	var replacements int
+	err = tx.QueryRow(`
+		SELECT COUNT(*)
+		FROM suggestions s
+		LEFT JOIN selected_movies sm ON s.id = sm.suggestion_id AND s.guild_id = sm.guild_id
+		WHERE s.guild_id = ? AND sm.id IS NULL`+pickableSQL+filterSQL,
+		append([]interface{}{guildID}, filterArgs...)...).Scan(&replacements)
+	if err != nil {
+		return 0, err
+	}
+	if replacements == 0 {
+		return 0, ErrNoReplacement
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO vetoes (guild_id, suggestion_id, user_id, username, season, banned, vetoed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, guildID, suggestionID, userID, username, season, banned, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return allowance - used - 1, nil
+}
+
+// GetUserVetoCount devolve quantos vetos o membro já gastou na temporada atual
+func (d *Database) GetUserVetoCount(guildID, userID string) (int, error) {
+	var used int
+	err := d.db.QueryRow(`
+		SELECT COUNT(*) FROM vetoes
+		WHERE guild_id = ? AND user_id = ?
+		  AND season = (SELECT COALESCE(MAX(season), 0) + 1 FROM selected_movies_archive WHERE guild_id = ?)`,
+		guildID, userID, guildID).Scan(&used)
+	return used, err
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSaveVeto(t *testing.T) {
+	d := newTestDB(t)
+	alien := suggest(t, d, "1", 348, "1979")
+	heat := suggest(t, d, "1", 949, "1995")
+
+	left, err := d.SaveVeto(testGuildID, alien, "2", "user2", false, 2, MovieFilter{})
+	if err != nil || left != 1 {
+		t.Fatalf("expected 1 veto left, got %d, %v", left, err)
+	}
+	if left, err = d.SaveVeto(testGuildID, heat, "2", "user2", false, 2, MovieFilter{}); err != nil || left != 0 {
+		t.Fatalf("expected no vetoes left, got %d, %v", left, err)
+	}
+	if _, err := d.SaveVeto(testGuildID, heat, "2", "user2", false, 2, MovieFilter{}); !errors.Is(err, ErrNoVetoesLeft) {
+		t.Fatalf("expected ErrNoVetoesLeft, got %v", err)
+	}
+	if used, _ := d.GetUserVetoCount(testGuildID, "2"); used != 2 {
+		t.Errorf("a refused veto should not be recorded, got %d used", used)
+	}
+
+	// Cada membro tem o próprio saldo, que volta na temporada seguinte
+	if left, err := d.SaveVeto(testGuildID, alien, "3", "user3", false, 2, MovieFilter{}); err != nil || left != 1 {
+		t.Errorf("expected another member to have their own vetoes, got %d, %v", left, err)
+	}
+	// A temporada só avança quando há seleções para arquivar
+	d.MarkMovieSelected(testGuildID, alien)
+	if _, _, err := d.ResetSelections(testGuildID); err != nil {
+		t.Fatal(err)
+	}
+	if used, _ := d.GetUserVetoCount(testGuildID, "2"); used != 0 {
+		t.Errorf("expected the vetoes to reset with the season, got %d used", used)
+	}
+}
+
+func TestSaveVetoNeedsAReplacement(t *testing.T) {
+	d := newTestDB(t)
+	alien := suggest(t, d, "1", 348, "1979")
+	suggest(t, d, "1", 949, "1995")
+
+	// Só o Alien passa pelo filtro do sorteio, então não há quem o substitua
+	if _, err := d.SaveVeto(testGuildID, alien, "2", "user2", false, 2, MovieFilter{MaxYear: 1980}); !errors.Is(err, ErrNoReplacement) {
+		t.Fatalf("expected ErrNoReplacement, got %v", err)
+	}
+	if used, _ := d.GetUserVetoCount(testGuildID, "2"); used != 0 {
+		t.Errorf("a veto without a replacement should not be spent, got %d used", used)
+	}
+
+	if left, err := d.SaveVeto(testGuildID, alien, "2", "user2", false, 2, MovieFilter{}); err != nil || left != 1 {
+		t.Errorf("expected the veto to be spent without the filter, got %d, %v", left, err)
+	}
+}
@@ -0,0 +1,47 @@
+package links
+
+import (
+	"clapper/tmdb"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// IMDbResolver traduz links do IMDb (imdb.com/title/tt0133093, m.imdb.com e o ID tt0133093 sozinho)
+// pelo endpoint /find do TMDB. O mesmo ID do IMDb pode ser de um filme ou de uma série.
+type IMDbResolver struct {
+	finder MovieFinder
+	link   *regexp.Regexp
+	bare   *regexp.Regexp
+}
+
+func NewIMDbResolver(finder MovieFinder) *IMDbResolver {
+	return &IMDbResolver{
+		finder: finder,
+		link:   regexp.MustCompile(`(?i)imdb\.com/(?:[a-z]{2}/)?title/(tt\d{7,})`),
+		bare:   regexp.MustCompile(`(?i)^(tt\d{7,})$`),
+	}
+}
+
+func (r *IMDbResolver) Resolve(ctx context.Context, input string) (Link, bool, error) {
+	input = strings.TrimSpace(input)
+
+	matches := r.link.FindStringSubmatch(input)
+	if matches == nil {
+		matches = r.bare.FindStringSubmatch(input)
+	}
+	if matches == nil {
+		return Link{}, false, nil
+	}
+
+	// O idioma não importa aqui: só o ID do título é usado
+	movie, err := r.finder.FindByIMDbID(ctx, strings.ToLower(matches[1]), "")
+	if errors.Is(err, tmdb.ErrNotFound) {
+		return Link{}, true, ErrNotFound
+	}
+	if err != nil {
+		return Link{}, true, err
+	}
+	return Link{MediaType: movie.MediaType, TMDBID: movie.ID}, true, nil
+}
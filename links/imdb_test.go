@@ -0,0 +1,37 @@
+package links
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestIMDbResolver(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Link
+		ok    bool
+		err   error
+	}{
+		{input: "https://www.imdb.com/title/tt0133093/", want: movie(603), ok: true},
+		{input: "https://m.imdb.com/title/tt0133093/?ref_=nv_sr_srsg_0", want: movie(603), ok: true},
+		{input: "imdb.com/pt/title/tt0133093/reviews", want: movie(603), ok: true},
+		{input: "TT0133093", want: movie(603), ok: true},
+		// O ID é de uma série: o /find só traz resultados em tv_results
+		{input: "https://www.imdb.com/title/tt0903747/", want: tv(1396), ok: true},
+		// ID sem resposta gravada: o TMDB devolve 404
+		{input: "https://www.imdb.com/title/tt9999999/", ok: true, err: ErrNotFound},
+		{input: "https://www.imdb.com/name/nm0000206/", ok: false},
+		{input: "tt123", ok: false},
+	}
+
+	resolver := NewIMDbResolver(newFixtureTMDB(t))
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			link, ok, err := resolver.Resolve(context.Background(), tt.input)
+			if link != tt.want || ok != tt.ok || !errors.Is(err, tt.err) {
+				t.Errorf("Resolve(%q) = %+v, %v, %v; want %+v, %v, %v", tt.input, link, ok, err, tt.want, tt.ok, tt.err)
+			}
+		})
+	}
+}
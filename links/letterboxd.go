@@ -0,0 +1,165 @@
+package links
+
+import (
+	"clapper/tmdb"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LetterboxdBaseURL e LetterboxdShortURL são os endereços dos filmes e dos links curtos do Letterboxd
+const (
+	LetterboxdBaseURL  = "https://letterboxd.com"
+	LetterboxdShortURL = "https://boxd.it"
+)
+
+// maxLetterboxdPage limita quanto da página do filme é lido; a referência ao TMDB fica no começo do HTML
+const maxLetterboxdPage = 2 << 20
+
+// LetterboxdResolver traduz links do Letterboxd (letterboxd.com/film/<slug>, as resenhas em
+// letterboxd.com/<usuário>/film/<slug> e os links curtos boxd.it). A página do filme traz o ID do TMDB,
+// inclusive das minisséries que o Letterboxd cataloga; se ela não puder ser lida, o slug e o ano nele são
+// buscados entre os filmes do TMDB.
+type LetterboxdResolver struct {
+	finder     MovieFinder
+	httpClient *http.Client
+	film       *regexp.Regexp
+	short      *regexp.Regexp
+}
+
+func NewLetterboxdResolver(finder MovieFinder, httpClient *http.Client) *LetterboxdResolver {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &LetterboxdResolver{
+		finder:     finder,
+		httpClient: httpClient,
+		film:       regexp.MustCompile(`(?i)letterboxd\.com/(?:[\w.-]+/)?film/([a-z0-9-]+)`),
+		short:      regexp.MustCompile(`(?i)boxd\.it/([a-z0-9]+)`),
+	}
+}
+
+func (r *LetterboxdResolver) Resolve(ctx context.Context, input string) (Link, bool, error) {
+	var pageURL, slug string
+	if matches := r.film.FindStringSubmatch(input); matches != nil {
+		slug = strings.ToLower(matches[1])
+		pageURL = fmt.Sprintf("%s/film/%s/", LetterboxdBaseURL, slug)
+	} else if matches := r.short.FindStringSubmatch(input); matches != nil {
+		pageURL = fmt.Sprintf("%s/%s", LetterboxdShortURL, matches[1])
+	} else {
+		return Link{}, false, nil
+	}
+
+	link, finalSlug, err := r.fetchTMDBID(ctx, pageURL)
+	if err == nil && link.TMDBID > 0 {
+		return link, true, nil
+	}
+	if err != nil {
+		log.Printf("Erro ao abrir a página do Letterboxd %s: %v", pageURL, err)
+	}
+
+	// Links curtos só revelam o slug depois do redirecionamento
+	if slug == "" {
+		slug = finalSlug
+	}
+	if slug == "" {
+		return Link{}, true, ErrNotFound
+	}
+
+	id, err := r.searchSlug(ctx, slug)
+	if err != nil {
+		return Link{}, true, err
+	}
+	return Link{MediaType: tmdb.MediaMovie, TMDBID: id}, true, nil
+}
+
+// fetchTMDBID lê o ID do TMDB na página do filme. Também devolve o slug da página final, para os links curtos.
+// Um ID zero sem erro indica que a página não referencia nenhum título do TMDB.
+func (r *LetterboxdResolver) fetchTMDBID(ctx context.Context, pageURL string) (Link, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return Link{}, "", err
+	}
+	req.Header.Set("User-Agent", "clapper (Discord movie night bot)")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return Link{}, "", err
+	}
+	defer resp.Body.Close()
+
+	var slug string
+	if matches := r.film.FindStringSubmatch(resp.Request.URL.Host + resp.Request.URL.Path); matches != nil {
+		slug = strings.ToLower(matches[1])
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return Link{}, slug, fmt.Errorf("letterboxd returned status %d", resp.StatusCode)
+	}
+
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxLetterboxdPage))
+	if err != nil {
+		return Link{}, slug, err
+	}
+	return parseLetterboxdTMDBID(string(page)), slug, nil
+}
+
+// parseLetterboxdTMDBID procura o tipo e o ID nos atributos data-tmdb-* do <body> e, se faltarem, no link
+// para o TMDB. Tipos que o bot não sugere devolvem um Link vazio.
+func parseLetterboxdTMDBID(page string) Link {
+	if matches := regexp.MustCompile(`data-tmdb-id="(\d+)"`).FindStringSubmatch(page); matches != nil {
+		mediaType := tmdb.MediaMovie
+		if typeMatches := regexp.MustCompile(`data-tmdb-type="(\w+)"`).FindStringSubmatch(page); typeMatches != nil {
+			mediaType = typeMatches[1]
+		}
+		return letterboxdLink(mediaType, matches[1])
+	}
+	if matches := regexp.MustCompile(`themoviedb\.org/(movie|tv)/(\d+)`).FindStringSubmatch(page); matches != nil {
+		return letterboxdLink(matches[1], matches[2])
+	}
+	return Link{}
+}
+
+func letterboxdLink(mediaType, rawID string) Link {
+	if mediaType != tmdb.MediaMovie && mediaType != tmdb.MediaTV {
+		return Link{}
+	}
+	id, _ := strconv.Atoi(rawID)
+	return Link{MediaType: mediaType, TMDBID: id}
+}
+
+// searchSlug busca o título do slug no TMDB. O Letterboxd desambigua filmes homônimos com o ano no fim do
+// slug (dune-2021); como o ano também pode fazer parte do título (blade-runner-2049), sem um resultado
+// daquele ano o slug inteiro é buscado.
+func (r *LetterboxdResolver) searchSlug(ctx context.Context, slug string) (int, error) {
+	title := strings.ReplaceAll(slug, "-", " ")
+
+	if idx := strings.LastIndex(slug, "-"); idx > 0 {
+		if year, err := strconv.Atoi(slug[idx+1:]); err == nil && year >= 1870 && year <= 3000 {
+			movies, err := r.finder.SearchMovies(ctx, strings.ReplaceAll(slug[:idx], "-", " "), "")
+			if err != nil {
+				return 0, err
+			}
+			for _, movie := range movies {
+				if strings.HasPrefix(movie.ReleaseDate, slug[idx+1:]) {
+					return movie.ID, nil
+				}
+			}
+		}
+	}
+
+	movies, err := r.finder.SearchMovies(ctx, title, "")
+	if err != nil {
+		return 0, err
+	}
+	if len(movies) == 0 {
+		return 0, ErrNotFound
+	}
+	return movies[0].ID, nil
+}
@@ -0,0 +1,75 @@
+package links
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestLetterboxdResolver(t *testing.T) {
+	transport := fixtureTransport{
+		"https://letterboxd.com/film/the-matrix/":    {status: http.StatusOK, file: "letterboxd_the_matrix.html"},
+		"https://letterboxd.com/film/parasite-2019/": {status: http.StatusOK, file: "letterboxd_legacy_layout.html"},
+		"https://letterboxd.com/film/chernobyl/":     {status: http.StatusOK, file: "letterboxd_miniseries.html"},
+		"https://boxd.it/29qA":                       {status: http.StatusMovedPermanently, location: "https://letterboxd.com/film/the-matrix/"},
+		"https://boxd.it/2b0e":                       {status: http.StatusMovedPermanently, location: "https://letterboxd.com/film/dune-2021/"},
+		// Páginas bloqueadas caem na busca pelo slug
+		"https://letterboxd.com/film/dune-2021/":         {status: http.StatusForbidden},
+		"https://letterboxd.com/film/dune-1984/":         {status: http.StatusForbidden},
+		"https://letterboxd.com/film/blade-runner-2049/": {status: http.StatusServiceUnavailable},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  Link
+		ok    bool
+		err   error
+	}{
+		{name: "film page", input: "https://letterboxd.com/film/the-matrix/", want: movie(603), ok: true},
+		{name: "review page", input: "https://letterboxd.com/someone/film/the-matrix/", want: movie(603), ok: true},
+		{name: "link to the TMDB page only", input: "letterboxd.com/film/parasite-2019", want: movie(496243), ok: true},
+		{name: "short link", input: "https://boxd.it/29qA", want: movie(603), ok: true},
+		{name: "slug with the year", input: "https://letterboxd.com/film/dune-2021/", want: movie(438631), ok: true},
+		{name: "slug with an older year", input: "https://letterboxd.com/film/dune-1984/", want: movie(841), ok: true},
+		{name: "short link to a blocked page", input: "https://boxd.it/2b0e", want: movie(438631), ok: true},
+		{name: "year that is part of the title", input: "https://letterboxd.com/film/blade-runner-2049/", want: movie(335984), ok: true},
+		{name: "TV miniseries", input: "https://letterboxd.com/film/chernobyl/", want: tv(87108), ok: true},
+		{name: "unknown short link", input: "https://boxd.it/zzzz", ok: true, err: ErrNotFound},
+		{name: "profile", input: "https://letterboxd.com/someone/", ok: false},
+		{name: "plain title", input: "Dune", ok: false},
+	}
+
+	resolver := NewLetterboxdResolver(newFixtureTMDB(t), &http.Client{Transport: transport})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, ok, err := resolver.Resolve(context.Background(), tt.input)
+			if link != tt.want || ok != tt.ok || !errors.Is(err, tt.err) {
+				t.Errorf("Resolve(%q) = %+v, %v, %v; want %+v, %v, %v", tt.input, link, ok, err, tt.want, tt.ok, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseLetterboxdTMDBID(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    Link
+	}{
+		{fixture: "letterboxd_the_matrix.html", want: movie(603)},
+		{fixture: "letterboxd_legacy_layout.html", want: movie(496243)},
+		{fixture: "letterboxd_miniseries.html", want: tv(87108)},
+	}
+
+	for _, tt := range tests {
+		page, err := os.ReadFile("testdata/" + tt.fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := parseLetterboxdTMDBID(string(page)); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.fixture, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Package links traduz links de sites de filmes colados no /suggestion para o ID do filme ou da série no TMDB
+package links
+
+import (
+	"clapper/tmdb"
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrNotFound indica que o link foi reconhecido, mas não leva a nenhum filme ou série do TMDB
+var ErrNotFound = errors.New("links: movie not found")
+
+// Link é o título do TMDB para onde um link aponta. MediaType é tmdb.MediaMovie ou tmdb.MediaTV.
+type Link struct {
+	MediaType string
+	TMDBID    int
+}
+
+// Resolver reconhece os links de um site e os traduz para o ID do título no TMDB
+type Resolver interface {
+	// Resolve devolve ok = false quando input não é um link deste resolvedor; nesse caso err é sempre nil
+	Resolve(ctx context.Context, input string) (link Link, ok bool, err error)
+}
+
+// MovieFinder busca filmes no TMDB; *tmdb.Client o implementa
+type MovieFinder interface {
+	FindByIMDbID(ctx context.Context, imdbID, language string) (*tmdb.Movie, error)
+	SearchMovies(ctx context.Context, movieName, language string) ([]tmdb.Movie, error)
+}
+
+// Chain tenta os resolvedores em ordem e usa o primeiro que reconhece o link
+type Chain []Resolver
+
+func (c Chain) Resolve(ctx context.Context, input string) (Link, bool, error) {
+	for _, resolver := range c {
+		if link, ok, err := resolver.Resolve(ctx, input); ok {
+			return link, true, err
+		}
+	}
+	return Link{}, false, nil
+}
+
+// NewChain monta os resolvedores padrão: TMDB, IMDb e Letterboxd. httpClient é usado para abrir as páginas
+// do Letterboxd; nil usa um cliente com timeout padrão.
+func NewChain(finder MovieFinder, httpClient *http.Client) Chain {
+	return Chain{
+		NewTMDBResolver(),
+		NewIMDbResolver(finder),
+		NewLetterboxdResolver(finder, httpClient),
+	}
+}
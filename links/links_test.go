@@ -0,0 +1,115 @@
+package links
+
+import (
+	"clapper/tmdb"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFixtureTMDB aponta um cliente do TMDB para as respostas gravadas em testdata: /find/<id> lê
+// tmdb_find_<id>.json e /search/movie lê tmdb_search_<consulta>.json, com os espaços trocados por _
+func newFixtureTMDB(t *testing.T) *tmdb.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var fixture string
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/find/"):
+			fixture = "tmdb_find_" + strings.TrimPrefix(r.URL.Path, "/find/") + ".json"
+		case r.URL.Path == "/search/movie":
+			fixture = "tmdb_search_" + strings.ReplaceAll(r.URL.Query().Get("query"), " ", "_") + ".json"
+		}
+
+		body, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			if r.URL.Path == "/search/movie" {
+				w.Write([]byte(`{"page":1,"results":[]}`))
+				return
+			}
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	client := tmdb.NewClient("test-key")
+	client.SetBaseURL(server.URL)
+	client.SetRetries(0, 0)
+	return client
+}
+
+func movie(id int) Link { return Link{MediaType: tmdb.MediaMovie, TMDBID: id} }
+
+func tv(id int) Link { return Link{MediaType: tmdb.MediaTV, TMDBID: id} }
+
+// fixtureResponse é uma resposta gravada de um site: o corpo vem de testdata/file, ou um redirecionamento
+type fixtureResponse struct {
+	status   int
+	file     string
+	location string
+}
+
+// fixtureTransport responde às requisições HTTP com as respostas gravadas, pela URL completa;
+// URLs desconhecidas recebem 404
+type fixtureTransport map[string]fixtureResponse
+
+func (f fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fixture, ok := f[req.URL.String()]
+	if !ok {
+		fixture = fixtureResponse{status: http.StatusNotFound}
+	}
+
+	rec := httptest.NewRecorder()
+	if fixture.location != "" {
+		rec.Header().Set("Location", fixture.location)
+	}
+	rec.WriteHeader(fixture.status)
+	if fixture.file != "" {
+		body, err := os.ReadFile(filepath.Join("testdata", fixture.file))
+		if err != nil {
+			return nil, err
+		}
+		rec.Write(body)
+	}
+
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+func TestChain(t *testing.T) {
+	client := newFixtureTMDB(t)
+	chain := NewChain(client, &http.Client{Transport: fixtureTransport{
+		"https://letterboxd.com/film/the-matrix/": {status: http.StatusOK, file: "letterboxd_the_matrix.html"},
+	}})
+
+	tests := []struct {
+		input string
+		want  Link
+		ok    bool
+		err   error
+	}{
+		{input: "https://www.themoviedb.org/movie/603-the-matrix", want: movie(603), ok: true},
+		{input: "https://www.imdb.com/title/tt0133093/", want: movie(603), ok: true},
+		{input: "https://letterboxd.com/film/the-matrix/", want: movie(603), ok: true},
+		{input: "https://www.themoviedb.org/tv/1396-breaking-bad", want: tv(1396), ok: true},
+		{input: "https://www.imdb.com/title/tt0903747/", want: tv(1396), ok: true},
+		{input: "The Matrix", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			link, ok, err := chain.Resolve(context.Background(), tt.input)
+			if link != tt.want || ok != tt.ok || !errors.Is(err, tt.err) {
+				t.Errorf("Resolve(%q) = %+v, %v, %v; want %+v, %v, %v", tt.input, link, ok, err, tt.want, tt.ok, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package links
+
+import (
+	"clapper/tmdb"
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TMDBResolver lê o ID direto dos links do próprio TMDB: themoviedb.org/movie/603-the-matrix,
+// themoviedb.org/tv/1396-breaking-bad, o domínio curto tmdb.org/movie/603 e a forma tmdb:603 (sempre um filme)
+type TMDBResolver struct {
+	link      *regexp.Regexp
+	shorthand *regexp.Regexp
+}
+
+func NewTMDBResolver() *TMDBResolver {
+	return &TMDBResolver{
+		link:      regexp.MustCompile(`(?i)(?:themoviedb|tmdb)\.org/(movie|tv)/(\d+)`),
+		shorthand: regexp.MustCompile(`(?i)^tmdb:\s*(\d+)$`),
+	}
+}
+
+func (r *TMDBResolver) Resolve(ctx context.Context, input string) (Link, bool, error) {
+	input = strings.TrimSpace(input)
+
+	mediaType, rawID := tmdb.MediaMovie, ""
+	if matches := r.link.FindStringSubmatch(input); matches != nil {
+		mediaType, rawID = strings.ToLower(matches[1]), matches[2]
+	} else if matches := r.shorthand.FindStringSubmatch(input); matches != nil {
+		rawID = matches[1]
+	} else {
+		return Link{}, false, nil
+	}
+
+	id, err := strconv.Atoi(rawID)
+	if err != nil || id <= 0 {
+		return Link{}, true, ErrNotFound
+	}
+	return Link{MediaType: mediaType, TMDBID: id}, true, nil
+}
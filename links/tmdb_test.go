@@ -0,0 +1,38 @@
+package links
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTMDBResolver(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Link
+		ok    bool
+		err   error
+	}{
+		{input: "https://www.themoviedb.org/movie/603", want: movie(603), ok: true},
+		{input: "https://www.themoviedb.org/movie/603-the-matrix?language=pt-BR", want: movie(603), ok: true},
+		{input: "themoviedb.org/movie/496243-parasite/cast", want: movie(496243), ok: true},
+		{input: "https://tmdb.org/movie/949", want: movie(949), ok: true},
+		{input: "tmdb:348", want: movie(348), ok: true},
+		{input: "  TMDB: 348 ", want: movie(348), ok: true},
+		{input: "https://www.themoviedb.org/movie/0", ok: true, err: ErrNotFound},
+		{input: "https://www.themoviedb.org/tv/1396-breaking-bad", want: tv(1396), ok: true},
+		{input: "https://tmdb.org/TV/87108", want: tv(87108), ok: true},
+		{input: "tmdb 348", ok: false},
+		{input: "Heat", ok: false},
+	}
+
+	resolver := NewTMDBResolver()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			link, ok, err := resolver.Resolve(context.Background(), tt.input)
+			if link != tt.want || ok != tt.ok || !errors.Is(err, tt.err) {
+				t.Errorf("Resolve(%q) = %+v, %v, %v; want %+v, %v, %v", tt.input, link, ok, err, tt.want, tt.ok, tt.err)
+			}
+		})
+	}
+}
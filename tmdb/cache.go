@@ -0,0 +1,101 @@
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// DefaultCacheTTL é usado quando nenhum TTL é configurado
+const DefaultCacheTTL = 24 * time.Hour
+
+// CacheStore persiste respostas do TMDB entre reinicializações do bot.
+// Um payload nil sem erro indica que a chave não está no cache.
+type CacheStore interface {
+	GetCacheEntry(key string) (payload []byte, fetchedAt time.Time, err error)
+	SaveCacheEntry(key string, payload []byte) error
+}
+
+type inflightCall struct {
+	done  chan struct{}
+	movie *Movie
+	err   error
+}
+
+// EnableCache faz GetMovieByID e GetTVByID consultarem o store antes de ir ao TMDB.
+// Entradas expiradas continuam sendo usadas se o TMDB estiver fora do ar.
+func (c *Client) EnableCache(store CacheStore, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	c.cache = store
+	c.cacheTTL = ttl
+}
+
+// movieCacheKey inclui uma versão: ao adicionar campos em Movie a versão muda e as entradas antigas são ignoradas
+func movieCacheKey(mediaType string, id int, language string) string {
+	return fmt.Sprintf("%s:v4:%s:%d", mediaType, language, id)
+}
+
+func (c *Client) getCachedMovie(mediaType string, id int, language string) (*Movie, bool) {
+	payload, fetchedAt, err := c.cache.GetCacheEntry(movieCacheKey(mediaType, id, language))
+	if err != nil {
+		log.Printf("Erro ao ler cache do TMDB para %s %d: %v", mediaType, id, err)
+		return nil, false
+	}
+	if payload == nil {
+		return nil, false
+	}
+
+	var movie Movie
+	if err := json.Unmarshal(payload, &movie); err != nil {
+		return nil, false
+	}
+
+	return &movie, time.Since(fetchedAt) < c.cacheTTL
+}
+
+func (c *Client) storeCachedMovie(movie *Movie, language string) {
+	payload, err := json.Marshal(movie)
+	if err != nil {
+		return
+	}
+	if err := c.cache.SaveCacheEntry(movieCacheKey(movie.MediaType, movie.ID, language), payload); err != nil {
+		log.Printf("Erro ao salvar cache do TMDB para %s %d: %v", movie.MediaType, movie.ID, err)
+	}
+}
+
+// fetchMovieOnce garante uma única requisição ao TMDB por título e idioma, mesmo com chamadas concorrentes.
+// A requisição usa o contexto de quem chegou primeiro; os demais param de esperar quando o próprio contexto acaba.
+func (c *Client) fetchMovieOnce(ctx context.Context, mediaType string, id int, language string) (*Movie, error) {
+	key := movieCacheKey(mediaType, id, language)
+
+	c.inflightMu.Lock()
+	if call, ok := c.inflight[key]; ok {
+		c.inflightMu.Unlock()
+		select {
+		case <-call.done:
+			return call.movie, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call := &inflightCall{done: make(chan struct{})}
+	if c.inflight == nil {
+		c.inflight = make(map[string]*inflightCall)
+	}
+	c.inflight[key] = call
+	c.inflightMu.Unlock()
+
+	call.movie, call.err = c.fetchByID(ctx, mediaType, id, language)
+	close(call.done)
+
+	c.inflightMu.Lock()
+	delete(c.inflight, key)
+	c.inflightMu.Unlock()
+
+	return call.movie, call.err
+}
@@ -1,127 +1,260 @@
 package tmdb
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// Endereços padrão do TMDB; SetBaseURL e SetImageBaseURL apontam o cliente para outro servidor
 const (
 	BaseURL      = "https://api.themoviedb.org/3"
 	ImageBaseURL = "https://image.tmdb.org/t/p/w500"
 )
 
+// Valores padrão do cliente, ajustáveis com SetTimeout, SetRetries e SetRateLimit
+const (
+	// DefaultTimeout limita cada tentativa; o prazo total fica com o contexto de quem chama
+	DefaultTimeout        = 10 * time.Second
+	DefaultMaxRetries     = 3
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	// O TMDB aceita por volta de 50 requisições por segundo; o padrão fica abaixo disso
+	DefaultRateLimit = 40
+	DefaultRateBurst = 20
+)
+
+// maxRetryDelay limita a espera entre tentativas, tanto a do backoff exponencial quanto a pedida no Retry-After
+const maxRetryDelay = 10 * time.Second
+
 type Client struct {
-	apiKey     string
-	httpClient *http.Client
+	// Só uma das credenciais é usada: o token de leitura da API v4, quando definido, substitui a chave v3
+	apiKey       string
+	accessToken  string
+	baseURL      string
+	imageBaseURL string
+	httpClient   *http.Client
+
+	maxRetries     int
+	retryBaseDelay time.Duration
+	limiter        *rateLimiter
+
+	// language é usado quando quem chama não informa um idioma
+	language string
+	// genres guarda os nomes dos gêneros carregados por LoadGenres, por idioma
+	genresMu sync.RWMutex
+	genres   map[string]map[int]string
+
+	cache      CacheStore
+	cacheTTL   time.Duration
+	inflightMu sync.Mutex
+	inflight   map[string]*inflightCall
 }
 
 type SearchResponse struct {
 	Results []Movie `json:"results"`
 }
 
+// FindResponse é a resposta de /find; só os filmes e as séries encontrados interessam ao bot
+type FindResponse struct {
+	MovieResults []Movie  `json:"movie_results"`
+	TVResults    []TVShow `json:"tv_results"`
+}
+
 type Genre struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
 }
 
+// Tipos de título do TMDB, que também são os segmentos das URLs da API (/movie/{id} e /tv/{id})
+const (
+	MediaMovie = "movie"
+	MediaTV    = "tv"
+)
+
+// Movie é um título do TMDB. Séries também são devolvidas como Movie, com MediaType igual a MediaTV,
+// o nome da série em Title, a data de estreia em ReleaseDate e as contagens de temporadas e episódios.
 type Movie struct {
-	ID          int     `json:"id"`
-	Title       string  `json:"title"`
-	Overview    string  `json:"overview"`
-	VoteAverage float64 `json:"vote_average"`
-	ReleaseDate string  `json:"release_date"`
-	PosterPath  string  `json:"poster_path"`
-	GenreIDs    []int   `json:"genre_ids"`
-	Genres      []Genre `json:"genres"`
-}
-
-var genreMap = map[int]string{
-	28:    "Action",
-	12:    "Adventure",
-	16:    "Animation",
-	35:    "Comedy",
-	80:    "Crime",
-	99:    "Documentary",
-	18:    "Drama",
-	10751: "Family",
-	14:    "Fantasy",
-	36:    "History",
-	27:    "Horror",
-	10402: "Music",
-	9648:  "Mystery",
-	10749: "Romance",
-	878:   "Science Fiction",
-	10770: "TV Movie",
-	53:    "Thriller",
-	10752: "War",
-	37:    "Western",
+	ID               int     `json:"id"`
+	MediaType        string  `json:"media_type"`
+	Title            string  `json:"title"`
+	OriginalTitle    string  `json:"original_title"`
+	OriginalLanguage string  `json:"original_language"`
+	Overview         string  `json:"overview"`
+	VoteAverage      float64 `json:"vote_average"`
+	ReleaseDate      string  `json:"release_date"`
+	PosterPath       string  `json:"poster_path"`
+	BackdropPath     string  `json:"backdrop_path"`
+	Runtime          int     `json:"runtime"`
+	IMDbID           string  `json:"imdb_id"`
+	GenreIDs         []int   `json:"genre_ids"`
+	Genres           []Genre `json:"genres"`
+	NumberOfSeasons  int     `json:"number_of_seasons"`
+	NumberOfEpisodes int     `json:"number_of_episodes"`
 }
 
 func NewClient(apiKey string) *Client {
 	return &Client{
-		apiKey:     apiKey,
-		httpClient: &http.Client{},
+		apiKey:         apiKey,
+		baseURL:        BaseURL,
+		imageBaseURL:   ImageBaseURL,
+		language:       DefaultLanguage,
+		httpClient:     &http.Client{Timeout: DefaultTimeout},
+		maxRetries:     DefaultMaxRetries,
+		retryBaseDelay: DefaultRetryBaseDelay,
+		limiter:        newRateLimiter(DefaultRateLimit, DefaultRateBurst),
 	}
 }
 
-func (c *Client) SearchMovie(movieName string) (*Movie, error) {
-	params := url.Values{}
-	params.Set("api_key", c.apiKey)
-	params.Set("query", movieName)
-	params.Set("language", "en-US")
+// SetTimeout muda o tempo máximo de cada tentativa
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.httpClient.Timeout = timeout
+}
 
-	url := fmt.Sprintf("%s/search/movie?%s", BaseURL, params.Encode())
+// SetRetries define quantas vezes uma resposta 429 ou 5xx é repetida e a espera antes da primeira
+// repetição, que dobra a cada tentativa; zero desliga as repetições
+func (c *Client) SetRetries(maxRetries int, baseDelay time.Duration) {
+	c.maxRetries = maxRetries
+	c.retryBaseDelay = baseDelay
+}
 
-	resp, err := c.httpClient.Get(url)
-	if err != nil {
-		return nil, err
+// SetRateLimit limita as requisições por segundo de todas as goroutines que usam o cliente;
+// zero ou negativo desliga o limite
+func (c *Client) SetRateLimit(perSecond float64, burst int) {
+	if perSecond <= 0 {
+		c.limiter = nil
+		return
 	}
-	defer resp.Body.Close()
+	c.limiter = newRateLimiter(perSecond, burst)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API returned status %d", resp.StatusCode)
+// SetBaseURL aponta o cliente para outro servidor compatível com a API do TMDB, como um servidor de testes
+func (c *Client) SetBaseURL(baseURL string) {
+	c.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// SetImageBaseURL muda o endereço dos pôsteres, por exemplo para um proxy com cache
+func (c *Client) SetImageBaseURL(imageBaseURL string) {
+	c.imageBaseURL = strings.TrimRight(imageBaseURL, "/")
+}
+
+// SetAccessToken autentica com o token de leitura da API v4, enviado no cabeçalho Authorization
+// em vez da chave v3 na URL, que acabaria nos logs e nas mensagens de erro
+func (c *Client) SetAccessToken(accessToken string) {
+	c.accessToken = accessToken
+}
+
+// SetLanguage troca o idioma padrão das respostas, usado quando quem chama passa um idioma vazio
+func (c *Client) SetLanguage(language string) {
+	c.language = language
+}
+
+// languageOrDefault devolve o idioma pedido ou, se vazio, o idioma padrão do cliente
+func (c *Client) languageOrDefault(language string) string {
+	if language == "" {
+		return c.language
 	}
+	return language
+}
+
+// SearchMovies retorna todos os resultados da primeira página de busca do TMDB, na ordem de relevância,
+// com títulos e sinopses no idioma informado
+func (c *Client) SearchMovies(ctx context.Context, movieName, language string) ([]Movie, error) {
+	params := url.Values{}
+	params.Set("query", movieName)
+	params.Set("language", c.languageOrDefault(language))
 
 	var searchResp SearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
+	if err := c.get(ctx, "/search/movie", params, &searchResp); err != nil {
 		return nil, err
 	}
 
-	if len(searchResp.Results) == 0 {
-		return nil, nil
+	for i := range searchResp.Results {
+		searchResp.Results[i].MediaType = MediaMovie
 	}
+	return searchResp.Results, nil
+}
 
-	return &searchResp.Results[0], nil
+// GetMovieByID busca um filme específico pelo ID do TMDB, usando o cache quando habilitado.
+// Cada idioma tem sua própria entrada no cache. Um ID inexistente devolve ErrNotFound.
+func (c *Client) GetMovieByID(ctx context.Context, movieID int, language string) (*Movie, error) {
+	return c.getByID(ctx, MediaMovie, movieID, language)
 }
 
-// GetMovieByID busca um filme específico pelo ID do TMDB
-func (c *Client) GetMovieByID(movieID int) (*Movie, error) {
-	params := url.Values{}
-	params.Set("api_key", c.apiKey)
-	params.Set("language", "en-US")
+// getByID busca um filme ou série pelo ID, passando pelo cache quando ele está habilitado
+func (c *Client) getByID(ctx context.Context, mediaType string, id int, language string) (*Movie, error) {
+	language = c.languageOrDefault(language)
+	if c.cache == nil {
+		return c.fetchByID(ctx, mediaType, id, language)
+	}
 
-	url := fmt.Sprintf("%s/movie/%d?%s", BaseURL, movieID, params.Encode())
+	cached, fresh := c.getCachedMovie(mediaType, id, language)
+	if fresh {
+		return cached, nil
+	}
 
-	resp, err := c.httpClient.Get(url)
+	movie, err := c.fetchMovieOnce(ctx, mediaType, id, language)
 	if err != nil {
+		// Um título removido do TMDB não deve continuar vindo do cache
+		if cached != nil && !errors.Is(err, ErrNotFound) {
+			log.Printf("TMDB indisponível, usando cache expirado para %s %d: %v", mediaType, id, err)
+			return cached, nil
+		}
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, nil
+	c.storeCachedMovie(movie, language)
+	return movie, nil
+}
+
+func (c *Client) fetchByID(ctx context.Context, mediaType string, id int, language string) (*Movie, error) {
+	if mediaType == MediaTV {
+		return c.fetchTVByID(ctx, id, language)
 	}
+	return c.fetchMovieByID(ctx, id, language)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API returned status %d", resp.StatusCode)
+// FindByIMDbID busca em /find o filme ou a série com o ID do IMDb informado (ex.: tt0133093). Filmes têm
+// preferência; devolve ErrNotFound quando o TMDB não conhece o ID ou ele é de uma pessoa ou episódio.
+func (c *Client) FindByIMDbID(ctx context.Context, imdbID, language string) (*Movie, error) {
+	params := url.Values{}
+	params.Set("external_source", "imdb_id")
+	params.Set("language", c.languageOrDefault(language))
+
+	var findResp FindResponse
+	if err := c.get(ctx, "/find/"+url.PathEscape(imdbID), params, &findResp); err != nil {
+		return nil, err
+	}
+
+	if len(findResp.MovieResults) > 0 {
+		movie := findResp.MovieResults[0]
+		movie.MediaType = MediaMovie
+		return &movie, nil
 	}
+	if len(findResp.TVResults) > 0 {
+		show := findResp.TVResults[0].movie()
+		return &show, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (c *Client) fetchMovieByID(ctx context.Context, movieID int, language string) (*Movie, error) {
+	params := url.Values{}
+	params.Set("language", language)
 
 	var movie Movie
-	if err := json.NewDecoder(resp.Body).Decode(&movie); err != nil {
+	if err := c.get(ctx, fmt.Sprintf("/movie/%d", movieID), params, &movie); err != nil {
 		return nil, err
 	}
+	movie.MediaType = MediaMovie
 
 	if len(movie.Genres) > 0 && len(movie.GenreIDs) == 0 {
 		movie.GenreIDs = make([]int, len(movie.Genres))
@@ -133,31 +266,111 @@ func (c *Client) GetMovieByID(movieID int) (*Movie, error) {
 	return &movie, nil
 }
 
-func (c *Client) GetPosterURL(posterPath string) string {
-	if posterPath == "" {
-		return ""
+// get faz a requisição ao TMDB e decodifica a resposta em out. Respostas 429 e 5xx são repetidas com
+// backoff exponencial e jitter, respeitando o Retry-After, enquanto o contexto permitir.
+func (c *Client) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	endpoint := c.baseURL + path + "?" + params.Encode()
+
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		retryAfter, err := c.getOnce(ctx, endpoint, out)
+		var statusErr *StatusError
+		if err == nil || attempt >= c.maxRetries || !errors.As(err, &statusErr) || !statusErr.retryable() {
+			return err
+		}
+
+		delay, ok := c.retryDelay(ctx, attempt, retryAfter)
+		if !ok {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
 	}
-	return ImageBaseURL + posterPath
 }
 
-func FormatGenres(genreIDs []int) string {
-	var genres []string
-	for _, id := range genreIDs {
-		if name, ok := genreMap[id]; ok {
-			genres = append(genres, name)
-			if len(genres) >= 5 {
-				break
-			}
+// getOnce faz uma única tentativa; em respostas 429 também devolve a espera pedida pelo TMDB
+func (c *Client) getOnce(ctx context.Context, endpoint string, out interface{}) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	if c.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	} else {
+		query := req.URL.Query()
+		query.Set("api_key", c.apiKey)
+		req.URL.RawQuery = query.Encode()
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// O erro do net/http traz a URL completa; a versão sem a chave é a que vai para os logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = endpoint
 		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return parseRetryAfter(resp.Header.Get("Retry-After")), &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	return 0, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// retryDelay dobra a espera a cada tentativa, com jitter para que várias goroutines não repitam juntas;
+// um Retry-After maior prevalece, até maxRetryDelay. Devolve false quando o contexto não comporta a espera:
+// sem tempo para a próxima tentativa, o erro original é mais útil que um timeout.
+func (c *Client) retryDelay(ctx context.Context, attempt int, retryAfter time.Duration) (time.Duration, bool) {
+	delay := c.retryBaseDelay << attempt
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	delay = delay/2 + rand.N(delay/2+1)
+
+	if retryAfter > delay {
+		delay = min(retryAfter, maxRetryDelay)
 	}
 
-	if len(genres) == 0 {
-		return "Not available"
+	if ctx.Err() != nil {
+		return 0, false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		return 0, false
 	}
+	return delay, true
+}
 
-	result := genres[0]
-	for i := 1; i < len(genres); i++ {
-		result += ", " + genres[i]
+// parseRetryAfter aceita os dois formatos do cabeçalho: segundos ou uma data HTTP
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func (c *Client) GetPosterURL(posterPath string) string {
+	if posterPath == "" {
+		return ""
 	}
-	return result
+	return c.imageBaseURL + posterPath
 }
\ No newline at end of file
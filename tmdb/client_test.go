@@ -0,0 +1,233 @@
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient aponta um cliente para um servidor que responde com os status da lista, um por requisição;
+// depois do último, responde sempre com o filme
+func newTestClient(t *testing.T, statuses ...int) (*Client, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		json.NewEncoder(w).Encode(Movie{ID: 949, Title: "Heat"})
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test-key")
+	client.SetBaseURL(server.URL)
+	client.SetRetries(2, time.Millisecond)
+	client.SetRateLimit(0, 0)
+	return client, &requests
+}
+
+func TestGetMovieByIDErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		want     error
+		requests int32
+	}{
+		{name: "server errors are retried", statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable}, requests: 3},
+		{name: "rate limits are retried", statuses: []int{http.StatusTooManyRequests}, requests: 2},
+		{name: "retries give up", statuses: []int{500, 500, 500}, want: &StatusError{StatusCode: 500}, requests: 3},
+		{name: "rate limited after every retry", statuses: []int{429, 429, 429}, want: ErrRateLimited, requests: 3},
+		{name: "not found", statuses: []int{http.StatusNotFound}, want: ErrNotFound, requests: 1},
+		{name: "unauthorized", statuses: []int{http.StatusUnauthorized}, want: ErrUnauthorized, requests: 1},
+		{name: "other client errors are not retried", statuses: []int{http.StatusBadRequest}, want: &StatusError{StatusCode: 400}, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := newTestClient(t, tt.statuses...)
+
+			movie, err := client.GetMovieByID(context.Background(), 949, "")
+			switch want := tt.want.(type) {
+			case nil:
+				if err != nil || movie == nil || movie.Title != "Heat" {
+					t.Fatalf("expected Heat, got %+v, %v", movie, err)
+				}
+			case *StatusError:
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != want.StatusCode {
+					t.Fatalf("expected status %d, got %v", want.StatusCode, err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("expected %v, got %v", want, err)
+				}
+			}
+
+			if got := requests.Load(); got != tt.requests {
+				t.Errorf("expected %d requests, got %d", tt.requests, got)
+			}
+		})
+	}
+}
+
+func TestRetryAfterIsRespected(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(SearchResponse{Results: []Movie{{ID: 949, Title: "Heat"}}})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key")
+	client.SetBaseURL(server.URL)
+	client.SetRetries(1, time.Millisecond)
+
+	start := time.Now()
+	movies, err := client.SearchMovies(context.Background(), "heat", "")
+	if err != nil || len(movies) != 1 {
+		t.Fatalf("expected one result, got %+v, %v", movies, err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected to wait for Retry-After, retried after %v", elapsed)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	client := NewClient("test-key")
+	client.SetRetries(3, time.Millisecond)
+
+	// Um Retry-After exagerado não segura a goroutine por mais que maxRetryDelay
+	if delay, ok := client.retryDelay(context.Background(), 0, time.Hour); !ok || delay != maxRetryDelay {
+		t.Errorf("expected the Retry-After to be capped at %v, got %v, %v", maxRetryDelay, delay, ok)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, ok := client.retryDelay(ctx, 0, 5*time.Second); ok {
+		t.Error("expected no retry when the wait outlives the context deadline")
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, ok := client.retryDelay(cancelled, 0, 0); ok {
+		t.Error("expected no retry on a cancelled context")
+	}
+}
+
+func TestRetriesStopAtTheContextDeadline(t *testing.T) {
+	client, requests := newTestClient(t, 500, 500, 500)
+	client.SetRetries(2, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetMovieByID(ctx, 949, "")
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected the server error, got %v", err)
+	}
+	if requests.Load() != 1 || time.Since(start) > time.Second {
+		t.Errorf("expected a single request without waiting, got %d in %v", requests.Load(), time.Since(start))
+	}
+}
+
+func TestRateLimiterIsShared(t *testing.T) {
+	limiter := newRateLimiter(20, 2)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// As duas primeiras usam o balde cheio; as outras quatro esperam 50ms cada
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("expected the limiter to space the requests, all done after %v", elapsed)
+	}
+
+	// Com o balde vazio, um contexto cancelado interrompe a espera
+	limiter = newRateLimiter(0.001, 1)
+	limiter.Wait(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancelled context to stop the wait, got %v", err)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	var authorization, apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		apiKey = r.URL.Query().Get("api_key")
+		json.NewEncoder(w).Encode(SearchResponse{})
+	}))
+	defer server.Close()
+
+	client := NewClient("v3-key")
+	client.SetBaseURL(server.URL + "/")
+	if _, err := client.SearchMovies(context.Background(), "heat", ""); err != nil {
+		t.Fatal(err)
+	}
+	if apiKey != "v3-key" || authorization != "" {
+		t.Errorf("expected the v3 key in the query, got api_key=%q Authorization=%q", apiKey, authorization)
+	}
+
+	client.SetAccessToken("v4-token")
+	if _, err := client.SearchMovies(context.Background(), "heat", ""); err != nil {
+		t.Fatal(err)
+	}
+	if apiKey != "" || authorization != "Bearer v4-token" {
+		t.Errorf("expected the v4 token in the header, got api_key=%q Authorization=%q", apiKey, authorization)
+	}
+}
+
+func TestErrorsDoNotLeakTheAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client := NewClient("secret-key")
+	client.SetBaseURL(server.URL)
+	_, err := client.GetMovieByID(context.Background(), 949, "")
+	if err == nil || strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("expected a connection error without the key, got %v", err)
+	}
+}
+
+func TestGetPosterURL(t *testing.T) {
+	client := NewClient("test-key")
+	if got := client.GetPosterURL("/poster.jpg"); got != ImageBaseURL+"/poster.jpg" {
+		t.Errorf("unexpected default poster URL %q", got)
+	}
+
+	client.SetImageBaseURL("http://localhost:8080/images/")
+	if got := client.GetPosterURL("/poster.jpg"); got != "http://localhost:8080/images/poster.jpg" {
+		t.Errorf("unexpected poster URL %q", got)
+	}
+	if got := client.GetPosterURL(""); got != "" {
+		t.Errorf("movies without posters should have no URL, got %q", got)
+	}
+}
@@ -0,0 +1,40 @@
+package tmdb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Erros devolvidos pelo cliente; compare com errors.Is para mostrar a mensagem certa ao usuário
+var (
+	ErrNotFound     = errors.New("tmdb: not found")
+	ErrRateLimited  = errors.New("tmdb: rate limited")
+	ErrUnauthorized = errors.New("tmdb: unauthorized")
+)
+
+// StatusError é uma resposta inesperada do TMDB. Unwrap a traduz para os erros acima quando o status tem um.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("TMDB API returned status %d", e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// retryable indica as respostas que valem uma nova tentativa: limite de requisições e erros do servidor
+func (e *StatusError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
@@ -0,0 +1,135 @@
+package tmdb
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage é o idioma das respostas do TMDB quando nem o servidor nem o bot configuram outro
+const DefaultLanguage = "en-US"
+
+// genreMap é a lista embutida, em inglês, usada no registro dos comandos e enquanto LoadGenres não carregou
+// a lista do TMDB
+var genreMap = map[int]string{
+	28:    "Action",
+	12:    "Adventure",
+	16:    "Animation",
+	35:    "Comedy",
+	80:    "Crime",
+	99:    "Documentary",
+	18:    "Drama",
+	10751: "Family",
+	14:    "Fantasy",
+	36:    "History",
+	27:    "Horror",
+	10402: "Music",
+	9648:  "Mystery",
+	10749: "Romance",
+	878:   "Science Fiction",
+	10770: "TV Movie",
+	53:    "Thriller",
+	10752: "War",
+	37:    "Western",
+}
+
+// tvGenreMap completa a lista embutida com os gêneros exclusivos das séries
+var tvGenreMap = map[int]string{
+	10759: "Action & Adventure",
+	10762: "Kids",
+	10763: "News",
+	10764: "Reality",
+	10765: "Sci-Fi & Fantasy",
+	10766: "Soap",
+	10767: "Talk",
+	10768: "War & Politics",
+}
+
+type genreListResponse struct {
+	Genres []Genre `json:"genres"`
+}
+
+// MovieGenres lista os gêneros conhecidos em ordem alfabética
+func MovieGenres() []Genre {
+	genres := make([]Genre, 0, len(genreMap))
+	for id, name := range genreMap {
+		genres = append(genres, Genre{ID: id, Name: name})
+	}
+	sort.Slice(genres, func(a, b int) bool {
+		return genres[a].Name < genres[b].Name
+	})
+	return genres
+}
+
+func GenreName(genreID int) string {
+	return genreMap[genreID]
+}
+
+// LoadGenres busca em /genre/movie/list e /genre/tv/list os nomes dos gêneros no idioma informado,
+// substituindo a lista carregada antes para esse idioma. Chame de novo de tempos em tempos para acompanhar
+// mudanças do TMDB. Os IDs são compartilhados: um gênero presente nas duas listas tem o mesmo nome.
+func (c *Client) LoadGenres(ctx context.Context, language string) error {
+	language = c.languageOrDefault(language)
+
+	params := url.Values{}
+	params.Set("language", language)
+
+	names := make(map[int]string)
+	for _, mediaType := range []string{MediaMovie, MediaTV} {
+		var resp genreListResponse
+		if err := c.get(ctx, "/genre/"+mediaType+"/list", params, &resp); err != nil {
+			return err
+		}
+		for _, genre := range resp.Genres {
+			names[genre.ID] = genre.Name
+		}
+	}
+
+	c.genresMu.Lock()
+	if c.genres == nil {
+		c.genres = make(map[string]map[int]string)
+	}
+	c.genres[language] = names
+	c.genresMu.Unlock()
+	return nil
+}
+
+// genreName procura o gênero na lista do idioma, depois na do idioma padrão do cliente e por fim nas listas embutidas
+func (c *Client) genreName(language string, genreID int) (string, bool) {
+	c.genresMu.RLock()
+	defer c.genresMu.RUnlock()
+
+	for _, lang := range []string{language, c.language} {
+		if name, ok := c.genres[lang][genreID]; ok {
+			return name, true
+		}
+	}
+	if name, ok := genreMap[genreID]; ok {
+		return name, true
+	}
+	name, ok := tvGenreMap[genreID]
+	return name, ok
+}
+
+// FormatGenres junta os nomes dos gêneros no idioma informado. IDs que nenhuma lista conhece são registrados
+// no log, já que indicam que a lista do TMDB ainda não foi carregada ou ganhou gêneros novos.
+func (c *Client) FormatGenres(language string, genreIDs []int) string {
+	language = c.languageOrDefault(language)
+
+	var genres []string
+	for _, id := range genreIDs {
+		name, ok := c.genreName(language, id)
+		if !ok {
+			log.Printf("Gênero %d desconhecido do TMDB para o idioma %s", id, language)
+			continue
+		}
+		genres = append(genres, name)
+	}
+
+	if len(genres) == 0 {
+		return "Not available"
+	}
+	return strings.Join(genres, ", ")
+}
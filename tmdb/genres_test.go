@@ -0,0 +1,60 @@
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFormatGenres(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var genres []Genre
+		switch r.URL.Path + "?" + r.URL.Query().Get("language") {
+		case "/genre/movie/list?en-US":
+			genres = []Genre{{ID: 28, Name: "Action"}, {ID: 18, Name: "Drama"}, {ID: 99999, Name: "Brand New"}}
+		case "/genre/tv/list?en-US":
+			genres = []Genre{{ID: 10765, Name: "Sci-Fi & Fantasy"}, {ID: 18, Name: "Drama"}}
+		case "/genre/movie/list?pt-BR":
+			genres = []Genre{{ID: 28, Name: "Ação"}, {ID: 18, Name: "Drama"}}
+		case "/genre/tv/list?pt-BR":
+			genres = []Genre{{ID: 10765, Name: "Ficção científica e fantasia"}}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(genreListResponse{Genres: genres})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key")
+	client.SetBaseURL(server.URL)
+
+	// Antes de carregar do TMDB vale a lista embutida; IDs desconhecidos ficam de fora e não há limite de gêneros
+	ids := []int{28, 12, 16, 35, 80, 18, 10765, 99999}
+	if got := client.FormatGenres("", ids); got != "Action, Adventure, Animation, Comedy, Crime, Drama, Sci-Fi & Fantasy" {
+		t.Errorf("unexpected built-in genres %q", got)
+	}
+	if got := client.FormatGenres("", nil); got != "Not available" {
+		t.Errorf("expected no genres, got %q", got)
+	}
+
+	for _, language := range []string{"", "pt-BR"} {
+		if err := client.LoadGenres(context.Background(), language); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := client.FormatGenres("", []int{18, 99999}); got != "Drama, Brand New" {
+		t.Errorf("expected the genres loaded for the default language, got %q", got)
+	}
+	// Gêneros que faltam na lista do idioma caem no idioma padrão
+	if got := client.FormatGenres("pt-BR", []int{28, 99999, 12}); got != "Ação, Brand New, Adventure" {
+		t.Errorf("unexpected pt-BR genres %q", got)
+	}
+	// Os gêneros das séries entram na mesma lista
+	if got := client.FormatGenres("pt-BR", []int{10765, 18}); got != "Ficção científica e fantasia, Drama" {
+		t.Errorf("unexpected pt-BR TV genres %q", got)
+	}
+}
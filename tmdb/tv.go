@@ -0,0 +1,99 @@
+package tmdb
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// TVShow é uma série como o TMDB a descreve em /search/tv, /tv/{id} e /find
+type TVShow struct {
+	ID               int     `json:"id"`
+	Name             string  `json:"name"`
+	OriginalName     string  `json:"original_name"`
+	OriginalLanguage string  `json:"original_language"`
+	Overview         string  `json:"overview"`
+	VoteAverage      float64 `json:"vote_average"`
+	FirstAirDate     string  `json:"first_air_date"`
+	PosterPath       string  `json:"poster_path"`
+	BackdropPath     string  `json:"backdrop_path"`
+	NumberOfSeasons  int     `json:"number_of_seasons"`
+	NumberOfEpisodes int     `json:"number_of_episodes"`
+	GenreIDs         []int   `json:"genre_ids"`
+	Genres           []Genre `json:"genres"`
+	// ExternalIDs só vem em /tv/{id}, pedido com append_to_response=external_ids
+	ExternalIDs struct {
+		IMDbID string `json:"imdb_id"`
+	} `json:"external_ids"`
+}
+
+type TVSearchResponse struct {
+	Results []TVShow `json:"results"`
+}
+
+// movie converte a série para o formato usado pelo resto do bot
+func (s TVShow) movie() Movie {
+	movie := Movie{
+		ID:               s.ID,
+		MediaType:        MediaTV,
+		Title:            s.Name,
+		OriginalTitle:    s.OriginalName,
+		OriginalLanguage: s.OriginalLanguage,
+		Overview:         s.Overview,
+		VoteAverage:      s.VoteAverage,
+		ReleaseDate:      s.FirstAirDate,
+		PosterPath:       s.PosterPath,
+		BackdropPath:     s.BackdropPath,
+		IMDbID:           s.ExternalIDs.IMDbID,
+		GenreIDs:         s.GenreIDs,
+		Genres:           s.Genres,
+		NumberOfSeasons:  s.NumberOfSeasons,
+		NumberOfEpisodes: s.NumberOfEpisodes,
+	}
+
+	if len(movie.Genres) > 0 && len(movie.GenreIDs) == 0 {
+		movie.GenreIDs = make([]int, len(movie.Genres))
+		for i, genre := range movie.Genres {
+			movie.GenreIDs[i] = genre.ID
+		}
+	}
+	return movie
+}
+
+// SearchTV retorna as séries da primeira página de busca do TMDB, na ordem de relevância
+func (c *Client) SearchTV(ctx context.Context, name, language string) ([]Movie, error) {
+	params := url.Values{}
+	params.Set("query", name)
+	params.Set("language", c.languageOrDefault(language))
+
+	var searchResp TVSearchResponse
+	if err := c.get(ctx, "/search/tv", params, &searchResp); err != nil {
+		return nil, err
+	}
+
+	shows := make([]Movie, len(searchResp.Results))
+	for i, show := range searchResp.Results {
+		shows[i] = show.movie()
+	}
+	return shows, nil
+}
+
+// GetTVByID busca uma série pelo ID do TMDB, com as contagens de temporadas e episódios, usando o cache
+// quando habilitado. Um ID inexistente devolve ErrNotFound.
+func (c *Client) GetTVByID(ctx context.Context, tvID int, language string) (*Movie, error) {
+	return c.getByID(ctx, MediaTV, tvID, language)
+}
+
+func (c *Client) fetchTVByID(ctx context.Context, tvID int, language string) (*Movie, error) {
+	params := url.Values{}
+	params.Set("language", language)
+	params.Set("append_to_response", "external_ids")
+
+	var show TVShow
+	if err := c.get(ctx, fmt.Sprintf("/tv/%d", tvID), params, &show); err != nil {
+		return nil, err
+	}
+
+	movie := show.movie()
+	return &movie, nil
+}
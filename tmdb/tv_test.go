@@ -0,0 +1,100 @@
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryCache guarda as entradas do cache em memória
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func (m *memoryCache) GetCacheEntry(key string) ([]byte, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	payload, ok := m.entries[key]
+	if !ok {
+		return nil, time.Time{}, nil
+	}
+	return payload, time.Now(), nil
+}
+
+func (m *memoryCache) SaveCacheEntry(key string, payload []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = payload
+	return nil
+}
+
+func TestTVShows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/movie/949":
+			json.NewEncoder(w).Encode(Movie{ID: 949, Title: "Heat", Runtime: 170})
+		case "/tv/949":
+			if r.URL.Query().Get("append_to_response") != "external_ids" {
+				t.Errorf("expected the external IDs to be requested, got %q", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"id":949,"name":"Heat Wave","original_name":"Heat Wave","first_air_date":"2003-09-22",
+				"number_of_seasons":2,"number_of_episodes":16,"genres":[{"id":18,"name":"Drama"}],
+				"external_ids":{"imdb_id":"tt0364845"}}`))
+		case "/search/tv":
+			w.Write([]byte(`{"results":[{"id":87108,"name":"Chernobyl","first_air_date":"2019-05-06","genre_ids":[18]}]}`))
+		case "/find/tt7366338":
+			w.Write([]byte(`{"movie_results":[],"tv_results":[{"id":87108,"name":"Chernobyl","media_type":"tv"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key")
+	client.SetBaseURL(server.URL)
+	client.EnableCache(&memoryCache{entries: map[string][]byte{}}, time.Hour)
+	ctx := context.Background()
+
+	show, err := client.GetTVByID(ctx, 949, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if show.MediaType != MediaTV || show.Title != "Heat Wave" || show.ReleaseDate != "2003-09-22" ||
+		show.NumberOfSeasons != 2 || show.NumberOfEpisodes != 16 || show.IMDbID != "tt0364845" ||
+		len(show.GenreIDs) != 1 || show.GenreIDs[0] != 18 {
+		t.Errorf("unexpected show %+v", show)
+	}
+
+	// Filmes e séries com o mesmo ID não dividem a entrada do cache
+	movie, err := client.GetMovieByID(ctx, 949, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if movie.MediaType != MediaMovie || movie.Title != "Heat" {
+		t.Errorf("expected the movie, got %+v", movie)
+	}
+	if cached, err := client.GetTVByID(ctx, 949, ""); err != nil || cached.Title != "Heat Wave" || cached.MediaType != MediaTV {
+		t.Errorf("expected the cached show, got %+v, %v", cached, err)
+	}
+
+	shows, err := client.SearchTV(ctx, "chernobyl", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shows) != 1 || shows[0].ID != 87108 || shows[0].Title != "Chernobyl" || shows[0].MediaType != MediaTV {
+		t.Errorf("unexpected search results %+v", shows)
+	}
+
+	found, err := client.FindByIMDbID(ctx, "tt7366338", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != 87108 || found.MediaType != MediaTV {
+		t.Errorf("expected the show from tv_results, got %+v", found)
+	}
+}